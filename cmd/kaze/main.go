@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
+	_ "time/tzdata" // timezone database for the scratch image
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -15,11 +19,17 @@ import (
 
 	"github.com/glower/kaze/pkg/config"
 	"github.com/glower/kaze/pkg/database"
+	"github.com/glower/kaze/pkg/dataloader"
+	"github.com/glower/kaze/pkg/geojson"
 	"github.com/glower/kaze/pkg/handler"
 	"github.com/glower/kaze/pkg/repository"
 	"github.com/glower/kaze/pkg/service"
+	"github.com/glower/kaze/pkg/weather"
 )
 
+// backfillBatchSize is the number of power plants updated with a single elevation api call.
+const backfillBatchSize = 100
+
 func initLog() {
 	logHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
@@ -73,15 +83,157 @@ func main() {
 	slog.Info("Migration executed successfully")
 
 	powerPlantRepo := repository.NewPowerPlantRepository(db)
-	openMeteoRepo := repository.NewOpenMeteoRepository(conf.OpenMeteoAPIKey)
-	powerPlantService := service.NewPowerPlantService(powerPlantRepo, openMeteoRepo)
+	openMeteoClient := repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{
+		APIKey:         conf.OpenMeteoAPIKey,
+		BaseURL:        conf.OpenMeteoBaseURL,
+		ArchiveBaseURL: conf.OpenMeteoArchiveBaseURL,
+		Timeout:        conf.OpenMeteoTimeout,
+		MaxRetries:     conf.OpenMeteoMaxRetries,
+	})
+	// the breaker is behind the cache, so the cache can serve the last known responses while the circuit is open
+	openMeteoClient = repository.NewBreakerOpenMeteoRepository(openMeteoClient, repository.BreakerOptions{
+		FailureThreshold: conf.OpenMeteoBreakerThreshold,
+		ProbeInterval:    conf.OpenMeteoBreakerProbeInterval,
+	})
+	cacheOpts := repository.CacheOptions{Size: conf.OpenMeteoCacheSize, LoadTimeout: conf.OpenMeteoCacheLoadTimeout}
+	if conf.OpenMeteoCachePostgres {
+		cacheOpts.Store = repository.NewPostgresCacheStore(db)
+	}
+	openMeteoRepo, err := repository.NewCachedOpenMeteoRepository(openMeteoClient, cacheOpts)
+	if err != nil {
+		slog.Error("can't create the Open-Meteo cache", "error", err)
+		return
+	}
+	weatherProviders, err := newWeatherProviders(conf, openMeteoRepo)
+	if err != nil {
+		slog.Error("can't create the weather providers", "error", err)
+		return
+	}
+	powerPlantService := service.NewPowerPlantService(powerPlantRepo, openMeteoRepo, weatherProviders)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backfill-elevation":
+			backfillElevation(powerPlantService)
+		case "import":
+			importPowerPlants(powerPlantService, os.Args[2:])
+		default:
+			slog.Error("unknown command, available commands: backfill-elevation, import", "command", os.Args[1])
+		}
+		return
+	}
 
-	server := handler.NewServer(powerPlantService)
+	server := handler.NewServer(powerPlantService, openMeteoRepo, weatherProviders, dataloader.Options{
+		MaxBatchSize: conf.EnrichmentBatchSize,
+		Concurrency:  conf.EnrichmentConcurrency,
+		Timeout:      conf.EnrichmentTimeout,
+	})
 	mux := server.SetupRoutes()
 
+	// Start the admin listener with the metrics separately, so they are not exposed on the public port
+	if conf.AdminAddr != "" {
+		go func() {
+			slog.Info("Starting admin server", "addr", conf.AdminAddr)
+			if err := http.ListenAndServe(conf.AdminAddr, handler.SetupAdminRoutes()); err != nil {
+				log.Fatalf("could not start admin server: %v", err)
+			}
+		}()
+	}
+
 	// Start the server
 	slog.Info("Starting GraphQL server on http://localhost:8080/")
 	if err := http.ListenAndServe(":8080", mux); err != nil {
 		log.Fatalf("could not start server: %v", err)
 	}
 }
+
+// newWeatherProviders creates the weather providers of the configuration, the failover and ensemble
+// providers combine the other providers.
+func newWeatherProviders(conf *config.Config, openMeteoRepo repository.OpenMeteoRepository) (*weather.Providers, error) {
+	timeZones := weather.NewTimeZones(openMeteoRepo)
+	providers := []weather.WeatherProvider{
+		weather.NewOpenMeteoProvider(openMeteoRepo),
+		weather.NewMETNorwayProvider(weather.METNorwayConfig{UserAgent: conf.METNorwayUserAgent, TimeZones: timeZones}),
+		weather.NewDWDProvider(weather.DWDConfig{TimeZones: timeZones}),
+	}
+	for _, model := range conf.OpenMeteoModels {
+		providers = append(providers, weather.NewOpenMeteoModelProvider(openMeteoRepo, model))
+	}
+
+	var combined []weather.WeatherProvider
+	if len(conf.WeatherFailover) > 0 {
+		chain, err := weather.FindProviders(providers, conf.WeatherFailover...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weather failover: %w", err)
+		}
+		combined = append(combined, weather.NewFailoverProvider(chain...))
+	}
+	if conf.WeatherEnsemble != "" {
+		members, err := weather.ParseEnsemble(conf.WeatherEnsemble, providers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weather ensemble: %w", err)
+		}
+		combined = append(combined, weather.NewEnsembleProvider(members...))
+	}
+
+	return weather.NewProviders(conf.WeatherProvider, append(providers, combined...)...)
+}
+
+// backfillElevation stores the elevation of all power plants without a stored elevation.
+func backfillElevation(powerPlantService service.PowerPlantService) {
+	updated, err := powerPlantService.BackfillElevation(context.Background(), backfillBatchSize)
+	if err != nil {
+		slog.Error("can't backfill elevation", "error", err, "updated", updated)
+		return
+	}
+
+	slog.Info("Elevation backfilled successfully", "updated", updated)
+}
+
+// importPowerPlants creates and updates the power plants of a file, e.g. kaze import --format geojson plants.geojson.
+// The file is read from stdin if it is "-" or not given.
+func importPowerPlants(powerPlantService service.PowerPlantService, args []string) {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "geojson", "format of the file, only geojson (a FeatureCollection of Point features) is supported")
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *format != "geojson" {
+		slog.Error("unknown import format, available formats: geojson", "format", *format)
+		return
+	}
+
+	input := os.Stdin
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			slog.Error("can't open the import file", "error", err)
+			return
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var collection geojson.FeatureCollection
+	if err := json.NewDecoder(input).Decode(&collection); err != nil {
+		slog.Error("can't decode the GeoJSON FeatureCollection", "error", err)
+		return
+	}
+
+	result, err := powerPlantService.ImportPowerPlants(context.Background(), collection)
+	if err != nil {
+		slog.Error("can't import power plants", "error", err)
+		if result == nil {
+			return
+		}
+	}
+	for _, featureError := range result.Errors {
+		id := ""
+		if featureError.ID != nil {
+			id = *featureError.ID
+		}
+		slog.Warn("Feature was not imported", "index", featureError.Index, "id", id, "error", featureError.Message)
+	}
+
+	slog.Info("Power plants imported", "created", result.Created, "updated", result.Updated, "errors", len(result.Errors))
+}
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/glower/kaze/pkg/service"
+)
+
+// Error codes set in the extensions of GraphQL errors.
+const (
+	CodeNotFound            = "NOT_FOUND"
+	CodeValidation          = "BAD_USER_INPUT"
+	CodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	CodeConflict            = "CONFLICT"
+	CodeInternal            = "INTERNAL_SERVER_ERROR"
+)
+
+// ErrorPresenter presents resolver errors as GraphQL errors with the error code of the service
+// error in extensions.code.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	if _, ok := gqlErr.Extensions["code"]; !ok {
+		gqlErr.Extensions["code"] = errorCode(err)
+	}
+
+	return gqlErr
+}
+
+// errorCode maps the service error taxonomy to an error code.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, service.ErrValidation):
+		return CodeValidation
+	case errors.Is(err, service.ErrUpstreamUnavailable):
+		return CodeUpstreamUnavailable
+	case errors.Is(err, service.ErrConflict):
+		return CodeConflict
+	default:
+		return CodeInternal
+	}
+}
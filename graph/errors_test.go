@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/glower/kaze/mocks"
+	"github.com/glower/kaze/pkg/service"
+)
+
+func TestErrorPresenter(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode string
+	}{
+		{
+			name:         "not found",
+			err:          fmt.Errorf("error retrieving power plant by ID: %w", fmt.Errorf("%w: power plant 42", service.ErrNotFound)),
+			expectedCode: CodeNotFound,
+		},
+		{
+			name:         "validation",
+			err:          service.ErrDateOutOfRange,
+			expectedCode: CodeValidation,
+		},
+		{
+			name:         "upstream unavailable",
+			err:          fmt.Errorf("%w: timeout", service.ErrUpstreamUnavailable),
+			expectedCode: CodeUpstreamUnavailable,
+		},
+		{
+			name:         "conflict",
+			err:          fmt.Errorf("%w: power plant 1 is already in the requested state", service.ErrConflict),
+			expectedCode: CodeConflict,
+		},
+		{
+			name:         "unknown error",
+			err:          assert.AnError,
+			expectedCode: CodeInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		gqlErr := ErrorPresenter(context.Background(), tc.err)
+		assert.Equal(t, tc.expectedCode, gqlErr.Extensions["code"], "Failed test: "+tc.name)
+		assert.Equal(t, tc.err.Error(), gqlErr.Message, "Failed test: "+tc.name)
+	}
+}
+
+func TestPowerPlantNotFound(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	srv := handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}}))
+	srv.SetErrorPresenter(ErrorPresenter)
+	c := client.New(srv)
+
+	mockService.On("GetPowerPlant", mock.Anything, "42", false).Return(nil, fmt.Errorf("%w: power plant 42", service.ErrNotFound)).Once()
+
+	resp, err := c.RawPost(`query { powerPlant(id: "42") { id } }`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"powerPlant": nil}, resp.Data)
+	assert.Contains(t, string(resp.Errors), `"code":"NOT_FOUND"`)
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// dateLayout is the layout of the Date scalar.
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day without a time zone, it is stored at midnight UTC.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the calendar day of t in its location.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// UnmarshalGQL parses a Date in the format YYYY-MM-DD.
+func (d *Date) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("date must be a string in the format YYYY-MM-DD, got %T", v)
+	}
+
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return fmt.Errorf("date must be in the format YYYY-MM-DD, got %q", s)
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalGQL writes the Date in the format YYYY-MM-DD.
+func (d Date) MarshalGQL(w io.Writer) {
+	io.WriteString(w, strconv.Quote(d.Format(dateLayout)))
+}
+
+// MarshalJSON writes the Date as a JSON string in the format YYYY-MM-DD.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.Format(dateLayout))), nil
+}
+
+// UnmarshalJSON parses a Date from a JSON string in the format YYYY-MM-DD.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("date must be a string in the format YYYY-MM-DD, got %s", data)
+	}
+	return d.UnmarshalGQL(s)
+}
+
+// Scan reads a Date from a DATE column.
+func (d *Date) Scan(src any) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("can't scan %T into a date", src)
+	}
+	*d = NewDate(t)
+	return nil
+}
+
+// Value writes the Date to a DATE column.
+func (d Date) Value() (driver.Value, error) {
+	return d.Format(dateLayout), nil
+}
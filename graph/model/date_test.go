@@ -0,0 +1,67 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDate(t *testing.T) {
+	t.Run("parse a calendar day", func(t *testing.T) {
+		var d Date
+		assert.NoError(t, d.UnmarshalGQL("2024-02-29"))
+		assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), d.Time)
+
+		var buf bytes.Buffer
+		d.MarshalGQL(&buf)
+		assert.Equal(t, `"2024-02-29"`, buf.String())
+	})
+
+	t.Run("fail due to an invalid day", func(t *testing.T) {
+		var d Date
+		assert.Error(t, d.UnmarshalGQL("2023-02-29"))
+		assert.Error(t, d.UnmarshalGQL("2024-01-01T00:00:00Z"))
+		assert.Error(t, d.UnmarshalGQL(20240101))
+	})
+
+	t.Run("keep the local day", func(t *testing.T) {
+		d := NewDate(time.Date(2024, 1, 1, 1, 0, 0, 0, time.FixedZone("JST", 9*60*60)))
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), d.Time)
+	})
+
+	t.Run("encode as JSON", func(t *testing.T) {
+		data, err := json.Marshal(NewDate(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)))
+		assert.NoError(t, err)
+		assert.Equal(t, `"2024-02-29"`, string(data))
+
+		var d Date
+		assert.NoError(t, json.Unmarshal([]byte(`"2024-02-29"`), &d))
+		assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), d.Time)
+		assert.Error(t, json.Unmarshal([]byte(`"2024-02-29T00:00:00Z"`), &d))
+		assert.Error(t, json.Unmarshal([]byte(`20240229`), &d))
+	})
+}
+
+func TestDateTime(t *testing.T) {
+	t.Run("write the time in UTC", func(t *testing.T) {
+		var buf bytes.Buffer
+		MarshalDateTime(time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))).MarshalGQL(&buf)
+		assert.Equal(t, `"2024-01-01T00:00:00Z"`, buf.String())
+	})
+
+	t.Run("parse a time with an offset", func(t *testing.T) {
+		parsed, err := UnmarshalDateTime("2024-01-01T09:00:00+09:00")
+		assert.NoError(t, err)
+		assert.True(t, parsed.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("fail due to a time without an offset", func(t *testing.T) {
+		_, err := UnmarshalDateTime("2024-01-01T00:00")
+		assert.Error(t, err)
+		_, err = UnmarshalDateTime(1704067200)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// MarshalDateTime writes a point in time in RFC 3339 format in UTC, e.g. "2024-01-01T15:00:00Z".
+func MarshalDateTime(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(t.UTC().Format(time.RFC3339)))
+	})
+}
+
+// UnmarshalDateTime parses a point in time in RFC 3339 format with any offset.
+func UnmarshalDateTime(v any) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("date time must be a string in RFC 3339 format, got %T", v)
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date time must be in RFC 3339 format, e.g. 2024-01-01T15:00:00Z, got %q", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/glower/kaze/pkg/geojson"
+)
+
+// MarshalGeoJSON writes a GeoJSON geometry as an object.
+func MarshalGeoJSON(g geojson.Geometry) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		data, err := json.Marshal(g)
+		if err != nil {
+			io.WriteString(w, "null")
+			return
+		}
+		w.Write(data)
+	})
+}
+
+// UnmarshalGeoJSON parses a GeoJSON geometry given as an object or as a JSON string. The coordinates are
+// only decoded when the geometry is used.
+func UnmarshalGeoJSON(v any) (geojson.Geometry, error) {
+	var g geojson.Geometry
+	if err := decodeGeoJSON(v, &g); err != nil {
+		return geojson.Geometry{}, fmt.Errorf("invalid GeoJSON geometry: %w", err)
+	}
+	if g.Type == "" {
+		return geojson.Geometry{}, fmt.Errorf("GeoJSON geometry must have a type")
+	}
+	return g, nil
+}
+
+// MarshalGeoJSONFeatureCollection writes a GeoJSON feature collection as an object.
+func MarshalGeoJSONFeatureCollection(c geojson.FeatureCollection) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		data, err := json.Marshal(c)
+		if err != nil {
+			io.WriteString(w, "null")
+			return
+		}
+		w.Write(data)
+	})
+}
+
+// UnmarshalGeoJSONFeatureCollection parses a GeoJSON feature collection given as an object or as a JSON string.
+// The properties of the features are only decoded when they are imported.
+func UnmarshalGeoJSONFeatureCollection(v any) (geojson.FeatureCollection, error) {
+	var c geojson.FeatureCollection
+	if err := decodeGeoJSON(v, &c); err != nil {
+		return geojson.FeatureCollection{}, fmt.Errorf("invalid GeoJSON feature collection: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return geojson.FeatureCollection{}, err
+	}
+	return c, nil
+}
+
+// decodeGeoJSON decodes a GeoJSON object given as an object or as a JSON string.
+func decodeGeoJSON(v any, target any) error {
+	data, ok := v.(string)
+	if !ok {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("GeoJSON must be an object or a JSON string, got %T", v)
+		}
+		data = string(encoded)
+	}
+	return json.Unmarshal([]byte(data), target)
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoJSON(t *testing.T) {
+	polygon := `{"type":"Polygon","coordinates":[[[139.5,35.2],[140.2,35.2],[139.8,35.8],[139.5,35.2]]]}`
+
+	t.Run("parse an object", func(t *testing.T) {
+		g, err := UnmarshalGeoJSON(map[string]any{
+			"type":        "Polygon",
+			"coordinates": []any{[]any{[]any{139.5, 35.2}, []any{140.2, 35.2}, []any{139.8, 35.8}, []any{139.5, 35.2}}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Polygon", g.Type)
+
+		var buf bytes.Buffer
+		MarshalGeoJSON(g).MarshalGQL(&buf)
+		assert.JSONEq(t, polygon, buf.String())
+	})
+
+	t.Run("parse a JSON string", func(t *testing.T) {
+		g, err := UnmarshalGeoJSON(polygon)
+		assert.NoError(t, err)
+		assert.Equal(t, "Polygon", g.Type)
+		assert.JSONEq(t, `[[[139.5,35.2],[140.2,35.2],[139.8,35.8],[139.5,35.2]]]`, string(g.Coordinates))
+	})
+
+	t.Run("fail due to invalid GeoJSON", func(t *testing.T) {
+		_, err := UnmarshalGeoJSON(`{"type":`)
+		assert.Error(t, err)
+
+		_, err = UnmarshalGeoJSON(map[string]any{"coordinates": []any{}})
+		assert.ErrorContains(t, err, "must have a type")
+
+		_, err = UnmarshalGeoJSON(42)
+		assert.Error(t, err)
+	})
+}
+
+func TestGeoJSONFeatureCollection(t *testing.T) {
+	collection := `{"type":"FeatureCollection","features":[{"type":"Feature","id":"7","geometry":{"type":"Point","coordinates":[139.638,35.4437]},"properties":{"name":"Yokohama"}}]}`
+
+	c, err := UnmarshalGeoJSONFeatureCollection(collection)
+	assert.NoError(t, err)
+	assert.Len(t, c.Features, 1)
+	assert.Equal(t, "7", c.Features[0].FeatureID())
+
+	var buf bytes.Buffer
+	MarshalGeoJSONFeatureCollection(c).MarshalGQL(&buf)
+	assert.JSONEq(t, collection, buf.String())
+
+	_, err = UnmarshalGeoJSONFeatureCollection(map[string]any{"type": "Feature"})
+	assert.ErrorContains(t, err, "must be a FeatureCollection")
+}
@@ -2,27 +2,154 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// Area between two latitudes and two longitudes in degrees, it crosses the antimeridian if minLongitude is greater than maxLongitude
+type BoundingBoxInput struct {
+	MinLatitude  float64 `json:"minLatitude"`
+	MinLongitude float64 `json:"minLongitude"`
+	MaxLatitude  float64 `json:"maxLatitude"`
+	MaxLongitude float64 `json:"maxLongitude"`
+}
+
+type DailyWeather struct {
+	// Local calendar day of the power plant
+	Date Date `json:"date"`
+	// Minimum temperature (2 m) in celsius
+	TemperatureMin *float64 `json:"temperatureMin,omitempty"`
+	// Maximum temperature (2 m) in celsius
+	TemperatureMax *float64 `json:"temperatureMax,omitempty"`
+	// Sum of the precipitation (rain + showers + snow) in millimeter
+	PrecipitationSum *float64 `json:"precipitationSum,omitempty"`
+	// Maximum wind speed (10 m) in Km/h
+	WindSpeedMax *float64 `json:"windSpeedMax,omitempty"`
+	// Maximum wind gusts (10 m) in Km/h
+	WindGustsMax *float64 `json:"windGustsMax,omitempty"`
+	// Dominant wind direction (10 m) in degrees
+	WindDirectionDominant *float64 `json:"windDirectionDominant,omitempty"`
+	// Time of the sunrise, null if openmeteo has no sunrise for the day
+	Sunrise *time.Time `json:"sunrise,omitempty"`
+	// Time of the sunset, null if openmeteo has no sunset for the day
+	Sunset *time.Time `json:"sunset,omitempty"`
+	// Sunshine duration in seconds
+	SunshineDuration *float64 `json:"sunshineDuration,omitempty"`
+	// True if openmeteo is not available and the last known aggregates are returned
+	Stale bool `json:"stale"`
+	// Time the aggregates were fetched from openmeteo
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"`
+}
+
+// Error of a feature that was not imported
+type FeatureError struct {
+	// Position of the feature in the collection, starting at 0
+	Index int `json:"index"`
+	// ID of the feature, null if it has none
+	ID *string `json:"id,omitempty"`
+	// Reason why the feature was not imported
+	Message string `json:"message"`
+}
+
+type HistoricalHour struct {
+	// Time of the hour
+	Time time.Time `json:"time"`
+	// Temperature (2 m) in celsius, null if the reanalysis has no data for the hour yet
+	Temperature *float64 `json:"temperature,omitempty"`
+	// Precipitation (rain + showers + snow) in millimeter, null if the reanalysis has no data for the hour yet
+	Precipitation *float64 `json:"precipitation,omitempty"`
+	// Wind Speed (10 m) in Km/h, null if the reanalysis has no data for the hour yet
+	WindSpeed *float64 `json:"windSpeed,omitempty"`
+	// Wind Direction (10 m) in degrees, null if the reanalysis has no data for the hour yet
+	WindDirection *float64 `json:"windDirection,omitempty"`
+}
+
+type HistoricalWeather struct {
+	// Hourly weather, null for the daily resolution
+	Hourly []*HistoricalHour `json:"hourly,omitempty"`
+	// Daily aggregates, null for the hourly resolution
+	Daily []*DailyWeather `json:"daily,omitempty"`
+}
+
+// Result of an import of power plants
+type ImportResult struct {
+	// Number of created power plants
+	Created int `json:"created"`
+	// Number of updated power plants
+	Updated int `json:"updated"`
+	// Errors of the features that were not imported
+	Errors []*FeatureError `json:"errors"`
+}
+
 type NewPowerPlantInput struct {
 	Name      string  `json:"name"      validate:"required,min=2,max=100"`
 	Latitude  float64 `json:"latitude"  validate:"required,latitude"`
 	Longitude float64 `json:"longitude" validate:"required,longitude"`
+	// Weather provider (e.g. openmeteo, metno, dwd, failover or ensemble), the default provider of the deployment is used if it is not set
+	WeatherProvider *string `json:"weatherProvider,omitempty" validate:"omitempty,max=100"`
+	// Technology of the power plant
+	PlantType *PlantType `json:"plantType,omitempty"`
+	// Nameplate capacity in MW, limited by the maximum capacity of the plant type
+	CapacityMw *float64 `json:"capacityMw,omitempty"`
+	// Day the power plant was or is planned to be commissioned, only planned power plants can have a commissioning date in the future
+	CommissioningDate *Date `json:"commissioningDate,omitempty"`
+	// Operational status of the power plant
+	Status *PlantStatus `json:"status,omitempty"`
+	// Company operating the power plant
+	Operator *string `json:"operator,omitempty" validate:"omitempty,max=255"`
 }
 
-type PowerPlant struct {
-	// ID of the power plant
-	ID string `json:"id"`
-	// Name of the power plant
-	Name string `json:"name"`
-	// Latitude in degrees
-	Latitude float64 `json:"latitude"`
-	// Longitude in degrees
+type PageInfo struct {
+	HasNextPage     bool `json:"hasNextPage"`
+	HasPreviousPage bool `json:"hasPreviousPage"`
+	// Cursor of the first power plant of the page
+	StartCursor *string `json:"startCursor,omitempty"`
+	// Cursor of the last power plant of the page
+	EndCursor *string `json:"endCursor,omitempty"`
+}
+
+// Point on the earth in degrees
+type PointInput struct {
+	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
-	// Provided forecasts from openmeteo for the weather
-	WeatherForecasts []*WeatherForecast `json:"weatherForecasts"`
-	// Is there precipitation at the power plant today?
-	HasPrecipitationToday bool `json:"hasPrecipitationToday"`
-	// Elevation of the power plant
-	Elevation float64 `json:"elevation"`
+}
+
+// A power plant with its distance to a point
+type PowerPlantDistance struct {
+	PowerPlant *PowerPlant `json:"powerPlant"`
+	// Great-circle distance to the point in km
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+type PowerPlantEdge struct {
+	// Cursor of the power plant for the after and before arguments
+	Cursor string      `json:"cursor"`
+	Node   *PowerPlant `json:"node"`
+}
+
+// Conditions on the power plants of a list, the conditions that are set must all match
+type PowerPlantFilter struct {
+	// Case insensitive part of the name
+	NameContains *string `json:"nameContains,omitempty"`
+	// Fuzzy search of the words of the name that tolerates typos, e.g. hokaido matches Hokkaido Wind Farm
+	Search *string `json:"search,omitempty"`
+	// Power plants of any of the types
+	PlantTypes []PlantType `json:"plantTypes,omitempty"`
+	// Power plants with any of the statuses
+	Statuses []PlantStatus `json:"statuses,omitempty"`
+	// Minimum nameplate capacity in MW, inclusive
+	MinCapacityMw *float64 `json:"minCapacityMw,omitempty"`
+	// Maximum nameplate capacity in MW, inclusive
+	MaxCapacityMw *float64 `json:"maxCapacityMw,omitempty"`
+	// Power plants within the bounding box
+	BoundingBox *BoundingBoxInput `json:"boundingBox,omitempty"`
+	// Power plants created after the time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
 }
 
 type PowerPlantList struct {
@@ -32,21 +159,523 @@ type PowerPlantList struct {
 	TotalCount int `json:"totalCount"`
 }
 
+// Order of the power plants of a list, power plants with the same value are ordered by ID and power plants without a value come last
+type PowerPlantSort struct {
+	Field     PowerPlantSortField `json:"field"`
+	Direction *SortDirection      `json:"direction,omitempty"`
+	// Point the distance is measured from, required for the DISTANCE field
+	From *PointInput `json:"from,omitempty"`
+}
+
+// Units of the weather values, the units that are not set are the units of the system
+type UnitsInput struct {
+	// Unit system, METRIC is celsius, km/h and millimeter, IMPERIAL is fahrenheit, mph and inch
+	System *UnitSystem `json:"system,omitempty"`
+	// Temperature unit instead of the unit of the system
+	Temperature *TemperatureUnit `json:"temperature,omitempty"`
+	// Wind speed unit instead of the unit of the system, e.g. KN for offshore wind farms
+	WindSpeed *WindSpeedUnit `json:"windSpeed,omitempty"`
+	// Precipitation unit instead of the unit of the system
+	Precipitation *PrecipitationUnit `json:"precipitation,omitempty"`
+}
+
+// Fields of a power plant to update, fields that are not set are left unchanged
 type UpdatePowerPlantInput struct {
 	Name      *string  `json:"name,omitempty"      validate:"omitempty,min=2,max=100"`
 	Latitude  *float64 `json:"latitude,omitempty"  validate:"omitempty,latitude"`
 	Longitude *float64 `json:"longitude,omitempty" validate:"omitempty,longitude"`
+	// Weather provider (e.g. openmeteo, metno, dwd, failover or ensemble), an empty string resets it to the default provider of the deployment
+	WeatherProvider *string `json:"weatherProvider,omitempty" validate:"omitempty,max=100"`
+	// Technology of the power plant, null removes it
+	PlantType graphql.Omittable[*PlantType] `json:"plantType,omitempty"`
+	// Nameplate capacity in MW, limited by the maximum capacity of the plant type, null removes it
+	CapacityMw graphql.Omittable[*float64] `json:"capacityMw,omitempty"`
+	// Day the power plant was or is planned to be commissioned, only planned power plants can have a commissioning date in the future, null removes it
+	CommissioningDate graphql.Omittable[*Date] `json:"commissioningDate,omitempty"`
+	// Operational status of the power plant
+	Status *PlantStatus `json:"status,omitempty"`
+	// Company operating the power plant, an empty string removes the operator
+	Operator *string `json:"operator,omitempty" validate:"omitempty,max=255"`
 }
 
 type WeatherForecast struct {
-	// Time of the forecast in UTC/GMT
-	Time string `json:"time"`
-	// Temperature (2 m) in celsius
+	// Time of the forecast
+	Time time.Time `json:"time"`
+	// Temperature (2 m) in the temperature unit of units
 	Temperature float64 `json:"temperature"`
-	// Precipitation (rain + showers + snow) in millimeter
+	// Precipitation (rain + showers + snow) in the precipitation unit of units
 	Precipitation float64 `json:"precipitation"`
-	// Wind Speed (10 m) in Km/h
+	// Wind Speed (10 m) in the wind speed unit of units
 	WindSpeed float64 `json:"windSpeed"`
 	// Wind Direction (10 m) in degrees
 	WindDirection float64 `json:"windDirection"`
+	// True if the weather provider is not available and the last known forecast is returned
+	Stale bool `json:"stale"`
+	// Time the forecast was fetched from the weather provider
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"`
+	// Weather provider or model that produced the values, e.g. openmeteo:icon_seamless or ensemble(openmeteo,metno) for blended values
+	Source string `json:"source"`
+	// Units of the values, as requested with the units argument of the weatherForecasts field
+	Units *WeatherUnits `json:"units"`
+	// Wind Speed (80 m) in the wind speed unit of units
+	WindSpeed80m *float64 `json:"windSpeed80m,omitempty"`
+	// Wind Speed (120 m) in the wind speed unit of units
+	WindSpeed120m *float64 `json:"windSpeed120m,omitempty"`
+	// Wind Speed (180 m) in the wind speed unit of units
+	WindSpeed180m *float64 `json:"windSpeed180m,omitempty"`
+	// Wind Direction (80 m) in degrees
+	WindDirection80m *float64 `json:"windDirection80m,omitempty"`
+	// Wind Direction (120 m) in degrees
+	WindDirection120m *float64 `json:"windDirection120m,omitempty"`
+	// Wind Direction (180 m) in degrees
+	WindDirection180m *float64 `json:"windDirection180m,omitempty"`
+	// Wind Gusts (10 m), the maximum of the preceding hour in the wind speed unit of units
+	WindGusts10m *float64 `json:"windGusts10m,omitempty"`
+	// Low level clouds and fog up to 3 km altitude in percent
+	CloudCoverLow *float64 `json:"cloudCoverLow,omitempty"`
+	// Mid level clouds from 3 to 8 km altitude in percent
+	CloudCoverMid *float64 `json:"cloudCoverMid,omitempty"`
+	// High level clouds from 8 km altitude in percent
+	CloudCoverHigh *float64 `json:"cloudCoverHigh,omitempty"`
+	// Shortwave solar radiation (GHI), the average of the preceding hour in W/m²
+	ShortwaveRadiation *float64 `json:"shortwaveRadiation,omitempty"`
+	// Direct solar radiation on the horizontal plane, the average of the preceding hour in W/m²
+	DirectRadiation *float64 `json:"directRadiation,omitempty"`
+	// Diffuse solar radiation (DHI), the average of the preceding hour in W/m²
+	DiffuseRadiation *float64 `json:"diffuseRadiation,omitempty"`
+	// Global tilted irradiance (GTI) on the panels with the tilt and azimuth of the weatherForecasts field, the average of the preceding hour in W/m²
+	GlobalTiltedIrradiance *float64 `json:"globalTiltedIrradiance,omitempty"`
+	// Surface pressure in hPa
+	SurfacePressure *float64 `json:"surfacePressure,omitempty"`
+	// Relative humidity (2 m) in percent
+	RelativeHumidity2m *float64 `json:"relativeHumidity2m,omitempty"`
+	// Snowfall sum of the preceding hour in centimeter, inch for the INCH precipitation unit
+	Snowfall *float64 `json:"snowfall,omitempty"`
+	// Snow depth on the ground in meter
+	SnowDepth *float64 `json:"snowDepth,omitempty"`
+}
+
+// A weather provider and the range of its hourly forecasts
+type WeatherProviderInfo struct {
+	// Name of the provider, e.g. metno or openmeteo:icon_seamless
+	Name string `json:"name"`
+	// True for the provider of the power plants without a weather provider
+	Default bool `json:"default"`
+	// How many hours after now the hourly forecasts reach at most, weatherForecasts returns fewer hours for a longer forecastDays
+	ForecastHours int `json:"forecastHours"`
+	// Maximum number of past days, 0 if the provider has no forecasts of past days
+	PastDays int `json:"pastDays"`
+}
+
+type WeatherUnits struct {
+	Temperature   TemperatureUnit   `json:"temperature"`
+	WindSpeed     WindSpeedUnit     `json:"windSpeed"`
+	Precipitation PrecipitationUnit `json:"precipitation"`
+}
+
+// Operational status of a power plant
+type PlantStatus string
+
+const (
+	// The power plant is not commissioned yet
+	PlantStatusPlanned PlantStatus = "PLANNED"
+	// The power plant produces electricity
+	PlantStatusOperating PlantStatus = "OPERATING"
+	// The power plant is temporarily shut down for maintenance
+	PlantStatusMaintenance PlantStatus = "MAINTENANCE"
+	// The power plant is permanently shut down
+	PlantStatusDecommissioned PlantStatus = "DECOMMISSIONED"
+)
+
+var AllPlantStatus = []PlantStatus{
+	PlantStatusPlanned,
+	PlantStatusOperating,
+	PlantStatusMaintenance,
+	PlantStatusDecommissioned,
+}
+
+func (e PlantStatus) IsValid() bool {
+	switch e {
+	case PlantStatusPlanned, PlantStatusOperating, PlantStatusMaintenance, PlantStatusDecommissioned:
+		return true
+	}
+	return false
+}
+
+func (e PlantStatus) String() string {
+	return string(e)
+}
+
+func (e *PlantStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PlantStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PlantStatus", str)
+	}
+	return nil
+}
+
+func (e PlantStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Technology of a power plant
+type PlantType string
+
+const (
+	PlantTypeSolar      PlantType = "SOLAR"
+	PlantTypeWind       PlantType = "WIND"
+	PlantTypeGeothermal PlantType = "GEOTHERMAL"
+	PlantTypeTidal      PlantType = "TIDAL"
+	PlantTypeBiomass    PlantType = "BIOMASS"
+	PlantTypeHydro      PlantType = "HYDRO"
+)
+
+var AllPlantType = []PlantType{
+	PlantTypeSolar,
+	PlantTypeWind,
+	PlantTypeGeothermal,
+	PlantTypeTidal,
+	PlantTypeBiomass,
+	PlantTypeHydro,
+}
+
+func (e PlantType) IsValid() bool {
+	switch e {
+	case PlantTypeSolar, PlantTypeWind, PlantTypeGeothermal, PlantTypeTidal, PlantTypeBiomass, PlantTypeHydro:
+		return true
+	}
+	return false
+}
+
+func (e PlantType) String() string {
+	return string(e)
+}
+
+func (e *PlantType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PlantType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PlantType", str)
+	}
+	return nil
+}
+
+func (e PlantType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PowerPlantSortField string
+
+const (
+	PowerPlantSortFieldID   PowerPlantSortField = "ID"
+	PowerPlantSortFieldName PowerPlantSortField = "NAME"
+	// Nameplate capacity
+	PowerPlantSortFieldCapacity  PowerPlantSortField = "CAPACITY"
+	PowerPlantSortFieldCreatedAt PowerPlantSortField = "CREATED_AT"
+	// Great-circle distance from the point of the sort
+	PowerPlantSortFieldDistance PowerPlantSortField = "DISTANCE"
+)
+
+var AllPowerPlantSortField = []PowerPlantSortField{
+	PowerPlantSortFieldID,
+	PowerPlantSortFieldName,
+	PowerPlantSortFieldCapacity,
+	PowerPlantSortFieldCreatedAt,
+	PowerPlantSortFieldDistance,
+}
+
+func (e PowerPlantSortField) IsValid() bool {
+	switch e {
+	case PowerPlantSortFieldID, PowerPlantSortFieldName, PowerPlantSortFieldCapacity, PowerPlantSortFieldCreatedAt, PowerPlantSortFieldDistance:
+		return true
+	}
+	return false
+}
+
+func (e PowerPlantSortField) String() string {
+	return string(e)
+}
+
+func (e *PowerPlantSortField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PowerPlantSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PowerPlantSortField", str)
+	}
+	return nil
+}
+
+func (e PowerPlantSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PrecipitationUnit string
+
+const (
+	PrecipitationUnitMillimeter PrecipitationUnit = "MILLIMETER"
+	PrecipitationUnitInch       PrecipitationUnit = "INCH"
+)
+
+var AllPrecipitationUnit = []PrecipitationUnit{
+	PrecipitationUnitMillimeter,
+	PrecipitationUnitInch,
+}
+
+func (e PrecipitationUnit) IsValid() bool {
+	switch e {
+	case PrecipitationUnitMillimeter, PrecipitationUnitInch:
+		return true
+	}
+	return false
+}
+
+func (e PrecipitationUnit) String() string {
+	return string(e)
+}
+
+func (e *PrecipitationUnit) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PrecipitationUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PrecipitationUnit", str)
+	}
+	return nil
+}
+
+func (e PrecipitationUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TemperatureUnit string
+
+const (
+	TemperatureUnitCelsius    TemperatureUnit = "CELSIUS"
+	TemperatureUnitFahrenheit TemperatureUnit = "FAHRENHEIT"
+)
+
+var AllTemperatureUnit = []TemperatureUnit{
+	TemperatureUnitCelsius,
+	TemperatureUnitFahrenheit,
+}
+
+func (e TemperatureUnit) IsValid() bool {
+	switch e {
+	case TemperatureUnitCelsius, TemperatureUnitFahrenheit:
+		return true
+	}
+	return false
+}
+
+func (e TemperatureUnit) String() string {
+	return string(e)
+}
+
+func (e *TemperatureUnit) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TemperatureUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TemperatureUnit", str)
+	}
+	return nil
+}
+
+func (e TemperatureUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type UnitSystem string
+
+const (
+	// Celsius, km/h and millimeter
+	UnitSystemMetric UnitSystem = "METRIC"
+	// Fahrenheit, mph and inch
+	UnitSystemImperial UnitSystem = "IMPERIAL"
+)
+
+var AllUnitSystem = []UnitSystem{
+	UnitSystemMetric,
+	UnitSystemImperial,
+}
+
+func (e UnitSystem) IsValid() bool {
+	switch e {
+	case UnitSystemMetric, UnitSystemImperial:
+		return true
+	}
+	return false
+}
+
+func (e UnitSystem) String() string {
+	return string(e)
+}
+
+func (e *UnitSystem) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UnitSystem(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UnitSystem", str)
+	}
+	return nil
+}
+
+func (e UnitSystem) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WeatherResolution string
+
+const (
+	// Hourly weather
+	WeatherResolutionHourly WeatherResolution = "HOURLY"
+	// Daily aggregates
+	WeatherResolutionDaily WeatherResolution = "DAILY"
+)
+
+var AllWeatherResolution = []WeatherResolution{
+	WeatherResolutionHourly,
+	WeatherResolutionDaily,
+}
+
+func (e WeatherResolution) IsValid() bool {
+	switch e {
+	case WeatherResolutionHourly, WeatherResolutionDaily:
+		return true
+	}
+	return false
+}
+
+func (e WeatherResolution) String() string {
+	return string(e)
+}
+
+func (e *WeatherResolution) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WeatherResolution(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WeatherResolution", str)
+	}
+	return nil
+}
+
+func (e WeatherResolution) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WindSpeedUnit string
+
+const (
+	// Kilometers per hour
+	WindSpeedUnitKmh WindSpeedUnit = "KMH"
+	// Meters per second
+	WindSpeedUnitMs WindSpeedUnit = "MS"
+	// Knots
+	WindSpeedUnitKn WindSpeedUnit = "KN"
+	// Miles per hour
+	WindSpeedUnitMph WindSpeedUnit = "MPH"
+)
+
+var AllWindSpeedUnit = []WindSpeedUnit{
+	WindSpeedUnitKmh,
+	WindSpeedUnitMs,
+	WindSpeedUnitKn,
+	WindSpeedUnitMph,
+}
+
+func (e WindSpeedUnit) IsValid() bool {
+	switch e {
+	case WindSpeedUnitKmh, WindSpeedUnitMs, WindSpeedUnitKn, WindSpeedUnitMph:
+		return true
+	}
+	return false
+}
+
+func (e WindSpeedUnit) String() string {
+	return string(e)
+}
+
+func (e *WindSpeedUnit) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WindSpeedUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WindSpeedUnit", str)
+	}
+	return nil
+}
+
+func (e WindSpeedUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
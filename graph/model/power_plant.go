@@ -0,0 +1,155 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// PowerPlant is a power plant as stored in the database.
+// Weather data are fetched on demand by the PowerPlant field resolvers.
+type PowerPlant struct {
+	// ID of the power plant
+	ID string `json:"id"`
+	// Name of the power plant
+	Name string `json:"name"`
+	// Latitude in degrees
+	Latitude float64 `json:"latitude"`
+	// Longitude in degrees
+	Longitude float64 `json:"longitude"`
+	// Elevation of the power plant, nil if it was not fetched from the api yet
+	Elevation *float64 `json:"elevation"`
+	// Time the power plant was created
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	// Time the power plant was deleted, nil if it is not deleted
+	DeletedAt *time.Time `json:"deletedAt" db:"deleted_at"`
+	// Name of the weather provider of the power plant, nil uses the default provider
+	WeatherProvider *string `json:"weatherProvider" db:"weather_provider"`
+	// Technology of the power plant, nil if it is not known
+	PlantType *PlantType `json:"plantType" db:"plant_type"`
+	// Nameplate capacity in MW
+	CapacityMw *float64 `json:"capacityMw" db:"capacity_mw"`
+	// Day the power plant was or is planned to be commissioned
+	CommissioningDate *Date `json:"commissioningDate" db:"commissioning_date"`
+	// Operational status, an empty status is stored as operating
+	Status PlantStatus `json:"status" db:"status"`
+	// Company operating the power plant
+	Operator *string `json:"operator" db:"operator"`
+}
+
+// PowerPlantPatch is a partial update of a power plant.
+// Nil and omitted fields are left untouched, set fields are written even if they are zero values or nil.
+type PowerPlantPatch struct {
+	// ID of the power plant to update
+	ID string
+	// New name of the power plant
+	Name *string
+	// New latitude in degrees
+	Latitude *float64
+	// New longitude in degrees
+	Longitude *float64
+	// New weather provider, an empty name resets it to the default provider
+	WeatherProvider *string
+	// Elevation for the new coordinates, the stored elevation is cleared if the coordinates
+	// are updated without an elevation
+	Elevation *float64
+	// New technology of the power plant, nil removes it
+	PlantType graphql.Omittable[*PlantType]
+	// New nameplate capacity in MW, nil removes it
+	CapacityMw graphql.Omittable[*float64]
+	// New commissioning day, nil removes it
+	CommissioningDate graphql.Omittable[*Date]
+	// New operational status
+	Status *PlantStatus
+	// New operator, an empty name removes the operator
+	Operator *string
+}
+
+// maxCapacityMw is the maximum nameplate capacity of a power plant of the type in MW,
+// a margin above the largest plants of the type in operation.
+var maxCapacityMw = map[PlantType]float64{
+	PlantTypeSolar:      10_000,
+	PlantTypeWind:       25_000,
+	PlantTypeGeothermal: 2_000,
+	PlantTypeTidal:      1_000,
+	PlantTypeBiomass:    3_000,
+	PlantTypeHydro:      25_000,
+}
+
+// maxCapacityOfUnknownType is the maximum nameplate capacity of a power plant without a type in MW.
+const maxCapacityOfUnknownType = 25_000
+
+// Validate checks the capacity against the limit of the plant type and the commissioning date against
+// the status, only planned power plants can be commissioned after today.
+func (p *PowerPlant) Validate(today time.Time) error {
+	if p.CapacityMw != nil {
+		maxCapacity := float64(maxCapacityOfUnknownType)
+		if p.PlantType != nil {
+			maxCapacity = maxCapacityMw[*p.PlantType]
+		}
+		if *p.CapacityMw <= 0 || *p.CapacityMw > maxCapacity {
+			return fmt.Errorf("capacity of %s must be positive and at most %g MW, got %g MW", p.typeName(), maxCapacity, *p.CapacityMw)
+		}
+	}
+
+	if p.CommissioningDate != nil && p.Status != PlantStatusPlanned && p.CommissioningDate.After(NewDate(today).Time) {
+		return fmt.Errorf("commissioning date %s is in the future, the power plant must be planned", p.CommissioningDate.Format(dateLayout))
+	}
+
+	return nil
+}
+
+// typeName is the name of the plant type in validation errors.
+func (p *PowerPlant) typeName() string {
+	if p.PlantType == nil {
+		return "a power plant"
+	}
+	return "a " + strings.ToLower(string(*p.PlantType)) + " power plant"
+}
+
+// Apply returns a copy of the power plant with the fields of the patch.
+func (p *PowerPlantPatch) Apply(plant PowerPlant) PowerPlant {
+	if p.Name != nil {
+		plant.Name = *p.Name
+	}
+	if p.Latitude != nil {
+		plant.Latitude = *p.Latitude
+	}
+	if p.Longitude != nil {
+		plant.Longitude = *p.Longitude
+	}
+	if p.WeatherProvider != nil {
+		plant.WeatherProvider = emptyToNil(p.WeatherProvider)
+	}
+	if plantType, ok := p.PlantType.ValueOK(); ok {
+		plant.PlantType = plantType
+	}
+	if capacity, ok := p.CapacityMw.ValueOK(); ok {
+		plant.CapacityMw = capacity
+	}
+	if date, ok := p.CommissioningDate.ValueOK(); ok {
+		plant.CommissioningDate = date
+	}
+	if p.Status != nil {
+		plant.Status = *p.Status
+	}
+	if p.Operator != nil {
+		plant.Operator = emptyToNil(p.Operator)
+	}
+	return plant
+}
+
+// ChangesDetails reports whether the patch changes a field that is checked by PowerPlant.Validate.
+func (p *PowerPlantPatch) ChangesDetails() bool {
+	return p.PlantType.IsSet() || p.CapacityMw.IsSet() || p.CommissioningDate.IsSet() || p.Status != nil
+}
+
+// emptyToNil returns nil for an empty string.
+func emptyToNil(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,16 @@
+package model
+
+import "context"
+
+// PowerPlantConnection is a page of power plants of the powerPlants field.
+type PowerPlantConnection struct {
+	Edges    []*PowerPlantEdge `json:"edges"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+	// Count counts the power plants of all pages, it is only called if the total count is selected
+	Count func(ctx context.Context) (int, error) `json:"-"`
+}
+
+// TotalCount returns the number of power plants of all pages.
+func (c *PowerPlantConnection) TotalCount(ctx context.Context) (int, error) {
+	return c.Count(ctx)
+}
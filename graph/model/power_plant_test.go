@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPowerPlantValidate(t *testing.T) {
+	today := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	solar, tidal := PlantTypeSolar, PlantTypeTidal
+	capacity := func(mw float64) *float64 { return &mw }
+	date := func(year int, month time.Month, day int) *Date {
+		d := NewDate(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+		return &d
+	}
+
+	testCases := []struct {
+		name        string
+		plant       PowerPlant
+		expectError bool
+	}{
+		{
+			name:  "Without details",
+			plant: PowerPlant{Name: "Plant"},
+		},
+		{
+			name:  "Solar plant within the limit",
+			plant: PowerPlant{PlantType: &solar, CapacityMw: capacity(2200), Status: PlantStatusOperating},
+		},
+		{
+			name:        "Tidal plant above the limit",
+			plant:       PowerPlant{PlantType: &tidal, CapacityMw: capacity(2200), Status: PlantStatusOperating},
+			expectError: true,
+		},
+		{
+			name:        "Zero capacity",
+			plant:       PowerPlant{CapacityMw: capacity(0)},
+			expectError: true,
+		},
+		{
+			name:        "Capacity above the limit of all types",
+			plant:       PowerPlant{CapacityMw: capacity(30_000)},
+			expectError: true,
+		},
+		{
+			name:  "Commissioned today",
+			plant: PowerPlant{CommissioningDate: date(2024, 1, 1), Status: PlantStatusOperating},
+		},
+		{
+			name:        "Operating plant commissioned in the future",
+			plant:       PowerPlant{CommissioningDate: date(2024, 1, 2), Status: PlantStatusOperating},
+			expectError: true,
+		},
+		{
+			name:  "Planned plant commissioned in the future",
+			plant: PowerPlant{CommissioningDate: date(2030, 1, 1), Status: PlantStatusPlanned},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.plant.Validate(today)
+			if tc.expectError {
+				assert.Error(t, err, "Failed test: "+tc.name)
+				return
+			}
+			assert.NoError(t, err, "Failed test: "+tc.name)
+		})
+	}
+}
+
+func TestPowerPlantPatchApply(t *testing.T) {
+	wind, operator, provider := PlantTypeWind, "Kaze Energy", "dwd"
+	plant := PowerPlant{ID: "1", Name: "Plant", Latitude: 10, Longitude: 20, Status: PlantStatusOperating, Operator: &operator, WeatherProvider: &provider}
+
+	decommissioned := PlantStatusDecommissioned
+	patch := &PowerPlantPatch{ID: "1", Latitude: new(float64), PlantType: graphql.OmittableOf(&wind), Status: &decommissioned, Operator: new(string), WeatherProvider: new(string)}
+	assert.True(t, patch.ChangesDetails())
+
+	updated := patch.Apply(plant)
+	assert.Equal(t, PowerPlant{ID: "1", Name: "Plant", Latitude: 0, Longitude: 20, PlantType: &wind, Status: decommissioned}, updated)
+	assert.Equal(t, "Kaze Energy", *plant.Operator, "Expected the power plant not to be modified")
+
+	assert.False(t, (&PowerPlantPatch{ID: "1", Name: &operator, Operator: &operator}).ChangesDetails())
+}
+
+func TestPowerPlantPatchApplyClearsDetails(t *testing.T) {
+	wind, capacity, commissioning := PlantTypeWind, 80.0, NewDate(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC))
+	plant := PowerPlant{ID: "1", Name: "Plant", PlantType: &wind, CapacityMw: &capacity, CommissioningDate: &commissioning, Status: PlantStatusOperating}
+
+	patch := &PowerPlantPatch{ID: "1", CapacityMw: graphql.OmittableOf[*float64](nil), CommissioningDate: graphql.OmittableOf[*Date](nil)}
+	assert.True(t, patch.ChangesDetails())
+
+	updated := patch.Apply(plant)
+	assert.Equal(t, &wind, updated.PlantType, "Expected the omitted plant type to be kept")
+	assert.Nil(t, updated.CapacityMw)
+	assert.Nil(t, updated.CommissioningDate)
+}
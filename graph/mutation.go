@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/go-playground/validator/v10"
 
 	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/geojson"
+	"github.com/glower/kaze/pkg/service"
 )
 
 // CreatePowerPlant is the resolver for the createPowerPlant field.
@@ -19,14 +22,20 @@ func (r *mutationResolver) CreatePowerPlant(ctx context.Context, input model.New
 	validate := validator.New()
 	if err := validate.Struct(input); err != nil {
 		slog.Error("Input validation failed", "error", err, "payload", input)
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
 	}
 
 	// Call the service layer to create the power plant
 	powerPlant, err := r.PowerPlantService.CreatePowerPlant(ctx, &model.PowerPlant{
-		Name:      input.Name,
-		Latitude:  input.Latitude,
-		Longitude: input.Longitude,
+		Name:              input.Name,
+		Latitude:          input.Latitude,
+		Longitude:         input.Longitude,
+		WeatherProvider:   input.WeatherProvider,
+		PlantType:         input.PlantType,
+		CapacityMw:        input.CapacityMw,
+		CommissioningDate: input.CommissioningDate,
+		Status:            toPlantStatus(input.Status),
+		Operator:          input.Operator,
 	})
 	if err != nil {
 		slog.Error("Failed to create power plant", "error", err, "payload", input)
@@ -45,27 +54,75 @@ func (r *mutationResolver) UpdatePowerPlant(ctx context.Context, id string, inpu
 	validate := validator.New()
 	if err := validate.Struct(input); err != nil {
 		slog.Error("Input validation failed", "error", err, "id", id, "payload", input)
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
 	}
 
-	// Prepare the update payload
-	updatePayload := &model.PowerPlant{ID: id}
-	if input.Name != nil {
-		updatePayload.Name = *input.Name
-	}
-	if input.Latitude != nil {
-		updatePayload.Latitude = *input.Latitude
-	}
-	if input.Longitude != nil {
-		updatePayload.Longitude = *input.Longitude
+	// Only the fields sent by the client are updated, zero values included
+	patch := &model.PowerPlantPatch{
+		ID:                id,
+		Name:              input.Name,
+		Latitude:          input.Latitude,
+		Longitude:         input.Longitude,
+		WeatherProvider:   input.WeatherProvider,
+		PlantType:         input.PlantType,
+		CapacityMw:        input.CapacityMw,
+		CommissioningDate: input.CommissioningDate,
+		Status:            input.Status,
+		Operator:          input.Operator,
 	}
 
 	// Call the service layer to update the power plant
-	updatedPowerPlant, err := r.PowerPlantService.UpdatePowerPlant(ctx, updatePayload)
+	updatedPowerPlant, err := r.PowerPlantService.UpdatePowerPlant(ctx, patch)
 	if err != nil {
-		slog.Error("Failed to update power plant", "error", err, "id", id, "payload", updatePayload)
+		slog.Error("Failed to update power plant", "error", err, "id", id, "payload", input)
 		return nil, fmt.Errorf("failed to update power plant: %w", err)
 	}
 
 	return updatedPowerPlant, nil
 }
+
+// DeletePowerPlant is the resolver for the deletePowerPlant field.
+// It soft deletes the power plant identified by the given ID.
+func (r *mutationResolver) DeletePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
+	slog.Debug("Deleting power plant", "id", id)
+
+	deletedPowerPlant, err := r.PowerPlantService.DeletePowerPlant(ctx, id)
+	if err != nil {
+		slog.Error("Failed to delete power plant", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to delete power plant: %w", err)
+	}
+
+	return deletedPowerPlant, nil
+}
+
+// RestorePowerPlant is the resolver for the restorePowerPlant field.
+// It restores the deleted power plant identified by the given ID.
+func (r *mutationResolver) RestorePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
+	slog.Debug("Restoring power plant", "id", id)
+
+	restoredPowerPlant, err := r.PowerPlantService.RestorePowerPlant(ctx, id)
+	if err != nil {
+		slog.Error("Failed to restore power plant", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to restore power plant: %w", err)
+	}
+
+	return restoredPowerPlant, nil
+}
+
+// ImportPowerPlants is the resolver for the importPowerPlants field.
+// It creates and updates power plants from the features of the collection, invalid features are reported in the result.
+// If the import stops at an error, the result of the features imported before is returned with the error.
+func (r *mutationResolver) ImportPowerPlants(ctx context.Context, featureCollection geojson.FeatureCollection) (*model.ImportResult, error) {
+	slog.Debug("Importing power plants", "features", len(featureCollection.Features))
+
+	result, err := r.PowerPlantService.ImportPowerPlants(ctx, featureCollection)
+	if err != nil {
+		slog.Error("Failed to import power plants", "error", err)
+		if result == nil {
+			return nil, fmt.Errorf("failed to import power plants: %w", err)
+		}
+		graphql.AddError(ctx, fmt.Errorf("failed to import power plants: %w", err))
+	}
+
+	return result, nil
+}
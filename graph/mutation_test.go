@@ -2,10 +2,16 @@ package graph
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
 
 	"github.com/glower/kaze/graph/model"
 	"github.com/glower/kaze/mocks"
+	"github.com/glower/kaze/pkg/geojson"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -25,6 +31,16 @@ func TestCreatePowerPlant(t *testing.T) {
 		mockService.AssertNotCalled(t, "CreatePowerPlant", mock.Anything)
 	})
 
+	t.Run("fail due to too long weather provider", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+
+		input := model.NewPowerPlantInput{Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678, WeatherProvider: stringPointer(strings.Repeat("x", 101))}
+
+		_, err := resolver.CreatePowerPlant(ctx, input)
+		assert.Error(t, err)
+		mockService.AssertNotCalled(t, "CreatePowerPlant", mock.Anything)
+	})
+
 	t.Run("fail due to service error", func(t *testing.T) {
 		resolver, mockService := setupTests(t)
 
@@ -39,15 +55,46 @@ func TestCreatePowerPlant(t *testing.T) {
 	t.Run("succeed creating power plant", func(t *testing.T) {
 		resolver, mockService := setupTests(t)
 
-		input := model.NewPowerPlantInput{Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678}
-		output := &model.PowerPlant{ID: "1", Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678}
-		mockService.On("CreatePowerPlant", ctx, mock.Anything).Return(output, nil).Once()
+		input := model.NewPowerPlantInput{Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678, WeatherProvider: stringPointer("dwd")}
+		output := &model.PowerPlant{ID: "1", Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678, WeatherProvider: stringPointer("dwd")}
+		mockService.On("CreatePowerPlant", ctx, &model.PowerPlant{Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678, WeatherProvider: stringPointer("dwd"), Status: model.PlantStatusOperating}).
+			Return(output, nil).Once()
 
 		result, err := resolver.CreatePowerPlant(ctx, input)
 		assert.NoError(t, err)
 		assert.Equal(t, output, result)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("succeed creating a planned power plant with details", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+
+		solar, planned := model.PlantTypeSolar, model.PlantStatusPlanned
+		commissioning := model.NewDate(time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC))
+		input := model.NewPowerPlantInput{
+			Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678,
+			PlantType: &solar, CapacityMw: floatPointer(120), CommissioningDate: &commissioning, Status: &planned, Operator: stringPointer("Kaze Energy"),
+		}
+		expected := &model.PowerPlant{
+			Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678,
+			PlantType: &solar, CapacityMw: floatPointer(120), CommissioningDate: &commissioning, Status: planned, Operator: stringPointer("Kaze Energy"),
+		}
+		mockService.On("CreatePowerPlant", ctx, expected).Return(expected, nil).Once()
+
+		_, err := resolver.CreatePowerPlant(ctx, input)
+		assert.NoError(t, err)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("fail due to too long operator", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+
+		input := model.NewPowerPlantInput{Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678, Operator: stringPointer(strings.Repeat("x", 256))}
+
+		_, err := resolver.CreatePowerPlant(ctx, input)
+		assert.Error(t, err)
+		mockService.AssertNotCalled(t, "CreatePowerPlant", mock.Anything)
+	})
 }
 
 func TestUpdatePowerPlant(t *testing.T) {
@@ -95,6 +142,190 @@ func TestUpdatePowerPlant(t *testing.T) {
 		assert.Equal(t, updatedPlant, result)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("fail due to empty name", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+		input := model.UpdatePowerPlantInput{
+			Name: stringPointer(""),
+		}
+
+		_, err := resolver.UpdatePowerPlant(ctx, "1", input)
+		assert.Error(t, err)
+		mockService.AssertNotCalled(t, "UpdatePowerPlant", mock.Anything, mock.Anything)
+	})
+
+	t.Run("pass zero coordinates and leave absent fields untouched", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+		input := model.UpdatePowerPlantInput{
+			Latitude:  floatPointer(0),
+			Longitude: floatPointer(0),
+		}
+		expectedPatch := &model.PowerPlantPatch{ID: "1", Latitude: floatPointer(0), Longitude: floatPointer(0)}
+		updatedPlant := &model.PowerPlant{ID: "1", Name: "Null Island Plant"}
+		mockService.On("UpdatePowerPlant", ctx, expectedPatch).Return(updatedPlant, nil).Once()
+
+		result, err := resolver.UpdatePowerPlant(ctx, "1", input)
+		assert.NoError(t, err)
+		assert.Equal(t, updatedPlant, result)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("pass the status and remove the operator", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+		maintenance := model.PlantStatusMaintenance
+		input := model.UpdatePowerPlantInput{
+			Status:   &maintenance,
+			Operator: stringPointer(""),
+		}
+		expectedPatch := &model.PowerPlantPatch{ID: "1", Status: &maintenance, Operator: stringPointer("")}
+		updatedPlant := &model.PowerPlant{ID: "1", Name: "Solar Plant", Status: maintenance}
+		mockService.On("UpdatePowerPlant", ctx, expectedPatch).Return(updatedPlant, nil).Once()
+
+		result, err := resolver.UpdatePowerPlant(ctx, "1", input)
+		assert.NoError(t, err)
+		assert.Equal(t, updatedPlant, result)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("remove the capacity with null and leave the omitted details untouched", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		isPatch := mock.MatchedBy(func(patch *model.PowerPlantPatch) bool {
+			capacity, ok := patch.CapacityMw.ValueOK()
+			return patch.ID == "1" && ok && capacity == nil && !patch.PlantType.IsSet() && !patch.CommissioningDate.IsSet()
+		})
+		mockService.On("UpdatePowerPlant", mock.Anything, isPatch).Return(&model.PowerPlant{ID: "1", Name: "Solar Plant"}, nil).Once()
+
+		var resp struct {
+			UpdatePowerPlant struct {
+				ID         string
+				CapacityMw *float64
+			}
+		}
+		c.MustPost(`mutation { updatePowerPlant(id: "1", input: {capacityMw: null}) { id capacityMw } }`, &resp)
+
+		assert.Equal(t, "1", resp.UpdatePowerPlant.ID)
+		assert.Nil(t, resp.UpdatePowerPlant.CapacityMw)
+	})
+}
+
+func TestDeletePowerPlant(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fail due to service error", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+		mockService.On("DeletePowerPlant", ctx, "1").Return(nil, assert.AnError).Once()
+
+		_, err := resolver.DeletePowerPlant(ctx, "1")
+		assert.Error(t, err)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("succeed deleting power plant", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+		deletedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		deletedPlant := &model.PowerPlant{ID: "1", Name: "Deleted Plant", DeletedAt: &deletedAt}
+		mockService.On("DeletePowerPlant", ctx, "1").Return(deletedPlant, nil).Once()
+
+		result, err := resolver.DeletePowerPlant(ctx, "1")
+		assert.NoError(t, err)
+		assert.Equal(t, deletedPlant, result)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestRestorePowerPlant(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fail due to service error", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+		mockService.On("RestorePowerPlant", ctx, "1").Return(nil, assert.AnError).Once()
+
+		_, err := resolver.RestorePowerPlant(ctx, "1")
+		assert.Error(t, err)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("succeed restoring power plant", func(t *testing.T) {
+		resolver, mockService := setupTests(t)
+		restoredPlant := &model.PowerPlant{ID: "1", Name: "Restored Plant"}
+		mockService.On("RestorePowerPlant", ctx, "1").Return(restoredPlant, nil).Once()
+
+		result, err := resolver.RestorePowerPlant(ctx, "1")
+		assert.NoError(t, err)
+		assert.Equal(t, restoredPlant, result)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestImportPowerPlants(t *testing.T) {
+	t.Run("import a feature collection with per-feature errors", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		isCollection := mock.MatchedBy(func(collection geojson.FeatureCollection) bool {
+			return len(collection.Features) == 2 && collection.Features[1].FeatureID() == "999"
+		})
+		mockService.On("ImportPowerPlants", mock.Anything, isCollection).Return(&model.ImportResult{
+			Created: 1,
+			Errors:  []*model.FeatureError{{Index: 1, ID: stringPointer("999"), Message: "not found: power plant 999"}},
+		}, nil).Once()
+
+		var resp struct {
+			ImportPowerPlants struct {
+				Created int
+				Updated int
+				Errors  []struct {
+					Index   int
+					ID      *string
+					Message string
+				}
+			}
+		}
+		c.MustPost(`mutation ($collection: GeoJSONFeatureCollection!) {
+			importPowerPlants(featureCollection: $collection) { created updated errors { index id message } }
+		}`, &resp, client.Var("collection", map[string]interface{}{
+			"type": "FeatureCollection",
+			"features": []interface{}{
+				map[string]interface{}{"type": "Feature", "geometry": map[string]interface{}{"type": "Point", "coordinates": []float64{136.9, 35.18}}, "properties": map[string]interface{}{"name": "Nagoya Wind Farm"}},
+				map[string]interface{}{"type": "Feature", "id": 999, "geometry": map[string]interface{}{"type": "Point", "coordinates": []float64{136.9, 35.18}}, "properties": map[string]interface{}{"name": "Unknown Plant"}},
+			},
+		}))
+
+		assert.Equal(t, 1, resp.ImportPowerPlants.Created)
+		assert.Len(t, resp.ImportPowerPlants.Errors, 1)
+		assert.Equal(t, "999", *resp.ImportPowerPlants.Errors[0].ID)
+		assert.Equal(t, "not found: power plant 999", resp.ImportPowerPlants.Errors[0].Message)
+	})
+
+	t.Run("return the features imported before an error", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		srv := handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}}))
+		srv.SetErrorPresenter(ErrorPresenter)
+		c := client.New(srv)
+
+		mockService.On("ImportPowerPlants", mock.Anything, mock.Anything).
+			Return(&model.ImportResult{Created: 1, Errors: []*model.FeatureError{}}, assert.AnError).Once()
+
+		var resp struct {
+			ImportPowerPlants struct {
+				Created int
+			}
+		}
+		err := c.Post(`mutation { importPowerPlants(featureCollection: {type: "FeatureCollection", features: []}) { created } }`, &resp)
+		assert.ErrorContains(t, err, "INTERNAL_SERVER_ERROR")
+		assert.Equal(t, 1, resp.ImportPowerPlants.Created)
+	})
+
+	t.Run("fail due to a feature instead of a collection", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		var resp struct{}
+		err := c.Post(`mutation { importPowerPlants(featureCollection: {type: "Feature"}) { created } }`, &resp)
+		assert.ErrorContains(t, err, "must be a FeatureCollection")
+	})
 }
 
 func stringPointer(s string) *string {
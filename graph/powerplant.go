@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/service"
+)
+
+// WeatherForecasts is the resolver for the weatherForecasts field.
+// It fetches the hourly weather forecasts for the power plant with the optional variables selected in the query.
+// Errors are reported on the field only, the other fields of the power plant are still resolved.
+func (r *powerPlantResolver) WeatherForecasts(ctx context.Context, obj *model.PowerPlant, forecastDays *int, pastDays *int, tilt *float64, azimuth *float64, units *model.UnitsInput) ([]*model.WeatherForecast, error) {
+	forecastOpts, err := toForecastOptions(forecastDays, pastDays, tilt, azimuth, units)
+	if err != nil {
+		slog.Error("Invalid weather forecast arguments", "error", err, "id", obj.ID)
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
+	}
+	forecastOpts = withSelectedVariables(ctx, forecastOpts)
+
+	forecasts, err := r.PowerPlantService.GetWeatherForecasts(ctx, obj, forecastOpts)
+	if err != nil {
+		slog.Error("Failed to retrieve weather forecasts", "error", err, "id", obj.ID)
+		return nil, fmt.Errorf("failed to retrieve weather forecasts: %w", err)
+	}
+
+	return forecasts, nil
+}
+
+// DailyWeather is the resolver for the dailyWeather field.
+// It fetches the daily weather aggregates for the power plant.
+// Errors are reported on the field only, the other fields of the power plant are still resolved.
+func (r *powerPlantResolver) DailyWeather(ctx context.Context, obj *model.PowerPlant, days *int) ([]*model.DailyWeather, error) {
+	d, err := toDays(days)
+	if err != nil {
+		slog.Error("Invalid daily weather arguments", "error", err, "id", obj.ID)
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
+	}
+
+	daily, err := r.PowerPlantService.GetDailyWeather(ctx, obj, d)
+	if err != nil {
+		slog.Error("Failed to retrieve daily weather", "error", err, "id", obj.ID)
+		return nil, fmt.Errorf("failed to retrieve daily weather: %w", err)
+	}
+
+	return daily, nil
+}
+
+// HistoricalWeather is the resolver for the historicalWeather field.
+// It fetches the hourly or daily historical weather for the power plant.
+// Errors are reported on the field only, the other fields of the power plant are still resolved.
+func (r *powerPlantResolver) HistoricalWeather(ctx context.Context, obj *model.PowerPlant, from model.Date, to model.Date, resolution *model.WeatherResolution) (*model.HistoricalWeather, error) {
+	historicalOpts, err := toHistoricalOptions(from, to, resolution)
+	if err != nil {
+		slog.Error("Invalid historical weather arguments", "error", err, "id", obj.ID)
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
+	}
+
+	historical, err := r.PowerPlantService.GetHistoricalWeather(ctx, obj, historicalOpts)
+	if err != nil {
+		slog.Error("Failed to retrieve historical weather", "error", err, "id", obj.ID)
+		return nil, fmt.Errorf("failed to retrieve historical weather: %w", err)
+	}
+
+	return historical, nil
+}
+
+// HasPrecipitationToday is the resolver for the hasPrecipitationToday field.
+// It checks for precipitation on the current or the given local day of the power plant.
+// Errors are reported on the field only, the other fields of the power plant are still resolved.
+func (r *powerPlantResolver) HasPrecipitationToday(ctx context.Context, obj *model.PowerPlant, date *model.Date) (*bool, error) {
+	day := toDate(date)
+	hasPrecipitation, err := r.PowerPlantService.HasPrecipitation(ctx, obj, day)
+	if err != nil {
+		slog.Error("Failed to check precipitation", "error", err, "id", obj.ID, "date", day)
+		return nil, fmt.Errorf("failed to check precipitation: %w", err)
+	}
+
+	return &hasPrecipitation, nil
+}
+
+// Elevation is the resolver for the elevation field.
+// It fetches the elevation of the power plant.
+// Errors are reported on the field only, the other fields of the power plant are still resolved.
+func (r *powerPlantResolver) Elevation(ctx context.Context, obj *model.PowerPlant) (*float64, error) {
+	elevation, err := r.PowerPlantService.GetElevation(ctx, obj)
+	if err != nil {
+		slog.Error("Failed to retrieve elevation", "error", err, "id", obj.ID)
+		return nil, fmt.Errorf("failed to retrieve elevation: %w", err)
+	}
+
+	return &elevation, nil
+}
@@ -0,0 +1,568 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/mocks"
+	"github.com/glower/kaze/pkg/geojson"
+	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/service"
+)
+
+func TestWeatherForecasts(t *testing.T) {
+	ctx := context.Background()
+	plant := &model.PowerPlant{ID: "1", Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678}
+
+	t.Run("fail due to invalid forecast days", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		_, err := resolver.WeatherForecasts(ctx, plant, intPtr(17), nil, nil, nil, nil)
+		assert.Error(t, err)
+		mockService.AssertNotCalled(t, "GetWeatherForecasts", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("succeed with forecast options", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		forecasts := []*model.WeatherForecast{{Time: newYear}}
+		mockService.On("GetWeatherForecasts", ctx, plant, repository.ForecastOptions{ForecastDays: 3, PastDays: 1}).Return(forecasts, nil).Once()
+
+		result, err := resolver.WeatherForecasts(ctx, plant, intPtr(3), intPtr(1), nil, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, forecasts, result)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDailyWeather(t *testing.T) {
+	ctx := context.Background()
+	plant := &model.PowerPlant{ID: "1", Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678}
+
+	t.Run("fail due to invalid days", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		_, err := resolver.DailyWeather(ctx, plant, intPtr(0))
+		assert.ErrorIs(t, err, service.ErrValidation)
+		mockService.AssertNotCalled(t, "GetDailyWeather", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("succeed with the default days", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		daily := []*model.DailyWeather{{Date: model.NewDate(newYear)}}
+		mockService.On("GetDailyWeather", ctx, plant, 7).Return(daily, nil).Once()
+
+		result, err := resolver.DailyWeather(ctx, plant, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, daily, result)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHistoricalWeather(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+	plant := &model.PowerPlant{ID: "1", Name: "Solar Plant"}
+	mockService.On("GetPowerPlant", mock.Anything, "1", false).Return(plant, nil)
+
+	t.Run("fail due to an invalid date", func(t *testing.T) {
+		var resp struct{}
+		err := c.Post(`query { powerPlant(id: "1") { historicalWeather(from: "2023-13-01", to: "2023-12-31") { daily { date } } } }`, &resp)
+		assert.ErrorContains(t, err, "YYYY-MM-DD")
+		mockService.AssertNotCalled(t, "GetHistoricalWeather", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("succeed with the daily resolution", func(t *testing.T) {
+		mockService.On("GetHistoricalWeather", mock.Anything, plant, repository.HistoricalOptions{
+			From:       time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			Resolution: repository.ResolutionDaily,
+		}).Return(&model.HistoricalWeather{Daily: []*model.DailyWeather{{Date: model.NewDate(time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC))}}}, nil).Once()
+
+		var resp struct {
+			PowerPlant struct {
+				HistoricalWeather struct {
+					Hourly []struct{ Time string }
+					Daily  []struct{ Date string }
+				}
+			}
+		}
+		c.MustPost(`query { powerPlant(id: "1") { historicalWeather(from: "2023-12-01", to: "2023-12-31", resolution: DAILY) { hourly { time } daily { date } } } }`, &resp)
+
+		assert.Nil(t, resp.PowerPlant.HistoricalWeather.Hourly)
+		assert.Equal(t, "2023-12-01", resp.PowerPlant.HistoricalWeather.Daily[0].Date)
+	})
+}
+
+func TestHasPrecipitationToday(t *testing.T) {
+	ctx := context.Background()
+	plant := &model.PowerPlant{ID: "1", Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678}
+
+	t.Run("succeed for a date", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		mockService.On("HasPrecipitation", ctx, plant, "2024-01-31").Return(false, nil).Once()
+
+		date := model.NewDate(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+		result, err := resolver.HasPrecipitationToday(ctx, plant, &date)
+		assert.NoError(t, err)
+		assert.False(t, *result)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("succeed for today", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		mockService.On("HasPrecipitation", ctx, plant, "").Return(true, nil).Once()
+
+		result, err := resolver.HasPrecipitationToday(ctx, plant, nil)
+		assert.NoError(t, err)
+		assert.True(t, *result)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestElevation(t *testing.T) {
+	ctx := context.Background()
+	plant := &model.PowerPlant{ID: "1", Name: "Solar Plant", Latitude: 1.234, Longitude: 5.678}
+
+	t.Run("fail due to service error", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		mockService.On("GetElevation", ctx, plant).Return(0.0, assert.AnError).Once()
+
+		_, err := resolver.Elevation(ctx, plant)
+		assert.Error(t, err)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("succeed", func(t *testing.T) {
+		resolver, mockService := setupPowerPlantTests(t)
+
+		mockService.On("GetElevation", ctx, plant).Return(34.0, nil).Once()
+
+		result, err := resolver.Elevation(ctx, plant)
+		assert.NoError(t, err)
+		assert.Equal(t, 34.0, *result)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDateTimeFields(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+	deletedAt := time.Date(2024, 1, 2, 9, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	plant := &model.PowerPlant{ID: "1", Name: "Solar Plant", DeletedAt: &deletedAt}
+	mockService.On("GetPowerPlant", mock.Anything, "1", true).Return(plant, nil).Once()
+	mockService.On("GetDailyWeather", mock.Anything, plant, 7).Return([]*model.DailyWeather{{
+		Date:    model.NewDate(newYear),
+		Sunrise: &deletedAt,
+	}}, nil).Once()
+
+	var resp struct {
+		PowerPlant struct {
+			DeletedAt    string
+			DailyWeather []struct {
+				Date    string
+				Sunrise string
+				Sunset  *string
+			}
+		}
+	}
+	c.MustPost(`query { powerPlant(id: "1", includeDeleted: true) { deletedAt dailyWeather { date sunrise sunset } } }`, &resp)
+
+	assert.Equal(t, "2024-01-02T00:30:00Z", resp.PowerPlant.DeletedAt, "Expected RFC 3339 in UTC")
+	assert.Equal(t, "2024-01-01", resp.PowerPlant.DailyWeather[0].Date)
+	assert.Equal(t, "2024-01-02T00:30:00Z", resp.PowerPlant.DailyWeather[0].Sunrise)
+	assert.Nil(t, resp.PowerPlant.DailyWeather[0].Sunset)
+}
+
+func TestPowerPlantFieldsWithFragmentsAndAliases(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+	plants := []*model.PowerPlant{{ID: "1", Name: "Solar Plant"}, {ID: "2", Name: "Wind Farm"}}
+	mockService.On("ListPowerPlants", mock.Anything, 1, 10, repository.PowerPlantFilter{}, repository.PowerPlantSort{}).Return(&model.PowerPlantList{PowerPlants: plants, TotalCount: 2}, nil).Once()
+	mockService.On("GetElevation", mock.Anything, mock.AnythingOfType("*model.PowerPlant")).Return(34.0, nil).Twice()
+	mockService.On("GetWeatherForecasts", mock.Anything, mock.AnythingOfType("*model.PowerPlant"), repository.ForecastOptions{ForecastDays: 2}).
+		Return([]*model.WeatherForecast{{Time: newYear}}, nil).Twice()
+
+	var resp struct {
+		ListPowerPlants struct {
+			PowerPlants []struct {
+				ID     string
+				Height float64
+				Short  []struct{ Time string }
+			}
+		}
+	}
+	c.MustPost(`
+		query { listPowerPlants { powerPlants { id ...Weather } } }
+		fragment Weather on PowerPlant { height: elevation short: weatherForecasts(forecastDays: 2) { time } }
+	`, &resp)
+
+	assert.Len(t, resp.ListPowerPlants.PowerPlants, 2)
+	assert.Equal(t, 34.0, resp.ListPowerPlants.PowerPlants[1].Height)
+	assert.Equal(t, "2024-01-01T00:00:00Z", resp.ListPowerPlants.PowerPlants[1].Short[0].Time)
+	mockService.AssertNotCalled(t, "HasPrecipitation", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListPowerPlantsWithFilterAndSort(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := repository.PowerPlantFilter{
+		IncludeDeleted: true,
+		NameContains:   "farm",
+		PlantTypes:     []model.PlantType{model.PlantTypeWind, model.PlantTypeSolar},
+		Statuses:       []model.PlantStatus{model.PlantStatusOperating},
+		MinCapacityMw:  floatPointer(100),
+		BoundingBox:    &repository.BoundingBox{MinLatitude: 30, MinLongitude: 130, MaxLatitude: 45, MaxLongitude: 145},
+		CreatedAfter:   &createdAfter,
+	}
+	sort := repository.PowerPlantSort{Field: repository.SortByDistance, Descending: true, From: &repository.Coordinates{Latitude: 35.68, Longitude: 139.69}}
+	wind := model.PlantTypeWind
+	mockService.On("ListPowerPlants", mock.Anything, 1, 10, filter, sort).
+		Return(&model.PowerPlantList{PowerPlants: []*model.PowerPlant{{ID: "2", Name: "Noshiro Wind Farm", PlantType: &wind, Status: model.PlantStatusOperating, CreatedAt: newYear}}, TotalCount: 1}, nil).Once()
+
+	var resp struct {
+		ListPowerPlants struct {
+			PowerPlants []struct {
+				ID        string
+				PlantType string
+				Status    string
+				CreatedAt string
+			}
+			TotalCount int
+		}
+	}
+	c.MustPost(`query {
+		listPowerPlants(
+			includeDeleted: true
+			filter: {
+				nameContains: "farm", plantTypes: [WIND, SOLAR], statuses: [OPERATING], minCapacityMw: 100
+				boundingBox: {minLatitude: 30, minLongitude: 130, maxLatitude: 45, maxLongitude: 145}
+				createdAfter: "2024-01-01T09:00:00+09:00"
+			}
+			sort: {field: DISTANCE, direction: DESC, from: {latitude: 35.68, longitude: 139.69}}
+		) { powerPlants { id plantType status createdAt } totalCount }
+	}`, &resp)
+
+	assert.Equal(t, 1, resp.ListPowerPlants.TotalCount)
+	assert.Equal(t, "WIND", resp.ListPowerPlants.PowerPlants[0].PlantType)
+	assert.Equal(t, "OPERATING", resp.ListPowerPlants.PowerPlants[0].Status)
+	assert.Equal(t, "2024-01-01T00:00:00Z", resp.ListPowerPlants.PowerPlants[0].CreatedAt)
+}
+
+func TestPowerPlantsConnection(t *testing.T) {
+	t.Run("page without total count", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		endCursor := "end"
+		mockService.On("PagePowerPlants", mock.Anything, repository.PowerPlantFilter{}, repository.PowerPlantSort{}, repository.Page{Limit: 2}).
+			Return(&model.PowerPlantConnection{
+				Edges:    []*model.PowerPlantEdge{{Cursor: "start", Node: &model.PowerPlant{ID: "1"}}, {Cursor: endCursor, Node: &model.PowerPlant{ID: "2"}}},
+				PageInfo: &model.PageInfo{HasNextPage: true, EndCursor: &endCursor},
+				Count: func(context.Context) (int, error) {
+					t.Error("Expected the total count not to be counted")
+					return 0, nil
+				},
+			}, nil).Once()
+
+		var resp struct {
+			PowerPlants struct {
+				Edges []struct {
+					Cursor string
+					Node   struct{ ID string }
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+		}
+		c.MustPost(`query { powerPlants(first: 2) { edges { cursor node { id } } pageInfo { hasNextPage endCursor } } }`, &resp)
+
+		assert.Len(t, resp.PowerPlants.Edges, 2)
+		assert.Equal(t, "2", resp.PowerPlants.Edges[1].Node.ID)
+		assert.True(t, resp.PowerPlants.PageInfo.HasNextPage)
+		assert.Equal(t, "end", resp.PowerPlants.PageInfo.EndCursor)
+	})
+
+	t.Run("total count of the filter", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		filter := repository.PowerPlantFilter{PlantTypes: []model.PlantType{model.PlantTypeHydro}}
+		mockService.On("PagePowerPlants", mock.Anything, filter, repository.PowerPlantSort{}, repository.Page{Limit: 3, Backward: true}).
+			Return(&model.PowerPlantConnection{
+				Edges:    []*model.PowerPlantEdge{},
+				PageInfo: &model.PageInfo{},
+				Count:    func(context.Context) (int, error) { return 4, nil },
+			}, nil).Once()
+
+		var resp struct {
+			PowerPlants struct{ TotalCount int }
+		}
+		c.MustPost(`query { powerPlants(last: 3, filter: {plantTypes: [HYDRO]}) { totalCount } }`, &resp)
+
+		assert.Equal(t, 4, resp.PowerPlants.TotalCount)
+	})
+
+	t.Run("fail due to first and last", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		srv := handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}}))
+		srv.SetErrorPresenter(ErrorPresenter)
+		c := client.New(srv)
+
+		var resp struct{}
+		err := c.Post(`query { powerPlants(first: 2, last: 2) { totalCount } }`, &resp)
+		assert.ErrorContains(t, err, "first and last")
+		assert.ErrorContains(t, err, CodeValidation)
+	})
+}
+
+func TestPlantsNear(t *testing.T) {
+	t.Run("nearest power plants with their distances", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		opts := repository.NearOptions{Center: repository.Coordinates{Latitude: 35.68, Longitude: 139.69}, RadiusKm: 50, Limit: 10}
+		mockService.On("PlantsNear", mock.Anything, opts).Return([]*model.PowerPlantDistance{
+			{PowerPlant: &model.PowerPlant{ID: "19", Name: "Saitama Wind Turbine Array"}, DistanceKm: 22.5},
+		}, nil).Once()
+
+		var resp struct {
+			PlantsNear []struct {
+				PowerPlant struct{ Name string }
+				DistanceKm float64
+			}
+		}
+		c.MustPost(`query { plantsNear(latitude: 35.68, longitude: 139.69, radiusKm: 50) { powerPlant { name } distanceKm } }`, &resp)
+
+		assert.Len(t, resp.PlantsNear, 1)
+		assert.Equal(t, "Saitama Wind Turbine Array", resp.PlantsNear[0].PowerPlant.Name)
+		assert.Equal(t, 22.5, resp.PlantsNear[0].DistanceKm)
+	})
+
+	t.Run("fail due to a too large radius", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		srv := handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}}))
+		srv.SetErrorPresenter(ErrorPresenter)
+		c := client.New(srv)
+
+		opts := repository.NearOptions{Center: repository.Coordinates{Latitude: 35.68, Longitude: 139.69}, RadiusKm: 5000, Limit: 3}
+		mockService.On("PlantsNear", mock.Anything, opts).Return(nil, fmt.Errorf("%w: radius too large", service.ErrValidation)).Once()
+
+		var resp struct{}
+		err := c.Post(`query { plantsNear(latitude: 35.68, longitude: 139.69, radiusKm: 5000, limit: 3) { distanceKm } }`, &resp)
+		assert.ErrorContains(t, err, CodeValidation)
+	})
+}
+
+func TestPlantsWithin(t *testing.T) {
+	isTokyoBay := mock.MatchedBy(func(g geojson.Geometry) bool {
+		polygons, err := g.Polygons()
+		return err == nil && g.Type == geojson.TypePolygon && len(polygons) == 1 && len(polygons[0][0]) == 4
+	})
+
+	t.Run("polygon as an object", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		mockService.On("PlantsWithin", mock.Anything, isTokyoBay, repository.DefaultWithinLimit).Return([]*model.PowerPlant{{ID: "5"}, {ID: "19"}}, nil).Once()
+
+		var resp struct {
+			PlantsWithin []struct{ ID string }
+		}
+		c.MustPost(`query {
+			plantsWithin(polygon: {type: "Polygon", coordinates: [[[139.5, 35.2], [140.2, 35.2], [139.8, 35.8], [139.5, 35.2]]]}) { id }
+		}`, &resp)
+
+		assert.Len(t, resp.PlantsWithin, 2)
+		assert.Equal(t, "19", resp.PlantsWithin[1].ID)
+	})
+
+	t.Run("polygon as a JSON string variable", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		mockService.On("PlantsWithin", mock.Anything, isTokyoBay, 5).Return([]*model.PowerPlant{}, nil).Once()
+
+		var resp struct {
+			PlantsWithin []struct{ ID string }
+		}
+		c.MustPost(`query ($polygon: GeoJSON!) { plantsWithin(polygon: $polygon, limit: 5) { id } }`, &resp,
+			client.Var("polygon", `{"type": "Polygon", "coordinates": [[[139.5, 35.2], [140.2, 35.2], [139.8, 35.8], [139.5, 35.2]]]}`))
+
+		assert.Empty(t, resp.PlantsWithin)
+	})
+
+	t.Run("fail due to a geometry without a type", func(t *testing.T) {
+		mockService := mocks.NewPowerPlantService(t)
+		c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+		var resp struct{}
+		err := c.Post(`query { plantsWithin(polygon: {coordinates: []}) { id } }`, &resp)
+		assert.ErrorContains(t, err, "must have a type")
+	})
+}
+
+func TestWeatherForecastsWithSelectedVariables(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+	plant := &model.PowerPlant{ID: "1", Name: "Solar Plant"}
+	gusts := 42.0
+	mockService.On("GetPowerPlant", mock.Anything, "1", false).Return(plant, nil).Once()
+	mockService.On("GetWeatherForecasts", mock.Anything, plant, repository.ForecastOptions{
+		ForecastDays: 7,
+		Variables:    repository.NewVariables(repository.WindGusts10m, repository.GlobalTiltedIrradiance),
+		Tilt:         35,
+	}).Return([]*model.WeatherForecast{{Time: newYear, WindGusts10m: &gusts}}, nil).Once()
+	mockService.On("GetWeatherForecasts", mock.Anything, plant, repository.ForecastOptions{ForecastDays: 7}).
+		Return([]*model.WeatherForecast{{Time: newYear}}, nil).Once()
+
+	var resp struct {
+		PowerPlant struct {
+			Solar []struct {
+				Time                   string
+				WindGusts10m           *float64
+				GlobalTiltedIrradiance *float64
+			}
+			Base []struct{ Time string }
+		}
+	}
+	c.MustPost(`
+		query { powerPlant(id: "1") { solar: weatherForecasts(tilt: 35) { time ...Solar } base: weatherForecasts(tilt: 35) { time } } }
+		fragment Solar on WeatherForecast { windGusts10m globalTiltedIrradiance }
+	`, &resp)
+
+	assert.Equal(t, 42.0, *resp.PowerPlant.Solar[0].WindGusts10m)
+	assert.Nil(t, resp.PowerPlant.Solar[0].GlobalTiltedIrradiance)
+	assert.Equal(t, "2024-01-01T00:00:00Z", resp.PowerPlant.Base[0].Time)
+}
+
+func TestWeatherForecastsWithUnits(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+	plant := &model.PowerPlant{ID: "1", Name: "Offshore Wind Farm"}
+	units := &model.WeatherUnits{Temperature: model.TemperatureUnitCelsius, WindSpeed: model.WindSpeedUnitKn, Precipitation: model.PrecipitationUnitMillimeter}
+	mockService.On("GetPowerPlant", mock.Anything, "1", false).Return(plant, nil).Once()
+	mockService.On("GetWeatherForecasts", mock.Anything, plant, repository.ForecastOptions{
+		ForecastDays: 7,
+		Units:        repository.Units{Temperature: repository.Celsius, WindSpeed: repository.Knots, Precipitation: repository.Millimeters},
+	}).Return([]*model.WeatherForecast{{Time: newYear, WindSpeed: 21.6, Units: units}}, nil).Once()
+
+	var resp struct {
+		PowerPlant struct {
+			WeatherForecasts []struct {
+				WindSpeed float64
+				Units     struct{ WindSpeed string }
+			}
+		}
+	}
+	c.MustPost(`query { powerPlant(id: "1") { weatherForecasts(units: {windSpeed: KN}) { windSpeed units { windSpeed } } } }`, &resp)
+
+	assert.Equal(t, 21.6, resp.PowerPlant.WeatherForecasts[0].WindSpeed)
+	assert.Equal(t, "KN", resp.PowerPlant.WeatherForecasts[0].Units.WindSpeed)
+}
+
+func TestListPowerPlantsWithPartialFailure(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	srv := handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}}))
+	srv.SetErrorPresenter(ErrorPresenter)
+	c := client.New(srv)
+
+	solar, wind := &model.PowerPlant{ID: "1", Name: "Solar Plant"}, &model.PowerPlant{ID: "2", Name: "Wind Farm"}
+	mockService.On("ListPowerPlants", mock.Anything, 1, 10, repository.PowerPlantFilter{}, repository.PowerPlantSort{}).
+		Return(&model.PowerPlantList{PowerPlants: []*model.PowerPlant{solar, wind}, TotalCount: 2}, nil).Once()
+	mockService.On("GetElevation", mock.Anything, solar).Return(34.0, nil).Once()
+	mockService.On("GetElevation", mock.Anything, wind).Return(0.0, fmt.Errorf("%w: timeout", service.ErrUpstreamUnavailable)).Once()
+	mockService.On("HasPrecipitation", mock.Anything, solar, "").Return(true, nil).Once()
+	mockService.On("HasPrecipitation", mock.Anything, wind, "").Return(false, fmt.Errorf("%w: timeout", service.ErrUpstreamUnavailable)).Once()
+
+	var resp struct {
+		ListPowerPlants struct {
+			PowerPlants []struct {
+				ID                    string
+				Name                  string
+				Elevation             *float64
+				HasPrecipitationToday *bool
+			}
+			TotalCount int
+		}
+	}
+	err := c.Post(`query { listPowerPlants { powerPlants { id name elevation hasPrecipitationToday } totalCount } }`, &resp)
+
+	var rawErr client.RawJsonError
+	assert.ErrorAs(t, err, &rawErr)
+	var errs gqlerror.List
+	assert.NoError(t, json.Unmarshal(rawErr.RawMessage, &errs))
+	assert.Len(t, errs, 2)
+	for _, gqlErr := range errs {
+		assert.Contains(t, []string{"listPowerPlants.powerPlants[1].elevation", "listPowerPlants.powerPlants[1].hasPrecipitationToday"}, gqlErr.Path.String())
+		assert.Equal(t, CodeUpstreamUnavailable, gqlErr.Extensions["code"])
+	}
+
+	plants := resp.ListPowerPlants.PowerPlants
+	assert.Equal(t, 2, resp.ListPowerPlants.TotalCount)
+	assert.Len(t, plants, 2)
+	assert.Equal(t, 34.0, *plants[0].Elevation)
+	assert.True(t, *plants[0].HasPrecipitationToday)
+	assert.Equal(t, "Wind Farm", plants[1].Name)
+	assert.Nil(t, plants[1].Elevation)
+	assert.Nil(t, plants[1].HasPrecipitationToday)
+}
+
+// newYear is the time of the forecasts returned by the mocked service.
+var newYear = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func setupPowerPlantTests(t *testing.T) (PowerPlantResolver, *mocks.PowerPlantService) {
+	mockService := mocks.NewPowerPlantService(t)
+	resolver := &Resolver{PowerPlantService: mockService}
+
+	return resolver.PowerPlant(), mockService
+}
+
+func TestWeatherProviders(t *testing.T) {
+	mockService := mocks.NewPowerPlantService(t)
+	c := client.New(handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: &Resolver{PowerPlantService: mockService}})))
+
+	mockService.On("WeatherProviders", mock.Anything).Return([]*model.WeatherProviderInfo{
+		{Name: "metno", ForecastHours: 60},
+		{Name: "openmeteo", Default: true, ForecastHours: 384, PastDays: 92},
+	}).Once()
+
+	var resp struct {
+		WeatherProviders []struct {
+			Name          string
+			Default       bool
+			ForecastHours int
+			PastDays      int
+		}
+	}
+	c.MustPost(`query { weatherProviders { name default forecastHours pastDays } }`, &resp)
+
+	assert.Len(t, resp.WeatherProviders, 2)
+	assert.Equal(t, "metno", resp.WeatherProviders[0].Name)
+	assert.Equal(t, 60, resp.WeatherProviders[0].ForecastHours)
+	assert.Equal(t, 0, resp.WeatherProviders[0].PastDays)
+	assert.True(t, resp.WeatherProviders[1].Default)
+}
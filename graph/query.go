@@ -6,17 +6,19 @@ import (
 	"log/slog"
 
 	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/geojson"
+	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/service"
 )
 
 // PowerPlant is the resolver for the powerPlant field.
-// It retrieves a power plant by its ID, including elevation and weather forecasts if requested.
-func (r *queryResolver) PowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
-	slog.Debug("Retrieving power plant", "id", id)
+// It retrieves a power plant by its ID, elevation and weather data are resolved by the PowerPlant field resolvers.
+// Deleted power plants are only returned if includeDeleted is set.
+func (r *queryResolver) PowerPlant(ctx context.Context, id string, includeDeleted *bool) (*model.PowerPlant, error) {
+	deleted := toBoolWithDefault(includeDeleted, false)
+	slog.Debug("Retrieving power plant", "id", id, "includeDeleted", deleted)
 
-	withElevation := IsFieldRequested(ctx, "elevation")
-	withWeatherForecasts := IsFieldRequested(ctx, "weatherForecasts")
-
-	powerPlant, err := r.PowerPlantService.GetPowerPlant(ctx, id, withElevation, withWeatherForecasts)
+	powerPlant, err := r.PowerPlantService.GetPowerPlant(ctx, id, deleted)
 	if err != nil {
 		slog.Error("Failed to retrieve power plant", "error", err, "id", id)
 		return nil, fmt.Errorf("error retrieving power plant by ID: %w", err)
@@ -26,12 +28,52 @@ func (r *queryResolver) PowerPlant(ctx context.Context, id string) (*model.Power
 }
 
 // ListPowerPlants is the resolver for the listPowerPlants field.
-// It retrieves a list of power plants, supporting pagination and optional inclusion of elevation and weather forecasts.
-func (r *queryResolver) ListPowerPlants(ctx context.Context, page *int, pageSize *int) (*model.PowerPlantList, error) {
-	slog.Debug("Retrieving a list of power plants", "page", *page, "pageSize", *pageSize)
+// It retrieves a list of power plants, supporting pagination, filtering and sorting. Deleted power plants are only listed if includeDeleted is set.
+func (r *queryResolver) ListPowerPlants(ctx context.Context, page *int, pageSize *int, includeDeleted *bool, filter *model.PowerPlantFilter, sort *model.PowerPlantSort) (*model.PowerPlantList, error) {
+	p, ps := toIntWithDefault(page, 1), toIntWithDefault(pageSize, 10)
+	deleted := toBoolWithDefault(includeDeleted, false)
+	slog.Debug("Retrieving a list of power plants", "page", p, "pageSize", ps, "includeDeleted", deleted)
+
+	return r.PowerPlantService.ListPowerPlants(ctx, p, ps, repository.NewPowerPlantFilter(filter, deleted), toPowerPlantSort(sort))
+}
+
+// PowerPlants is the resolver for the powerPlants field.
+// It retrieves a page of power plants with cursor pagination, first and after page forward, last and before backward.
+func (r *queryResolver) PowerPlants(ctx context.Context, first *int, after *string, last *int, before *string, includeDeleted *bool, filter *model.PowerPlantFilter, sort *model.PowerPlantSort) (*model.PowerPlantConnection, error) {
+	page, err := toPage(first, after, last, before)
+	if err != nil {
+		slog.Error("Invalid pagination arguments", "error", err)
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
+	}
+	deleted := toBoolWithDefault(includeDeleted, false)
+	slog.Debug("Retrieving a page of power plants", "page", page, "includeDeleted", deleted)
+
+	return r.PowerPlantService.PagePowerPlants(ctx, repository.NewPowerPlantFilter(filter, deleted), toPowerPlantSort(sort), page)
+}
+
+// PlantsNear is the resolver for the plantsNear field.
+// It retrieves the power plants within the radius around the point with their distances, the nearest first.
+func (r *queryResolver) PlantsNear(ctx context.Context, latitude float64, longitude float64, radiusKm float64, limit *int) ([]*model.PowerPlantDistance, error) {
+	opts := repository.NearOptions{
+		Center:   repository.Coordinates{Latitude: latitude, Longitude: longitude},
+		RadiusKm: radiusKm,
+		Limit:    toIntWithDefault(limit, repository.DefaultNearLimit),
+	}
+	slog.Debug("Retrieving power plants near a point", "opts", opts)
+
+	return r.PowerPlantService.PlantsNear(ctx, opts)
+}
+
+// WeatherProviders is the resolver for the weatherProviders field.
+// It lists the weather providers of the deployment with the range of their hourly forecasts.
+func (r *queryResolver) WeatherProviders(ctx context.Context) ([]*model.WeatherProviderInfo, error) {
+	return r.PowerPlantService.WeatherProviders(ctx), nil
+}
 
-	withElevation := IsFieldRequested(ctx, "powerPlants.elevation")
-	withWeatherForecasts := IsFieldRequested(ctx, "powerPlants.weatherForecasts")
+// PlantsWithin is the resolver for the plantsWithin field.
+// It retrieves the power plants inside the Polygon or MultiPolygon geometry, ordered by ID.
+func (r *queryResolver) PlantsWithin(ctx context.Context, polygon geojson.Geometry, limit *int) ([]*model.PowerPlant, error) {
+	slog.Debug("Retrieving power plants within an area", "type", polygon.Type, "limit", limit)
 
-	return r.PowerPlantService.ListPowerPlants(ctx, toIntWithDefault(page, 1), toIntWithDefault(pageSize, 10), withElevation, withWeatherForecasts)
+	return r.PowerPlantService.PlantsWithin(ctx, polygon, toIntWithDefault(limit, repository.DefaultWithinLimit))
 }
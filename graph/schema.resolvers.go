@@ -17,8 +17,12 @@ package graph
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// PowerPlant returns PowerPlantResolver implementation.
+func (r *Resolver) PowerPlant() PowerPlantResolver { return &powerPlantResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 type mutationResolver struct{ *Resolver }
+type powerPlantResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
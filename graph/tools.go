@@ -2,8 +2,13 @@ package graph
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/service"
 )
 
 func toIntWithDefault(i *int, defaultVaue int) int {
@@ -14,36 +19,228 @@ func toIntWithDefault(i *int, defaultVaue int) int {
 	return *i
 }
 
-func IsFieldRequested(ctx context.Context, field string) bool {
-	for _, f := range getPreloads(ctx) {
-		if f == field {
-			return true
+func toBoolWithDefault(b *bool, defaultValue bool) bool {
+	if b == nil {
+		return defaultValue
+	}
+
+	return *b
+}
+
+// toDate formats the date argument of the hasPrecipitationToday field, it returns an empty string if not set.
+func toDate(date *model.Date) string {
+	if date == nil {
+		return ""
+	}
+
+	return date.Format(service.DateLayout)
+}
+
+func toFloatWithDefault(f *float64, defaultValue float64) float64 {
+	if f == nil {
+		return defaultValue
+	}
+
+	return *f
+}
+
+// toForecastOptions converts the arguments of the weatherForecasts field into validated forecast options.
+func toForecastOptions(forecastDays, pastDays *int, tilt, azimuth *float64, units *model.UnitsInput) (repository.ForecastOptions, error) {
+	opts := repository.ForecastOptions{
+		ForecastDays: toIntWithDefault(forecastDays, repository.DefaultForecastDays),
+		PastDays:     toIntWithDefault(pastDays, 0),
+		Tilt:         toFloatWithDefault(tilt, 0),
+		Azimuth:      toFloatWithDefault(azimuth, 0),
+		Units:        toUnits(units),
+	}
+
+	return opts, opts.Validate()
+}
+
+// unitSystems are the units of the unit systems.
+var unitSystems = map[model.UnitSystem]repository.Units{
+	model.UnitSystemMetric:   {Temperature: repository.Celsius, WindSpeed: repository.KilometersPerHour, Precipitation: repository.Millimeters},
+	model.UnitSystemImperial: {Temperature: repository.Fahrenheit, WindSpeed: repository.MilesPerHour, Precipitation: repository.Inches},
+}
+
+var (
+	temperatureUnits = map[model.TemperatureUnit]repository.TemperatureUnit{
+		model.TemperatureUnitCelsius:    repository.Celsius,
+		model.TemperatureUnitFahrenheit: repository.Fahrenheit,
+	}
+	windSpeedUnits = map[model.WindSpeedUnit]repository.WindSpeedUnit{
+		model.WindSpeedUnitKmh: repository.KilometersPerHour,
+		model.WindSpeedUnitMs:  repository.MetersPerSecond,
+		model.WindSpeedUnitKn:  repository.Knots,
+		model.WindSpeedUnitMph: repository.MilesPerHour,
+	}
+	precipitationUnits = map[model.PrecipitationUnit]repository.PrecipitationUnit{
+		model.PrecipitationUnitMillimeter: repository.Millimeters,
+		model.PrecipitationUnitInch:       repository.Inches,
+	}
+)
+
+// toUnits converts the units argument into the units of the unit system, overridden by the single units
+// that are set. Nil is the metric system, the enums are validated by gqlgen.
+func toUnits(input *model.UnitsInput) repository.Units {
+	if input == nil {
+		return repository.Units{}
+	}
+
+	system := model.UnitSystemMetric
+	if input.System != nil {
+		system = *input.System
+	}
+	units := unitSystems[system]
+	if input.Temperature != nil {
+		units.Temperature = temperatureUnits[*input.Temperature]
+	}
+	if input.WindSpeed != nil {
+		units.WindSpeed = windSpeedUnits[*input.WindSpeed]
+	}
+	if input.Precipitation != nil {
+		units.Precipitation = precipitationUnits[*input.Precipitation]
+	}
+	return units
+}
+
+// toPlantStatus returns the status of a new power plant, operating if it is not set.
+func toPlantStatus(status *model.PlantStatus) model.PlantStatus {
+	if status == nil {
+		return model.PlantStatusOperating
+	}
+	return *status
+}
+
+// sortFields maps the sort fields of the schema to the sort fields of the repository.
+var sortFields = map[model.PowerPlantSortField]repository.SortField{
+	model.PowerPlantSortFieldID:        repository.SortByID,
+	model.PowerPlantSortFieldName:      repository.SortByName,
+	model.PowerPlantSortFieldCapacity:  repository.SortByCapacity,
+	model.PowerPlantSortFieldCreatedAt: repository.SortByCreatedAt,
+	model.PowerPlantSortFieldDistance:  repository.SortByDistance,
+}
+
+// toPowerPlantSort converts the sort argument of the listPowerPlants field into a repository sort,
+// nil sorts by ID. The sort is validated by the service.
+func toPowerPlantSort(sort *model.PowerPlantSort) repository.PowerPlantSort {
+	if sort == nil {
+		return repository.PowerPlantSort{}
+	}
+
+	s := repository.PowerPlantSort{
+		Field:      sortFields[sort.Field],
+		Descending: sort.Direction != nil && *sort.Direction == model.SortDirectionDesc,
+	}
+	if sort.From != nil {
+		s.From = &repository.Coordinates{Latitude: sort.From.Latitude, Longitude: sort.From.Longitude}
+	}
+	return s
+}
+
+// toPage converts the pagination arguments of the powerPlants field into a page, first and after page forward,
+// last and before backward. The limit is validated by the service.
+func toPage(first *int, after *string, last *int, before *string) (repository.Page, error) {
+	if first != nil && last != nil {
+		return repository.Page{}, fmt.Errorf("first and last can't be used together")
+	}
+	if after != nil && before != nil {
+		return repository.Page{}, fmt.Errorf("after and before can't be used together")
+	}
+	if (first != nil && before != nil) || (last != nil && after != nil) {
+		return repository.Page{}, fmt.Errorf("first can only be used with after and last with before")
+	}
+
+	page := repository.Page{Limit: repository.DefaultPageLimit}
+	cursor := after
+	if last != nil || before != nil {
+		page.Backward = true
+		cursor = before
+	}
+	if first != nil {
+		page.Limit = *first
+	}
+	if last != nil {
+		page.Limit = *last
+	}
+
+	if cursor != nil {
+		c, err := repository.ParseCursor(*cursor)
+		if err != nil {
+			return repository.Page{}, err
 		}
+		page.Cursor = c
 	}
-	return false
+	return page, nil
 }
 
-// Note: got this from here: https://github.com/99designs/gqlgen/blob/7dd971c871c0b0159ad26c9bf3095a8ba3780402/docs/content/reference/field-collection.md
-func getPreloads(ctx context.Context) []string {
-	return getNestedPreloads(
-		graphql.GetOperationContext(ctx),
-		graphql.CollectFieldsCtx(ctx, nil),
-		"",
-	)
+// toDays validates the days argument of the dailyWeather field.
+func toDays(days *int) (int, error) {
+	d := toIntWithDefault(days, repository.DefaultForecastDays)
+	if d < 1 || d > repository.MaxForecastDays {
+		return 0, fmt.Errorf("days must be between 1 and %d, got %d", repository.MaxForecastDays, d)
+	}
+
+	return d, nil
 }
 
-func getNestedPreloads(ctx *graphql.OperationContext, fields []graphql.CollectedField, prefix string) (preloads []string) {
-	for _, column := range fields {
-		prefixColumn := getPreloadString(prefix, column.Name)
-		preloads = append(preloads, prefixColumn)
-		preloads = append(preloads, getNestedPreloads(ctx, graphql.CollectFields(ctx, column.Selections, nil), prefixColumn)...)
+// toHistoricalOptions converts the arguments of the historicalWeather field into historical options,
+// the range is validated by the service.
+func toHistoricalOptions(from, to model.Date, resolution *model.WeatherResolution) (repository.HistoricalOptions, error) {
+	opts := repository.HistoricalOptions{From: from.Time, To: to.Time, Resolution: repository.ResolutionHourly}
+	if resolution == nil {
+		return opts, nil
 	}
-	return
+
+	switch *resolution {
+	case model.WeatherResolutionHourly:
+		opts.Resolution = repository.ResolutionHourly
+	case model.WeatherResolutionDaily:
+		opts.Resolution = repository.ResolutionDaily
+	default:
+		return opts, fmt.Errorf("unknown resolution %q", *resolution)
+	}
+	return opts, nil
+}
+
+// forecastVariables maps the fields of the WeatherForecast type to the optional hourly variables.
+var forecastVariables = map[string]repository.Variable{
+	"windSpeed80m":           repository.WindSpeed80m,
+	"windSpeed120m":          repository.WindSpeed120m,
+	"windSpeed180m":          repository.WindSpeed180m,
+	"windDirection80m":       repository.WindDirection80m,
+	"windDirection120m":      repository.WindDirection120m,
+	"windDirection180m":      repository.WindDirection180m,
+	"windGusts10m":           repository.WindGusts10m,
+	"cloudCoverLow":          repository.CloudCoverLow,
+	"cloudCoverMid":          repository.CloudCoverMid,
+	"cloudCoverHigh":         repository.CloudCoverHigh,
+	"shortwaveRadiation":     repository.ShortwaveRadiation,
+	"directRadiation":        repository.DirectRadiation,
+	"diffuseRadiation":       repository.DiffuseRadiation,
+	"globalTiltedIrradiance": repository.GlobalTiltedIrradiance,
+	"surfacePressure":        repository.SurfacePressure,
+	"relativeHumidity2m":     repository.RelativeHumidity2m,
+	"snowfall":               repository.Snowfall,
+	"snowDepth":              repository.SnowDepth,
 }
 
-func getPreloadString(prefix, name string) string {
-	if len(prefix) > 0 {
-		return prefix + "." + name
+// withSelectedVariables sets the optional variables of the forecast options to the fields selected in the
+// GraphQL query, including fragments, so only the selected variables are requested from the weather provider.
+// The panel orientation is reset if the global tilted irradiance is not selected, it would split the requests.
+func withSelectedVariables(ctx context.Context, opts repository.ForecastOptions) repository.ForecastOptions {
+	if graphql.HasOperationContext(ctx) && graphql.GetFieldContext(ctx) != nil {
+		var variables []repository.Variable
+		for _, field := range graphql.CollectFieldsCtx(ctx, nil) {
+			if v, ok := forecastVariables[field.Name]; ok {
+				variables = append(variables, v)
+			}
+		}
+		opts.Variables = repository.NewVariables(variables...)
+	}
+
+	if !opts.Variables.Has(repository.GlobalTiltedIrradiance) {
+		opts.Tilt, opts.Azimuth = 0, 0
 	}
-	return name
+	return opts
 }
@@ -2,8 +2,12 @@ package graph
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/repository"
 )
 
 func TestToIntWithDefault(t *testing.T) {
@@ -35,7 +39,219 @@ func TestToIntWithDefault(t *testing.T) {
 	}
 }
 
+func TestToForecastOptions(t *testing.T) {
+	testCases := []struct {
+		name         string
+		forecastDays *int
+		pastDays     *int
+		tilt         *float64
+		azimuth      *float64
+		units        *model.UnitsInput
+		expected     repository.ForecastOptions
+		expectError  bool
+	}{
+		{
+			name:     "Defaults",
+			expected: repository.ForecastOptions{ForecastDays: 7},
+		},
+		{
+			name:         "Forecast and past days",
+			forecastDays: intPtr(3),
+			pastDays:     intPtr(2),
+			expected:     repository.ForecastOptions{ForecastDays: 3, PastDays: 2},
+		},
+		{
+			name:         "Maximum forecast days",
+			forecastDays: intPtr(16),
+			expected:     repository.ForecastOptions{ForecastDays: 16},
+		},
+		{
+			name:         "Too many forecast days",
+			forecastDays: intPtr(17),
+			expectError:  true,
+		},
+		{
+			name:         "Zero forecast days",
+			forecastDays: intPtr(0),
+			expectError:  true,
+		},
+		{
+			name:        "Negative past days",
+			pastDays:    intPtr(-1),
+			expectError: true,
+		},
+		{
+			name:     "Panel orientation",
+			tilt:     floatPointer(35),
+			azimuth:  floatPointer(-90),
+			expected: repository.ForecastOptions{ForecastDays: 7, Tilt: 35, Azimuth: -90},
+		},
+		{
+			name:        "Tilt above vertical",
+			tilt:        floatPointer(91),
+			expectError: true,
+		},
+		{
+			name:        "Azimuth out of range",
+			azimuth:     floatPointer(-181),
+			expectError: true,
+		},
+		{
+			name:  "Imperial units with wind in knots",
+			units: &model.UnitsInput{System: unitSystemPtr(model.UnitSystemImperial), WindSpeed: windSpeedUnitPtr(model.WindSpeedUnitKn)},
+			expected: repository.ForecastOptions{ForecastDays: 7, Units: repository.Units{
+				Temperature: repository.Fahrenheit, WindSpeed: repository.Knots, Precipitation: repository.Inches,
+			}},
+		},
+		{
+			name:  "Metric units with wind in m/s",
+			units: &model.UnitsInput{WindSpeed: windSpeedUnitPtr(model.WindSpeedUnitMs)},
+			expected: repository.ForecastOptions{ForecastDays: 7, Units: repository.Units{
+				Temperature: repository.Celsius, WindSpeed: repository.MetersPerSecond, Precipitation: repository.Millimeters,
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := toForecastOptions(tc.forecastDays, tc.pastDays, tc.tilt, tc.azimuth, tc.units)
+			if tc.expectError {
+				assert.Error(t, err, "Failed test: "+tc.name)
+				return
+			}
+			assert.NoError(t, err, "Failed test: "+tc.name)
+			assert.Equal(t, tc.expected, result, "Failed test: "+tc.name)
+		})
+	}
+}
+
+func TestToDays(t *testing.T) {
+	days, err := toDays(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, days)
+
+	days, err = toDays(intPtr(16))
+	assert.NoError(t, err)
+	assert.Equal(t, 16, days)
+
+	_, err = toDays(intPtr(17))
+	assert.Error(t, err)
+}
+
+func TestToHistoricalOptions(t *testing.T) {
+	from := model.NewDate(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC))
+	to := model.NewDate(time.Date(2023, 1, 31, 12, 0, 0, 0, time.UTC))
+
+	opts, err := toHistoricalOptions(from, to, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, repository.HistoricalOptions{
+		From:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+		Resolution: repository.ResolutionHourly,
+	}, opts)
+
+	daily := model.WeatherResolutionDaily
+	opts, err = toHistoricalOptions(from, to, &daily)
+	assert.NoError(t, err)
+	assert.Equal(t, repository.ResolutionDaily, opts.Resolution)
+
+	weekly := model.WeatherResolution("WEEKLY")
+	_, err = toHistoricalOptions(from, to, &weekly)
+	assert.Error(t, err)
+}
+
+func TestToDate(t *testing.T) {
+	assert.Equal(t, "", toDate(nil))
+
+	date := model.NewDate(time.Date(2024, 1, 31, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60)))
+	assert.Equal(t, "2024-01-31", toDate(&date))
+}
+
+func TestToPowerPlantSort(t *testing.T) {
+	assert.Equal(t, repository.PowerPlantSort{}, toPowerPlantSort(nil))
+
+	asc := model.SortDirectionAsc
+	assert.Equal(t, repository.PowerPlantSort{Field: repository.SortByCapacity},
+		toPowerPlantSort(&model.PowerPlantSort{Field: model.PowerPlantSortFieldCapacity, Direction: &asc}))
+
+	desc := model.SortDirectionDesc
+	assert.Equal(t, repository.PowerPlantSort{Field: repository.SortByCreatedAt, Descending: true},
+		toPowerPlantSort(&model.PowerPlantSort{Field: model.PowerPlantSortFieldCreatedAt, Direction: &desc}))
+}
+
+func TestToPage(t *testing.T) {
+	cursor := repository.Cursor{Field: repository.SortByID, ID: "10"}
+	encoded := cursor.String()
+	invalid := "invalid"
+
+	testCases := []struct {
+		name          string
+		first, last   *int
+		after, before *string
+		expected      repository.Page
+		expectError   bool
+	}{
+		{
+			name:     "Defaults",
+			expected: repository.Page{Limit: 10},
+		},
+		{
+			name:     "First after a cursor",
+			first:    intPtr(5),
+			after:    &encoded,
+			expected: repository.Page{Limit: 5, Cursor: &cursor},
+		},
+		{
+			name:     "Last page",
+			last:     intPtr(5),
+			expected: repository.Page{Limit: 5, Backward: true},
+		},
+		{
+			name:     "Before a cursor",
+			before:   &encoded,
+			expected: repository.Page{Limit: 10, Cursor: &cursor, Backward: true},
+		},
+		{
+			name:        "First and last",
+			first:       intPtr(5),
+			last:        intPtr(5),
+			expectError: true,
+		},
+		{
+			name:        "First before a cursor",
+			first:       intPtr(5),
+			before:      &encoded,
+			expectError: true,
+		},
+		{
+			name:        "Invalid cursor",
+			after:       &invalid,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := toPage(tc.first, tc.after, tc.last, tc.before)
+			if tc.expectError {
+				assert.Error(t, err, "Failed test: "+tc.name)
+				return
+			}
+			assert.NoError(t, err, "Failed test: "+tc.name)
+			assert.Equal(t, tc.expected, result, "Failed test: "+tc.name)
+		})
+	}
+}
+
 // Helper function to create an int pointer
 func intPtr(val int) *int {
 	return &val
 }
+
+func unitSystemPtr(system model.UnitSystem) *model.UnitSystem {
+	return &system
+}
+
+func windSpeedUnitPtr(unit model.WindSpeedUnit) *model.WindSpeedUnit {
+	return &unit
+}
@@ -6,8 +6,11 @@ package integrationtests_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -96,3 +99,209 @@ func TestCreateUpdatePowerPlant(t *testing.T) {
 	assert.Equal(t, 52.636083, updateResponse.Data.UpdatePowerPlant.Latitude)
 	assert.Equal(t, 13.42977, updateResponse.Data.UpdatePowerPlant.Longitude)
 }
+
+func TestDeleteRestorePowerPlant(t *testing.T) {
+	createResponse := postGraphQL(t, `mutation ($input: NewPowerPlantInput!) { createPowerPlant(input: $input) { id } }`,
+		map[string]interface{}{
+			"input": map[string]interface{}{
+				"name":      "Hamburg Solar Park",
+				"latitude":  53.551086,
+				"longitude": 9.993682,
+			},
+		})
+	id := createResponse["createPowerPlant"].(map[string]interface{})["id"].(string)
+
+	// delete the power plant
+	deleteResponse := postGraphQL(t, `mutation ($id: ID!) { deletePowerPlant(id: $id) { id deletedAt } }`,
+		map[string]interface{}{"id": id})
+	assert.NotNil(t, deleteResponse["deletePowerPlant"].(map[string]interface{})["deletedAt"])
+
+	// deleted power plants are hidden by default
+	getResponse := postGraphQL(t, `query ($id: ID!) { powerPlant(id: $id) { id } }`, map[string]interface{}{"id": id})
+	assert.Nil(t, getResponse["powerPlant"])
+
+	getResponse = postGraphQL(t, `query ($id: ID!) { powerPlant(id: $id, includeDeleted: true) { id deletedAt } }`,
+		map[string]interface{}{"id": id})
+	assert.Equal(t, id, getResponse["powerPlant"].(map[string]interface{})["id"])
+
+	// restore the power plant
+	restoreResponse := postGraphQL(t, `mutation ($id: ID!) { restorePowerPlant(id: $id) { id deletedAt } }`,
+		map[string]interface{}{"id": id})
+	assert.Nil(t, restoreResponse["restorePowerPlant"].(map[string]interface{})["deletedAt"])
+
+	getResponse = postGraphQL(t, `query ($id: ID!) { powerPlant(id: $id) { id } }`, map[string]interface{}{"id": id})
+	assert.Equal(t, id, getResponse["powerPlant"].(map[string]interface{})["id"])
+}
+
+func TestPowerPlantDetails(t *testing.T) {
+	fields := `id plantType capacityMw commissioningDate status operator`
+	createResponse := postGraphQL(t, `mutation ($input: NewPowerPlantInput!) { createPowerPlant(input: $input) { `+fields+` } }`,
+		map[string]interface{}{
+			"input": map[string]interface{}{
+				"name":              "Kiel Offshore Wind Farm",
+				"latitude":          54.323293,
+				"longitude":         10.122765,
+				"plantType":         "WIND",
+				"capacityMw":        288.0,
+				"commissioningDate": "2019-06-01",
+				"operator":          "Kaze Energy",
+			},
+		})
+	created := createResponse["createPowerPlant"].(map[string]interface{})
+	assert.Equal(t, "WIND", created["plantType"])
+	assert.Equal(t, 288.0, created["capacityMw"])
+	assert.Equal(t, "2019-06-01", created["commissioningDate"])
+	assert.Equal(t, "OPERATING", created["status"])
+	assert.Equal(t, "Kaze Energy", created["operator"])
+
+	// a capacity above the limit of the plant type is rejected
+	rejected := postGraphQL(t, `mutation ($id: ID!, $input: UpdatePowerPlantInput!) { updatePowerPlant(id: $id, input: $input) { id } }`,
+		map[string]interface{}{"id": created["id"], "input": map[string]interface{}{"plantType": "TIDAL", "capacityMw": 5000.0}})
+	assert.Nil(t, rejected["updatePowerPlant"])
+
+	updateResponse := postGraphQL(t, `mutation ($id: ID!, $input: UpdatePowerPlantInput!) { updatePowerPlant(id: $id, input: $input) { `+fields+` } }`,
+		map[string]interface{}{"id": created["id"], "input": map[string]interface{}{"status": "MAINTENANCE", "operator": ""}})
+	updated := updateResponse["updatePowerPlant"].(map[string]interface{})
+	assert.Equal(t, "MAINTENANCE", updated["status"])
+	assert.Nil(t, updated["operator"])
+	assert.Equal(t, 288.0, updated["capacityMw"])
+
+	// null removes the capacity and the commissioning date, the omitted plant type is kept
+	clearResponse := postGraphQL(t, `mutation ($id: ID!, $input: UpdatePowerPlantInput!) { updatePowerPlant(id: $id, input: $input) { `+fields+` } }`,
+		map[string]interface{}{"id": created["id"], "input": map[string]interface{}{"capacityMw": nil, "commissioningDate": nil}})
+	cleared := clearResponse["updatePowerPlant"].(map[string]interface{})
+	assert.Nil(t, cleared["capacityMw"])
+	assert.Nil(t, cleared["commissioningDate"])
+	assert.Equal(t, "WIND", cleared["plantType"])
+}
+
+func TestPowerPlantWeatherProvider(t *testing.T) {
+	// the provider names of the Open-Meteo models are longer than the names of the other providers
+	createResponse := postGraphQL(t, `mutation ($input: NewPowerPlantInput!) { createPowerPlant(input: $input) { id weatherProvider } }`,
+		map[string]interface{}{
+			"input": map[string]interface{}{
+				"name":            "Munich Solar Park",
+				"latitude":        48.137154,
+				"longitude":       11.576124,
+				"weatherProvider": "openmeteo:icon_seamless",
+			},
+		})
+	created := createResponse["createPowerPlant"].(map[string]interface{})
+	assert.Equal(t, "openmeteo:icon_seamless", created["weatherProvider"])
+
+	getResponse := postGraphQL(t, `query ($id: ID!) { powerPlant(id: $id) { weatherProvider } }`, map[string]interface{}{"id": created["id"]})
+	assert.Equal(t, "openmeteo:icon_seamless", getResponse["powerPlant"].(map[string]interface{})["weatherProvider"])
+}
+
+// postGraphQL sends the query to the server and returns the data of the response.
+func TestGeoJSONExportAndImport(t *testing.T) {
+	name := fmt.Sprintf("Wakkanai Wind Farm %d", time.Now().UnixNano())
+	importQuery := `mutation ($collection: GeoJSONFeatureCollection!) {
+        importPowerPlants(featureCollection: $collection) { created updated errors { index id message } }
+    }`
+	feature := func(id interface{}, properties map[string]interface{}) map[string]interface{} {
+		f := map[string]interface{}{
+			"type":       "Feature",
+			"geometry":   map[string]interface{}{"type": "Point", "coordinates": []float64{141.6729, 45.4156}},
+			"properties": properties,
+		}
+		if id != nil {
+			f["id"] = id
+		}
+		return f
+	}
+
+	// create a power plant, an invalid feature is reported and skipped
+	data := postGraphQL(t, importQuery, map[string]interface{}{"collection": map[string]interface{}{
+		"type": "FeatureCollection",
+		"features": []interface{}{
+			feature(nil, map[string]interface{}{"name": name, "plantType": "WIND", "capacityMw": 57}),
+			feature(nil, map[string]interface{}{"name": "Wakkanai Solar", "plantType": "SOLAR", "capacityMw": 50000}),
+		},
+	}})
+	result := data["importPowerPlants"].(map[string]interface{})
+	assert.Equal(t, 1.0, result["created"])
+	assert.Len(t, result["errors"], 1)
+	assert.Equal(t, 1.0, result["errors"].([]interface{})[0].(map[string]interface{})["index"])
+
+	exported := exportGeoJSON(t, url.Values{"name": {name}})
+	assert.Len(t, exported.Features, 1)
+	plant := exported.Features[0]
+	assert.Equal(t, "Point", plant.Geometry.Type)
+	assert.Equal(t, []float64{141.6729, 45.4156}, plant.Geometry.Coordinates)
+	assert.Equal(t, "OPERATING", plant.Properties["status"])
+
+	// update the exported power plant by its ID
+	data = postGraphQL(t, importQuery, map[string]interface{}{"collection": map[string]interface{}{
+		"type":     "FeatureCollection",
+		"features": []interface{}{feature(plant.ID, map[string]interface{}{"name": name, "status": "MAINTENANCE"})},
+	}})
+	result = data["importPowerPlants"].(map[string]interface{})
+	assert.Equal(t, 1.0, result["updated"])
+	assert.Empty(t, result["errors"])
+
+	exported = exportGeoJSON(t, url.Values{"name": {name}, "status": {"MAINTENANCE"}})
+	assert.Len(t, exported.Features, 1)
+	assert.Equal(t, 57.0, exported.Features[0].Properties["capacityMw"])
+
+	// the IDs of other sources are rejected, only the IDs of exported power plants are accepted
+	data = postGraphQL(t, importQuery, map[string]interface{}{"collection": map[string]interface{}{
+		"type":     "FeatureCollection",
+		"features": []interface{}{feature("way/123", map[string]interface{}{"name": name})},
+	}})
+	result = data["importPowerPlants"].(map[string]interface{})
+	assert.Equal(t, 0.0, result["created"])
+	assert.Equal(t, 0.0, result["updated"])
+	featureError := result["errors"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "way/123", featureError["id"])
+	assert.Contains(t, featureError["message"], "is not the ID of a power plant")
+}
+
+// featureCollection is a GeoJSON FeatureCollection of power plants.
+type featureCollection struct {
+	Type     string
+	Features []struct {
+		ID       string
+		Geometry struct {
+			Type        string
+			Coordinates []float64
+		}
+		Properties map[string]interface{}
+	}
+}
+
+// exportGeoJSON fetches the power plants matching the query parameters from the GeoJSON endpoint.
+func exportGeoJSON(t *testing.T, query url.Values) featureCollection {
+	resp, err := http.Get("http://localhost:8080/geojson?" + query.Encode())
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/geo+json", resp.Header.Get("Content-Type"))
+	var collection featureCollection
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&collection))
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	return collection
+}
+
+func postGraphQL(t *testing.T, query string, variables map[string]interface{}) map[string]interface{} {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	assert.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8080/graphql", "application/json", bytes.NewBuffer(requestBody))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	assert.NoError(t, err)
+
+	return response.Data
+}
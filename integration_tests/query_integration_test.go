@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -111,3 +112,170 @@ func TestListPowerPlants(t *testing.T) {
 	assert.Equal(t, 37.4513, response.Data.ListPowerPlants.PowerPlants[0].Latitude)
 	assert.Equal(t, 141.0334, response.Data.ListPowerPlants.PowerPlants[0].Longitude)
 }
+
+func TestListPowerPlantsWithFilterAndSort(t *testing.T) {
+	query := `query ($filter: PowerPlantFilter, $sort: PowerPlantSort) {
+        listPowerPlants(filter: $filter, sort: $sort) { powerPlants { name } totalCount }
+    }`
+	names := func(data map[string]interface{}) []string {
+		list := data["listPowerPlants"].(map[string]interface{})
+		names := []string{}
+		for _, plant := range list["powerPlants"].([]interface{}) {
+			names = append(names, plant.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	// the types of the test data are derived from their names
+	geothermal := postGraphQL(t, query, map[string]interface{}{
+		"filter": map[string]interface{}{"plantTypes": []string{"GEOTHERMAL"}, "nameContains": "plant"},
+		"sort":   map[string]interface{}{"field": "NAME"},
+	})
+	assert.Equal(t, []string{"Kagoshima Geothermal Plant", "Osaka Geothermal Plant", "Shikoku Geothermal Plant", "Shizuoka Geothermal Plant"}, names(geothermal))
+	assert.Equal(t, 4.0, geothermal["listPowerPlants"].(map[string]interface{})["totalCount"])
+
+	search := postGraphQL(t, query, map[string]interface{}{"filter": map[string]interface{}{"search": "hokaido"}})
+	assert.Contains(t, names(search), "Hokkaido Wind Farm")
+
+	nearTokyo := postGraphQL(t, query, map[string]interface{}{
+		"sort": map[string]interface{}{"field": "DISTANCE", "from": map[string]interface{}{"latitude": 35.68, "longitude": 139.69}},
+	})
+	assert.Equal(t, "Saitama Wind Turbine Array", names(nearTokyo)[0])
+}
+
+func TestListPowerPlantsCreatedAfter(t *testing.T) {
+	before := time.Now().Add(-time.Minute)
+	created := postGraphQL(t, `mutation ($input: NewPowerPlantInput!) { createPowerPlant(input: $input) { id } }`,
+		map[string]interface{}{"input": map[string]interface{}{"name": "Akita Offshore Wind Farm", "latitude": 39.77, "longitude": 140.0}})
+	id := created["createPowerPlant"].(map[string]interface{})["id"]
+
+	query := `query ($filter: PowerPlantFilter) { listPowerPlants(filter: $filter) { powerPlants { id } } }`
+	ids := func(createdAfter time.Time) []interface{} {
+		data := postGraphQL(t, query, map[string]interface{}{"filter": map[string]interface{}{"createdAfter": createdAfter.Format(time.RFC3339)}})
+		ids := []interface{}{}
+		for _, plant := range data["listPowerPlants"].(map[string]interface{})["powerPlants"].([]interface{}) {
+			ids = append(ids, plant.(map[string]interface{})["id"])
+		}
+		return ids
+	}
+
+	// the time zone of the filter must not shift the stored UTC times
+	jst := time.FixedZone("JST", 9*60*60)
+	assert.Contains(t, ids(before.In(jst)), id)
+	assert.NotContains(t, ids(time.Now().Add(time.Hour).In(jst)), id)
+}
+
+func TestPowerPlantsConnection(t *testing.T) {
+	query := `query ($after: String, $before: String, $first: Int, $last: Int) {
+        powerPlants(after: $after, before: $before, first: $first, last: $last, sort: {field: CAPACITY, direction: DESC}) {
+            edges { cursor node { id } } pageInfo { hasNextPage hasPreviousPage endCursor } totalCount
+        }
+    }`
+
+	// page forward through all power plants, the ones without a capacity come last and are sorted by ID
+	ids := map[string]bool{}
+	var after interface{}
+	var cursors []string
+	for {
+		data := postGraphQL(t, query, map[string]interface{}{"first": 7, "after": after})
+		connection := data["powerPlants"].(map[string]interface{})
+		for _, edge := range connection["edges"].([]interface{}) {
+			edge := edge.(map[string]interface{})
+			id := edge["node"].(map[string]interface{})["id"].(string)
+			assert.False(t, ids[id], "Expected every power plant once, got %s twice", id)
+			ids[id] = true
+			cursors = append(cursors, edge["cursor"].(string))
+		}
+
+		pageInfo := connection["pageInfo"].(map[string]interface{})
+		if !pageInfo["hasNextPage"].(bool) {
+			assert.Equal(t, float64(len(ids)), connection["totalCount"])
+			break
+		}
+		after = pageInfo["endCursor"]
+	}
+
+	// the page before the last power plant ends with the power plant before it
+	data := postGraphQL(t, query, map[string]interface{}{"last": 2, "before": cursors[len(cursors)-1]})
+	edges := data["powerPlants"].(map[string]interface{})["edges"].([]interface{})
+	assert.Len(t, edges, 2)
+	assert.Equal(t, cursors[len(cursors)-2], edges[1].(map[string]interface{})["cursor"])
+}
+
+func TestPlantsNearAndWithin(t *testing.T) {
+	query := `query ($polygon: GeoJSON!) {
+        plantsNear(latitude: 35.68, longitude: 139.69, radiusKm: 50) { powerPlant { name } distanceKm }
+        plantsWithin(polygon: $polygon) { name }
+    }`
+	names := func(plants []interface{}) []string {
+		names := []string{}
+		for _, plant := range plants {
+			names = append(names, plant.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	// the Kanto region without a hole around Yokosuka
+	kanto := map[string]interface{}{"polygon": map[string]interface{}{
+		"type": "Polygon",
+		"coordinates": [][][]float64{
+			{{139.3, 35.2}, {140.0, 35.2}, {140.0, 36.0}, {139.3, 36.0}, {139.3, 35.2}},
+			{{139.6, 35.25}, {139.75, 35.25}, {139.75, 35.35}, {139.6, 35.35}, {139.6, 35.25}},
+		},
+	}}
+	data := postGraphQL(t, query, kanto)
+
+	near := data["plantsNear"].([]interface{})
+	nearest := near[0].(map[string]interface{})
+	assert.Equal(t, "Saitama Wind Turbine Array", nearest["powerPlant"].(map[string]interface{})["name"])
+	assert.InDelta(t, 20.6, nearest["distanceKm"], 0.5)
+	for i := range near {
+		distance := near[i].(map[string]interface{})["distanceKm"].(float64)
+		assert.LessOrEqual(t, distance, 50.0)
+		if i > 0 {
+			assert.GreaterOrEqual(t, distance, near[i-1].(map[string]interface{})["distanceKm"].(float64), "Expected the nearest power plants first")
+		}
+	}
+
+	within := names(data["plantsWithin"].([]interface{}))
+	assert.Contains(t, within, "Yokohama Urban Solar Array")
+	assert.Contains(t, within, "Saitama Wind Turbine Array")
+	assert.NotContains(t, within, "Yokosuka Biomass Power Plant")
+	assert.NotContains(t, within, "Kyoto Solar Array")
+
+	// the limit keeps the first power plants by ID
+	limited := postGraphQL(t, `query ($polygon: GeoJSON!) { plantsWithin(polygon: $polygon, limit: 1) { name } }`, kanto)
+	assert.Equal(t, within[:1], names(limited["plantsWithin"].([]interface{})))
+}
+
+func TestGetUnknownPowerPlant(t *testing.T) {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"query":     `query getPowerPlant($id: ID!) { powerPlant(id: $id) { id } }`,
+		"variables": map[string]interface{}{"id": "999999"},
+	})
+	assert.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8080/graphql", "application/json", bytes.NewBuffer(requestBody))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response struct {
+		Data struct {
+			PowerPlant *struct {
+				ID string `json:"id"`
+			} `json:"powerPlant"`
+		} `json:"data"`
+		Errors []struct {
+			Message    string                 `json:"message"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	assert.NoError(t, err)
+
+	assert.Nil(t, response.Data.PowerPlant)
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "NOT_FOUND", response.Errors[0].Extensions["code"])
+	}
+}
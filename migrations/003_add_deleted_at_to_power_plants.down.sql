@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS power_plants_not_deleted_idx;
+
+ALTER TABLE power_plants DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE power_plants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITHOUT TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS power_plants_not_deleted_idx ON power_plants (id) WHERE deleted_at IS NULL;
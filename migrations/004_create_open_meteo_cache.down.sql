@@ -0,0 +1 @@
+DROP TABLE IF EXISTS open_meteo_cache;
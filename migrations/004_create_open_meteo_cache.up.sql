@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS open_meteo_cache (
+    key VARCHAR(255) PRIMARY KEY,
+    value JSONB NOT NULL,
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS open_meteo_cache_expires_at_idx ON open_meteo_cache (expires_at);
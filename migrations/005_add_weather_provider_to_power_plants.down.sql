@@ -0,0 +1 @@
+ALTER TABLE power_plants DROP COLUMN IF EXISTS weather_provider;
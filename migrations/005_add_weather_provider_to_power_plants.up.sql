@@ -0,0 +1 @@
+ALTER TABLE power_plants ADD COLUMN IF NOT EXISTS weather_provider VARCHAR(20);
@@ -0,0 +1,5 @@
+ALTER TABLE power_plants DROP COLUMN IF EXISTS operator;
+ALTER TABLE power_plants DROP COLUMN IF EXISTS status;
+ALTER TABLE power_plants DROP COLUMN IF EXISTS commissioning_date;
+ALTER TABLE power_plants DROP COLUMN IF EXISTS capacity_mw;
+ALTER TABLE power_plants DROP COLUMN IF EXISTS plant_type;
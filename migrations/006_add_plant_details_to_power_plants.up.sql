@@ -0,0 +1,18 @@
+ALTER TABLE power_plants ADD COLUMN IF NOT EXISTS plant_type VARCHAR(20)
+    CHECK (plant_type IN ('SOLAR', 'WIND', 'GEOTHERMAL', 'TIDAL', 'BIOMASS', 'HYDRO'));
+ALTER TABLE power_plants ADD COLUMN IF NOT EXISTS capacity_mw DOUBLE PRECISION CHECK (capacity_mw > 0);
+ALTER TABLE power_plants ADD COLUMN IF NOT EXISTS commissioning_date DATE;
+ALTER TABLE power_plants ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'OPERATING'
+    CHECK (status IN ('PLANNED', 'OPERATING', 'MAINTENANCE', 'DECOMMISSIONED'));
+ALTER TABLE power_plants ADD COLUMN IF NOT EXISTS operator VARCHAR(255);
+
+-- The type of the test power plants is derived from their names
+UPDATE power_plants SET plant_type = CASE
+    WHEN name ILIKE '%solar%' THEN 'SOLAR'
+    WHEN name ILIKE '%wind%' THEN 'WIND'
+    WHEN name ILIKE '%geothermal%' THEN 'GEOTHERMAL'
+    WHEN name ILIKE '%tidal%' THEN 'TIDAL'
+    WHEN name ILIKE '%biomass%' OR name ILIKE '%biofuel%' THEN 'BIOMASS'
+    WHEN name ILIKE '%hydro%' THEN 'HYDRO'
+END
+WHERE plant_type IS NULL;
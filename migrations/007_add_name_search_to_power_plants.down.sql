@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS power_plants_created_at_idx;
+DROP INDEX IF EXISTS power_plants_name_trgm_idx;
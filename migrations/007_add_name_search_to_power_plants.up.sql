@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- The trigram index is used by the ILIKE filter on the name and by the fuzzy search
+CREATE INDEX IF NOT EXISTS power_plants_name_trgm_idx ON power_plants USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS power_plants_created_at_idx ON power_plants (created_at);
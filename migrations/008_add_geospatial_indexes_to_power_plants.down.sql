@@ -0,0 +1,4 @@
+-- The PostGIS extension is kept, other database objects may depend on it
+DROP INDEX IF EXISTS power_plants_geography_idx;
+DROP INDEX IF EXISTS power_plants_geometry_idx;
+DROP INDEX IF EXISTS power_plants_point_idx;
@@ -0,0 +1,15 @@
+-- The geospatial queries use PostGIS if it is installed and the geometric types of PostgreSQL otherwise,
+-- both have a GiST index on the location of the power plants
+CREATE INDEX IF NOT EXISTS power_plants_point_idx ON power_plants USING GIST (point(longitude, latitude));
+
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS postgis;
+    CREATE INDEX IF NOT EXISTS power_plants_geometry_idx ON power_plants
+        USING GIST (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326));
+    CREATE INDEX IF NOT EXISTS power_plants_geography_idx ON power_plants
+        USING GIST ((ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography));
+EXCEPTION WHEN OTHERS THEN
+    RAISE NOTICE 'PostGIS is not available (%), the geospatial queries use the geometric types of PostgreSQL', SQLERRM;
+END
+$$;
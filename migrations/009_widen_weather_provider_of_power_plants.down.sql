@@ -0,0 +1,3 @@
+-- Power plants with a provider name that doesn't fit are reset to the default provider
+ALTER TABLE power_plants ALTER COLUMN weather_provider TYPE VARCHAR(20)
+    USING CASE WHEN LENGTH(weather_provider) <= 20 THEN weather_provider END;
@@ -0,0 +1,2 @@
+-- The provider names of the Open-Meteo models, e.g. "openmeteo:icon_seamless", are longer than 20 characters
+ALTER TABLE power_plants ALTER COLUMN weather_provider TYPE VARCHAR(100);
@@ -0,0 +1,6 @@
+ALTER TABLE power_plants ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE power_plants ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE power_plants SET
+    created_at = created_at AT TIME ZONE 'utc' AT TIME ZONE current_setting('TimeZone'),
+    updated_at = updated_at AT TIME ZONE 'utc' AT TIME ZONE current_setting('TimeZone');
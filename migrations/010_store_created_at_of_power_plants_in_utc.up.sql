@@ -0,0 +1,8 @@
+-- CURRENT_TIMESTAMP was stored in the time zone of the session, the times are compared and returned as UTC
+-- like deleted_at. The existing times are converted assuming they were stored in the time zone of the database.
+UPDATE power_plants SET
+    created_at = created_at AT TIME ZONE current_setting('TimeZone') AT TIME ZONE 'utc',
+    updated_at = updated_at AT TIME ZONE current_setting('TimeZone') AT TIME ZONE 'utc';
+
+ALTER TABLE power_plants ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'utc');
+ALTER TABLE power_plants ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'utc');
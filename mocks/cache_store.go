@@ -0,0 +1,77 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	repository "github.com/glower/kaze/pkg/repository"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CacheStore is an autogenerated mock type for the CacheStore type
+type CacheStore struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, key
+func (_m *CacheStore) Get(ctx context.Context, key string) (*repository.CacheItem, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *repository.CacheItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.CacheItem, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.CacheItem); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.CacheItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Set provides a mock function with given fields: ctx, key, item
+func (_m *CacheStore) Set(ctx context.Context, key string, item repository.CacheItem) error {
+	ret := _m.Called(ctx, key, item)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, repository.CacheItem) error); ok {
+		r0 = rf(ctx, key, item)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCacheStore creates a new instance of CacheStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCacheStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CacheStore {
+	mock := &CacheStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -14,6 +14,36 @@ type OpenMeteoRepository struct {
 	mock.Mock
 }
 
+// GetDailyWeather provides a mock function with given fields: ctx, coordinates, days
+func (_m *OpenMeteoRepository) GetDailyWeather(ctx context.Context, coordinates []repository.Coordinates, days int) ([]*repository.DailyWeatherResponse, error) {
+	ret := _m.Called(ctx, coordinates, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDailyWeather")
+	}
+
+	var r0 []*repository.DailyWeatherResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, int) ([]*repository.DailyWeatherResponse, error)); ok {
+		return rf(ctx, coordinates, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, int) []*repository.DailyWeatherResponse); ok {
+		r0 = rf(ctx, coordinates, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.DailyWeatherResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []repository.Coordinates, int) error); ok {
+		r1 = rf(ctx, coordinates, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetElevation provides a mock function with given fields: ctx, latitude, longitude
 func (_m *OpenMeteoRepository) GetElevation(ctx context.Context, latitude float64, longitude float64) (float64, error) {
 	ret := _m.Called(ctx, latitude, longitude)
@@ -42,9 +72,69 @@ func (_m *OpenMeteoRepository) GetElevation(ctx context.Context, latitude float6
 	return r0, r1
 }
 
-// GetWeatherForecast provides a mock function with given fields: ctx, latitude, longitude
-func (_m *OpenMeteoRepository) GetWeatherForecast(ctx context.Context, latitude float64, longitude float64) (*repository.WeatherForecastResponse, error) {
-	ret := _m.Called(ctx, latitude, longitude)
+// GetElevations provides a mock function with given fields: ctx, coordinates
+func (_m *OpenMeteoRepository) GetElevations(ctx context.Context, coordinates []repository.Coordinates) ([]float64, error) {
+	ret := _m.Called(ctx, coordinates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetElevations")
+	}
+
+	var r0 []float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates) ([]float64, error)); ok {
+		return rf(ctx, coordinates)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates) []float64); ok {
+		r0 = rf(ctx, coordinates)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]float64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []repository.Coordinates) error); ok {
+		r1 = rf(ctx, coordinates)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHistoricalWeather provides a mock function with given fields: ctx, coordinates, opts
+func (_m *OpenMeteoRepository) GetHistoricalWeather(ctx context.Context, coordinates []repository.Coordinates, opts repository.HistoricalOptions) ([]*repository.HistoricalWeatherResponse, error) {
+	ret := _m.Called(ctx, coordinates, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistoricalWeather")
+	}
+
+	var r0 []*repository.HistoricalWeatherResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, repository.HistoricalOptions) ([]*repository.HistoricalWeatherResponse, error)); ok {
+		return rf(ctx, coordinates, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, repository.HistoricalOptions) []*repository.HistoricalWeatherResponse); ok {
+		r0 = rf(ctx, coordinates, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.HistoricalWeatherResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []repository.Coordinates, repository.HistoricalOptions) error); ok {
+		r1 = rf(ctx, coordinates, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWeatherForecast provides a mock function with given fields: ctx, latitude, longitude, opts
+func (_m *OpenMeteoRepository) GetWeatherForecast(ctx context.Context, latitude float64, longitude float64, opts repository.ForecastOptions) (*repository.WeatherForecastResponse, error) {
+	ret := _m.Called(ctx, latitude, longitude, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetWeatherForecast")
@@ -52,19 +142,49 @@ func (_m *OpenMeteoRepository) GetWeatherForecast(ctx context.Context, latitude
 
 	var r0 *repository.WeatherForecastResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, float64, float64) (*repository.WeatherForecastResponse, error)); ok {
-		return rf(ctx, latitude, longitude)
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, repository.ForecastOptions) (*repository.WeatherForecastResponse, error)); ok {
+		return rf(ctx, latitude, longitude, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, float64, float64) *repository.WeatherForecastResponse); ok {
-		r0 = rf(ctx, latitude, longitude)
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, repository.ForecastOptions) *repository.WeatherForecastResponse); ok {
+		r0 = rf(ctx, latitude, longitude, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*repository.WeatherForecastResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, float64, float64) error); ok {
-		r1 = rf(ctx, latitude, longitude)
+	if rf, ok := ret.Get(1).(func(context.Context, float64, float64, repository.ForecastOptions) error); ok {
+		r1 = rf(ctx, latitude, longitude, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWeatherForecasts provides a mock function with given fields: ctx, coordinates, opts
+func (_m *OpenMeteoRepository) GetWeatherForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*repository.WeatherForecastResponse, error) {
+	ret := _m.Called(ctx, coordinates, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWeatherForecasts")
+	}
+
+	var r0 []*repository.WeatherForecastResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, repository.ForecastOptions) ([]*repository.WeatherForecastResponse, error)); ok {
+		return rf(ctx, coordinates, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, repository.ForecastOptions) []*repository.WeatherForecastResponse); ok {
+		r0 = rf(ctx, coordinates, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.WeatherForecastResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []repository.Coordinates, repository.ForecastOptions) error); ok {
+		r1 = rf(ctx, coordinates, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
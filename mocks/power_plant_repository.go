@@ -7,6 +7,8 @@ import (
 
 	model "github.com/glower/kaze/graph/model"
 	mock "github.com/stretchr/testify/mock"
+
+	repository "github.com/glower/kaze/pkg/repository"
 )
 
 // PowerPlantRepository is an autogenerated mock type for the PowerPlantRepository type
@@ -14,6 +16,34 @@ type PowerPlantRepository struct {
 	mock.Mock
 }
 
+// Count provides a mock function with given fields: ctx, filter
+func (_m *PowerPlantRepository) Count(ctx context.Context, filter repository.PowerPlantFilter) (int, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter) (int, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter) int); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.PowerPlantFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, plant
 func (_m *PowerPlantRepository) Create(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
 	ret := _m.Called(ctx, plant)
@@ -44,29 +74,47 @@ func (_m *PowerPlantRepository) Create(ctx context.Context, plant *model.PowerPl
 	return r0, r1
 }
 
-// GetByID provides a mock function with given fields: ctx, id
-func (_m *PowerPlantRepository) GetByID(ctx context.Context, id string) (*model.PowerPlant, error) {
+// Delete provides a mock function with given fields: ctx, id
+func (_m *PowerPlantRepository) Delete(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
 
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, id, includeDeleted
+func (_m *PowerPlantRepository) GetByID(ctx context.Context, id string, includeDeleted bool) (*model.PowerPlant, error) {
+	ret := _m.Called(ctx, id, includeDeleted)
+
 	if len(ret) == 0 {
 		panic("no return value specified for GetByID")
 	}
 
 	var r0 *model.PowerPlant
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.PowerPlant, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*model.PowerPlant, error)); ok {
+		return rf(ctx, id, includeDeleted)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *model.PowerPlant); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *model.PowerPlant); ok {
+		r0 = rf(ctx, id, includeDeleted)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.PowerPlant)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, id, includeDeleted)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -74,9 +122,9 @@ func (_m *PowerPlantRepository) GetByID(ctx context.Context, id string) (*model.
 	return r0, r1
 }
 
-// List provides a mock function with given fields: ctx, offset, limit
-func (_m *PowerPlantRepository) List(ctx context.Context, offset int, limit int) ([]model.PowerPlant, int, error) {
-	ret := _m.Called(ctx, offset, limit)
+// List provides a mock function with given fields: ctx, offset, limit, filter, sort
+func (_m *PowerPlantRepository) List(ctx context.Context, offset int, limit int, filter repository.PowerPlantFilter, sort repository.PowerPlantSort) ([]model.PowerPlant, int, error) {
+	ret := _m.Called(ctx, offset, limit, filter, sort)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
@@ -85,25 +133,25 @@ func (_m *PowerPlantRepository) List(ctx context.Context, offset int, limit int)
 	var r0 []model.PowerPlant
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]model.PowerPlant, int, error)); ok {
-		return rf(ctx, offset, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, repository.PowerPlantFilter, repository.PowerPlantSort) ([]model.PowerPlant, int, error)); ok {
+		return rf(ctx, offset, limit, filter, sort)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []model.PowerPlant); ok {
-		r0 = rf(ctx, offset, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, repository.PowerPlantFilter, repository.PowerPlantSort) []model.PowerPlant); ok {
+		r0 = rf(ctx, offset, limit, filter, sort)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.PowerPlant)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
-		r1 = rf(ctx, offset, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, repository.PowerPlantFilter, repository.PowerPlantSort) int); ok {
+		r1 = rf(ctx, offset, limit, filter, sort)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
-		r2 = rf(ctx, offset, limit)
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, repository.PowerPlantFilter, repository.PowerPlantSort) error); ok {
+		r2 = rf(ctx, offset, limit, filter, sort)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -111,29 +159,89 @@ func (_m *PowerPlantRepository) List(ctx context.Context, offset int, limit int)
 	return r0, r1, r2
 }
 
-// Update provides a mock function with given fields: ctx, plant
-func (_m *PowerPlantRepository) Update(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
-	ret := _m.Called(ctx, plant)
+// ListNear provides a mock function with given fields: ctx, opts
+func (_m *PowerPlantRepository) ListNear(ctx context.Context, opts repository.NearOptions) ([]repository.PowerPlantDistance, error) {
+	ret := _m.Called(ctx, opts)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Update")
+		panic("no return value specified for ListNear")
 	}
 
-	var r0 *model.PowerPlant
+	var r0 []repository.PowerPlantDistance
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant) (*model.PowerPlant, error)); ok {
-		return rf(ctx, plant)
+	if rf, ok := ret.Get(0).(func(context.Context, repository.NearOptions) ([]repository.PowerPlantDistance, error)); ok {
+		return rf(ctx, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant) *model.PowerPlant); ok {
-		r0 = rf(ctx, plant)
+	if rf, ok := ret.Get(0).(func(context.Context, repository.NearOptions) []repository.PowerPlantDistance); ok {
+		r0 = rf(ctx, opts)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.PowerPlant)
+			r0 = ret.Get(0).([]repository.PowerPlantDistance)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlant) error); ok {
-		r1 = rf(ctx, plant)
+	if rf, ok := ret.Get(1).(func(context.Context, repository.NearOptions) error); ok {
+		r1 = rf(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPage provides a mock function with given fields: ctx, filter, sort, page
+func (_m *PowerPlantRepository) ListPage(ctx context.Context, filter repository.PowerPlantFilter, sort repository.PowerPlantSort, page repository.Page) (*repository.PowerPlantPage, error) {
+	ret := _m.Called(ctx, filter, sort, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPage")
+	}
+
+	var r0 *repository.PowerPlantPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter, repository.PowerPlantSort, repository.Page) (*repository.PowerPlantPage, error)); ok {
+		return rf(ctx, filter, sort, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter, repository.PowerPlantSort, repository.Page) *repository.PowerPlantPage); ok {
+		r0 = rf(ctx, filter, sort, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.PowerPlantPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.PowerPlantFilter, repository.PowerPlantSort, repository.Page) error); ok {
+		r1 = rf(ctx, filter, sort, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWithin provides a mock function with given fields: ctx, opts
+func (_m *PowerPlantRepository) ListWithin(ctx context.Context, opts repository.WithinOptions) ([]model.PowerPlant, error) {
+	ret := _m.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWithin")
+	}
+
+	var r0 []model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.WithinOptions) ([]model.PowerPlant, error)); ok {
+		return rf(ctx, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.WithinOptions) []model.PowerPlant); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.WithinOptions) error); ok {
+		r1 = rf(ctx, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -141,6 +249,90 @@ func (_m *PowerPlantRepository) Update(ctx context.Context, plant *model.PowerPl
 	return r0, r1
 }
 
+// ListWithoutElevation provides a mock function with given fields: ctx, limit
+func (_m *PowerPlantRepository) ListWithoutElevation(ctx context.Context, limit int) ([]model.PowerPlant, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWithoutElevation")
+	}
+
+	var r0 []model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]model.PowerPlant, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []model.PowerPlant); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *PowerPlantRepository) Restore(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, patch
+func (_m *PowerPlantRepository) Update(ctx context.Context, patch *model.PowerPlantPatch) error {
+	ret := _m.Called(ctx, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlantPatch) error); ok {
+		r0 = rf(ctx, patch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateElevation provides a mock function with given fields: ctx, id, elevation
+func (_m *PowerPlantRepository) UpdateElevation(ctx context.Context, id string, elevation float64) error {
+	ret := _m.Called(ctx, id, elevation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateElevation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) error); ok {
+		r0 = rf(ctx, id, elevation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewPowerPlantRepository creates a new instance of PowerPlantRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPowerPlantRepository(t interface {
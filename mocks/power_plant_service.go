@@ -5,8 +5,12 @@ package mocks
 import (
 	context "context"
 
-	model "github.com/glower/kaze/graph/model"
+	geojson "github.com/glower/kaze/pkg/geojson"
 	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/glower/kaze/graph/model"
+
+	repository "github.com/glower/kaze/pkg/repository"
 )
 
 // PowerPlantService is an autogenerated mock type for the PowerPlantService type
@@ -14,6 +18,34 @@ type PowerPlantService struct {
 	mock.Mock
 }
 
+// BackfillElevation provides a mock function with given fields: ctx, batchSize
+func (_m *PowerPlantService) BackfillElevation(ctx context.Context, batchSize int) (int, error) {
+	ret := _m.Called(ctx, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BackfillElevation")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, batchSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, batchSize)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, batchSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreatePowerPlant provides a mock function with given fields: ctx, plant
 func (_m *PowerPlantService) CreatePowerPlant(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
 	ret := _m.Called(ctx, plant)
@@ -44,9 +76,157 @@ func (_m *PowerPlantService) CreatePowerPlant(ctx context.Context, plant *model.
 	return r0, r1
 }
 
-// GetPowerPlant provides a mock function with given fields: ctx, id, withElevation, withWeatherForecasts
-func (_m *PowerPlantService) GetPowerPlant(ctx context.Context, id string, withElevation bool, withWeatherForecasts bool) (*model.PowerPlant, error) {
-	ret := _m.Called(ctx, id, withElevation, withWeatherForecasts)
+// DeletePowerPlant provides a mock function with given fields: ctx, id
+func (_m *PowerPlantService) DeletePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePowerPlant")
+	}
+
+	var r0 *model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.PowerPlant, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.PowerPlant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportPowerPlants provides a mock function with given fields: ctx, filter
+func (_m *PowerPlantService) ExportPowerPlants(ctx context.Context, filter repository.PowerPlantFilter) (*geojson.FeatureCollection, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportPowerPlants")
+	}
+
+	var r0 *geojson.FeatureCollection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter) (*geojson.FeatureCollection, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter) *geojson.FeatureCollection); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*geojson.FeatureCollection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.PowerPlantFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDailyWeather provides a mock function with given fields: ctx, plant, days
+func (_m *PowerPlantService) GetDailyWeather(ctx context.Context, plant *model.PowerPlant, days int) ([]*model.DailyWeather, error) {
+	ret := _m.Called(ctx, plant, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDailyWeather")
+	}
+
+	var r0 []*model.DailyWeather
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, int) ([]*model.DailyWeather, error)); ok {
+		return rf(ctx, plant, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, int) []*model.DailyWeather); ok {
+		r0 = rf(ctx, plant, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.DailyWeather)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlant, int) error); ok {
+		r1 = rf(ctx, plant, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetElevation provides a mock function with given fields: ctx, plant
+func (_m *PowerPlantService) GetElevation(ctx context.Context, plant *model.PowerPlant) (float64, error) {
+	ret := _m.Called(ctx, plant)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetElevation")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant) (float64, error)); ok {
+		return rf(ctx, plant)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant) float64); ok {
+		r0 = rf(ctx, plant)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlant) error); ok {
+		r1 = rf(ctx, plant)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHistoricalWeather provides a mock function with given fields: ctx, plant, historicalOpts
+func (_m *PowerPlantService) GetHistoricalWeather(ctx context.Context, plant *model.PowerPlant, historicalOpts repository.HistoricalOptions) (*model.HistoricalWeather, error) {
+	ret := _m.Called(ctx, plant, historicalOpts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistoricalWeather")
+	}
+
+	var r0 *model.HistoricalWeather
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, repository.HistoricalOptions) (*model.HistoricalWeather, error)); ok {
+		return rf(ctx, plant, historicalOpts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, repository.HistoricalOptions) *model.HistoricalWeather); ok {
+		r0 = rf(ctx, plant, historicalOpts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.HistoricalWeather)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlant, repository.HistoricalOptions) error); ok {
+		r1 = rf(ctx, plant, historicalOpts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPowerPlant provides a mock function with given fields: ctx, id, includeDeleted
+func (_m *PowerPlantService) GetPowerPlant(ctx context.Context, id string, includeDeleted bool) (*model.PowerPlant, error) {
+	ret := _m.Called(ctx, id, includeDeleted)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetPowerPlant")
@@ -54,19 +234,19 @@ func (_m *PowerPlantService) GetPowerPlant(ctx context.Context, id string, withE
 
 	var r0 *model.PowerPlant
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, bool, bool) (*model.PowerPlant, error)); ok {
-		return rf(ctx, id, withElevation, withWeatherForecasts)
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*model.PowerPlant, error)); ok {
+		return rf(ctx, id, includeDeleted)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, bool, bool) *model.PowerPlant); ok {
-		r0 = rf(ctx, id, withElevation, withWeatherForecasts)
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *model.PowerPlant); ok {
+		r0 = rf(ctx, id, includeDeleted)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.PowerPlant)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, bool, bool) error); ok {
-		r1 = rf(ctx, id, withElevation, withWeatherForecasts)
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, id, includeDeleted)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -74,9 +254,97 @@ func (_m *PowerPlantService) GetPowerPlant(ctx context.Context, id string, withE
 	return r0, r1
 }
 
-// ListPowerPlants provides a mock function with given fields: ctx, page, pageSize, withElevation, withWeatherForecasts
-func (_m *PowerPlantService) ListPowerPlants(ctx context.Context, page int, pageSize int, withElevation bool, withWeatherForecasts bool) (*model.PowerPlantList, error) {
-	ret := _m.Called(ctx, page, pageSize, withElevation, withWeatherForecasts)
+// GetWeatherForecasts provides a mock function with given fields: ctx, plant, forecastOpts
+func (_m *PowerPlantService) GetWeatherForecasts(ctx context.Context, plant *model.PowerPlant, forecastOpts repository.ForecastOptions) ([]*model.WeatherForecast, error) {
+	ret := _m.Called(ctx, plant, forecastOpts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWeatherForecasts")
+	}
+
+	var r0 []*model.WeatherForecast
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, repository.ForecastOptions) ([]*model.WeatherForecast, error)); ok {
+		return rf(ctx, plant, forecastOpts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, repository.ForecastOptions) []*model.WeatherForecast); ok {
+		r0 = rf(ctx, plant, forecastOpts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.WeatherForecast)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlant, repository.ForecastOptions) error); ok {
+		r1 = rf(ctx, plant, forecastOpts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasPrecipitation provides a mock function with given fields: ctx, plant, date
+func (_m *PowerPlantService) HasPrecipitation(ctx context.Context, plant *model.PowerPlant, date string) (bool, error) {
+	ret := _m.Called(ctx, plant, date)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasPrecipitation")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, string) (bool, error)); ok {
+		return rf(ctx, plant, date)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant, string) bool); ok {
+		r0 = rf(ctx, plant, date)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlant, string) error); ok {
+		r1 = rf(ctx, plant, date)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportPowerPlants provides a mock function with given fields: ctx, collection
+func (_m *PowerPlantService) ImportPowerPlants(ctx context.Context, collection geojson.FeatureCollection) (*model.ImportResult, error) {
+	ret := _m.Called(ctx, collection)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportPowerPlants")
+	}
+
+	var r0 *model.ImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geojson.FeatureCollection) (*model.ImportResult, error)); ok {
+		return rf(ctx, collection)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geojson.FeatureCollection) *model.ImportResult); ok {
+		r0 = rf(ctx, collection)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ImportResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geojson.FeatureCollection) error); ok {
+		r1 = rf(ctx, collection)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPowerPlants provides a mock function with given fields: ctx, page, pageSize, filter, sort
+func (_m *PowerPlantService) ListPowerPlants(ctx context.Context, page int, pageSize int, filter repository.PowerPlantFilter, sort repository.PowerPlantSort) (*model.PowerPlantList, error) {
+	ret := _m.Called(ctx, page, pageSize, filter, sort)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListPowerPlants")
@@ -84,19 +352,19 @@ func (_m *PowerPlantService) ListPowerPlants(ctx context.Context, page int, page
 
 	var r0 *model.PowerPlantList
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, bool, bool) (*model.PowerPlantList, error)); ok {
-		return rf(ctx, page, pageSize, withElevation, withWeatherForecasts)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, repository.PowerPlantFilter, repository.PowerPlantSort) (*model.PowerPlantList, error)); ok {
+		return rf(ctx, page, pageSize, filter, sort)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, bool, bool) *model.PowerPlantList); ok {
-		r0 = rf(ctx, page, pageSize, withElevation, withWeatherForecasts)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, repository.PowerPlantFilter, repository.PowerPlantSort) *model.PowerPlantList); ok {
+		r0 = rf(ctx, page, pageSize, filter, sort)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.PowerPlantList)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, bool, bool) error); ok {
-		r1 = rf(ctx, page, pageSize, withElevation, withWeatherForecasts)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, repository.PowerPlantFilter, repository.PowerPlantSort) error); ok {
+		r1 = rf(ctx, page, pageSize, filter, sort)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -104,9 +372,129 @@ func (_m *PowerPlantService) ListPowerPlants(ctx context.Context, page int, page
 	return r0, r1
 }
 
-// UpdatePowerPlant provides a mock function with given fields: ctx, plant
-func (_m *PowerPlantService) UpdatePowerPlant(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
-	ret := _m.Called(ctx, plant)
+// PagePowerPlants provides a mock function with given fields: ctx, filter, sort, page
+func (_m *PowerPlantService) PagePowerPlants(ctx context.Context, filter repository.PowerPlantFilter, sort repository.PowerPlantSort, page repository.Page) (*model.PowerPlantConnection, error) {
+	ret := _m.Called(ctx, filter, sort, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PagePowerPlants")
+	}
+
+	var r0 *model.PowerPlantConnection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter, repository.PowerPlantSort, repository.Page) (*model.PowerPlantConnection, error)); ok {
+		return rf(ctx, filter, sort, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PowerPlantFilter, repository.PowerPlantSort, repository.Page) *model.PowerPlantConnection); ok {
+		r0 = rf(ctx, filter, sort, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlantConnection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.PowerPlantFilter, repository.PowerPlantSort, repository.Page) error); ok {
+		r1 = rf(ctx, filter, sort, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PlantsNear provides a mock function with given fields: ctx, opts
+func (_m *PowerPlantService) PlantsNear(ctx context.Context, opts repository.NearOptions) ([]*model.PowerPlantDistance, error) {
+	ret := _m.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PlantsNear")
+	}
+
+	var r0 []*model.PowerPlantDistance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.NearOptions) ([]*model.PowerPlantDistance, error)); ok {
+		return rf(ctx, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.NearOptions) []*model.PowerPlantDistance); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlantDistance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.NearOptions) error); ok {
+		r1 = rf(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PlantsWithin provides a mock function with given fields: ctx, area, limit
+func (_m *PowerPlantService) PlantsWithin(ctx context.Context, area geojson.Geometry, limit int) ([]*model.PowerPlant, error) {
+	ret := _m.Called(ctx, area, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PlantsWithin")
+	}
+
+	var r0 []*model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geojson.Geometry, int) ([]*model.PowerPlant, error)); ok {
+		return rf(ctx, area, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geojson.Geometry, int) []*model.PowerPlant); ok {
+		r0 = rf(ctx, area, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geojson.Geometry, int) error); ok {
+		r1 = rf(ctx, area, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestorePowerPlant provides a mock function with given fields: ctx, id
+func (_m *PowerPlantService) RestorePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestorePowerPlant")
+	}
+
+	var r0 *model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.PowerPlant, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.PowerPlant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePowerPlant provides a mock function with given fields: ctx, patch
+func (_m *PowerPlantService) UpdatePowerPlant(ctx context.Context, patch *model.PowerPlantPatch) (*model.PowerPlant, error) {
+	ret := _m.Called(ctx, patch)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdatePowerPlant")
@@ -114,19 +502,19 @@ func (_m *PowerPlantService) UpdatePowerPlant(ctx context.Context, plant *model.
 
 	var r0 *model.PowerPlant
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant) (*model.PowerPlant, error)); ok {
-		return rf(ctx, plant)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlantPatch) (*model.PowerPlant, error)); ok {
+		return rf(ctx, patch)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlant) *model.PowerPlant); ok {
-		r0 = rf(ctx, plant)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PowerPlantPatch) *model.PowerPlant); ok {
+		r0 = rf(ctx, patch)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.PowerPlant)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlant) error); ok {
-		r1 = rf(ctx, plant)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.PowerPlantPatch) error); ok {
+		r1 = rf(ctx, patch)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -134,6 +522,26 @@ func (_m *PowerPlantService) UpdatePowerPlant(ctx context.Context, plant *model.
 	return r0, r1
 }
 
+// WeatherProviders provides a mock function with given fields: ctx
+func (_m *PowerPlantService) WeatherProviders(ctx context.Context) []*model.WeatherProviderInfo {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WeatherProviders")
+	}
+
+	var r0 []*model.WeatherProviderInfo
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.WeatherProviderInfo); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.WeatherProviderInfo)
+		}
+	}
+
+	return r0
+}
+
 // NewPowerPlantService creates a new instance of PowerPlantService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPowerPlantService(t interface {
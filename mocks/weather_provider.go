@@ -0,0 +1,97 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	repository "github.com/glower/kaze/pkg/repository"
+	mock "github.com/stretchr/testify/mock"
+
+	weather "github.com/glower/kaze/pkg/weather"
+)
+
+// WeatherProvider is an autogenerated mock type for the WeatherProvider type
+type WeatherProvider struct {
+	mock.Mock
+}
+
+// Coverage provides a mock function with given fields:
+func (_m *WeatherProvider) Coverage() weather.Coverage {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Coverage")
+	}
+
+	var r0 weather.Coverage
+	if rf, ok := ret.Get(0).(func() weather.Coverage); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(weather.Coverage)
+	}
+
+	return r0
+}
+
+// GetForecasts provides a mock function with given fields: ctx, coordinates, opts
+func (_m *WeatherProvider) GetForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*weather.Forecast, error) {
+	ret := _m.Called(ctx, coordinates, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetForecasts")
+	}
+
+	var r0 []*weather.Forecast
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, repository.ForecastOptions) ([]*weather.Forecast, error)); ok {
+		return rf(ctx, coordinates, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []repository.Coordinates, repository.ForecastOptions) []*weather.Forecast); ok {
+		r0 = rf(ctx, coordinates, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*weather.Forecast)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []repository.Coordinates, repository.ForecastOptions) error); ok {
+		r1 = rf(ctx, coordinates, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Name provides a mock function with given fields:
+func (_m *WeatherProvider) Name() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Name")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// NewWeatherProvider creates a new instance of WeatherProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWeatherProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WeatherProvider {
+	mock := &WeatherProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -1,17 +1,139 @@
 package config
 
-import "os"
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
 	DB              string
 	OpenMeteoAPIKey string
 	IsDebug         bool
+	// OpenMeteoBaseURL is the URL of the Open-Meteo API, e.g. of a self-hosted mirror. If it is empty the free API
+	// is used, or the commercial API if an API key is set.
+	OpenMeteoBaseURL string
+	// OpenMeteoArchiveBaseURL is the URL of the Open-Meteo archive API with the historical weather. If it is empty
+	// the OpenMeteoBaseURL is used if set, otherwise the free or commercial archive API.
+	OpenMeteoArchiveBaseURL string
+	// OpenMeteoTimeout is the timeout of a single Open-Meteo request, 0 uses the default.
+	OpenMeteoTimeout time.Duration
+	// OpenMeteoMaxRetries is the number of retries of failed Open-Meteo requests, 0 uses the default
+	// and a negative number disables retries.
+	OpenMeteoMaxRetries int
+	// EnrichmentBatchSize is the maximum number of locations requested with a single Open-Meteo call,
+	// 0 uses the default.
+	EnrichmentBatchSize int
+	// EnrichmentConcurrency is the maximum number of concurrent Open-Meteo calls of a GraphQL request, 0 uses the default.
+	EnrichmentConcurrency int
+	// EnrichmentTimeout is the deadline for loading the elevation and weather data of a batch, 0 uses the default.
+	EnrichmentTimeout time.Duration
+	// OpenMeteoCacheSize is the number of Open-Meteo responses cached in memory, 0 uses the default.
+	OpenMeteoCacheSize int
+	// OpenMeteoCachePostgres enables the Postgres tier of the Open-Meteo cache.
+	OpenMeteoCachePostgres bool
+	// OpenMeteoCacheLoadTimeout is the deadline of loading the responses missing in the cache, 0 uses the default.
+	OpenMeteoCacheLoadTimeout time.Duration
+	// OpenMeteoBreakerThreshold is the number of consecutive failed Open-Meteo requests that opens the circuit,
+	// 0 uses the default.
+	OpenMeteoBreakerThreshold int
+	// OpenMeteoBreakerProbeInterval is the interval Open-Meteo is probed in while the circuit is open,
+	// 0 uses the default.
+	OpenMeteoBreakerProbeInterval time.Duration
+	// WeatherProvider is the name of the default weather provider, it is used for power plants without a provider.
+	WeatherProvider string
+	// METNorwayUserAgent identifies the app at the MET Norway API, empty uses the default.
+	METNorwayUserAgent string
+	// OpenMeteoModels are the Open-Meteo weather models that are available as providers "openmeteo:<model>",
+	// e.g. "icon_seamless".
+	OpenMeteoModels []string
+	// WeatherFailover are the names of the providers tried in order by the "failover" provider,
+	// the provider is not available if it is empty.
+	WeatherFailover []string
+	// WeatherEnsemble are the providers and optional weights blended by the "ensemble" provider,
+	// e.g. "openmeteo=2,metno". The provider is not available if it is empty.
+	WeatherEnsemble string
+	// AdminAddr is the address of the admin listener with the metrics, e.g. "localhost:8081".
+	// The admin listener is not started if it is empty.
+	AdminAddr string
 }
 
 func NewConfig() *Config {
 	return &Config{
-		DB:              os.Getenv("APP_DB"),
-		OpenMeteoAPIKey: os.Getenv("OPEN_METEO_API_KEY"),
-		IsDebug:         os.Getenv("DEBUG") != "",
+		DB:                      os.Getenv("APP_DB"),
+		OpenMeteoAPIKey:         os.Getenv("OPEN_METEO_API_KEY"),
+		IsDebug:                 os.Getenv("DEBUG") != "",
+		OpenMeteoBaseURL:        os.Getenv("OPEN_METEO_BASE_URL"),
+		OpenMeteoArchiveBaseURL: os.Getenv("OPEN_METEO_ARCHIVE_BASE_URL"),
+		OpenMeteoTimeout:        getEnvDuration("OPEN_METEO_TIMEOUT"),
+		OpenMeteoMaxRetries:     getEnvInt("OPEN_METEO_MAX_RETRIES"),
+		EnrichmentBatchSize:     getEnvInt("ENRICHMENT_BATCH_SIZE"),
+		EnrichmentConcurrency:   getEnvInt("ENRICHMENT_CONCURRENCY"),
+		EnrichmentTimeout:       getEnvDuration("ENRICHMENT_TIMEOUT"),
+		OpenMeteoCacheSize:      getEnvInt("OPEN_METEO_CACHE_SIZE"),
+		OpenMeteoCachePostgres:  os.Getenv("OPEN_METEO_CACHE_POSTGRES") != "",
+
+		OpenMeteoCacheLoadTimeout: getEnvDuration("OPEN_METEO_CACHE_LOAD_TIMEOUT"),
+
+		OpenMeteoBreakerThreshold:     getEnvInt("OPEN_METEO_BREAKER_THRESHOLD"),
+		OpenMeteoBreakerProbeInterval: getEnvDuration("OPEN_METEO_BREAKER_PROBE_INTERVAL"),
+
+		WeatherProvider:    getEnv("WEATHER_PROVIDER", "openmeteo"),
+		METNorwayUserAgent: os.Getenv("METNO_USER_AGENT"),
+		OpenMeteoModels:    getEnvList("OPEN_METEO_MODELS"),
+		WeatherFailover:    getEnvList("WEATHER_FAILOVER"),
+		WeatherEnsemble:    os.Getenv("WEATHER_ENSEMBLE"),
+		AdminAddr:          os.Getenv("ADMIN_ADDR"),
+	}
+}
+
+// getEnv returns the value of the environment variable, or fallback if it is not set.
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvList returns the comma separated values of the environment variable, or nil if it is not set.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvInt returns the integer value of the environment variable, or 0 if it is not set or invalid.
+func getEnvInt(key string) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid integer in environment variable, using the default", "key", key, "value", value)
+		return 0
+	}
+	return i
+}
+
+// getEnvDuration returns the duration value (e.g. "10s") of the environment variable, or 0 if it is not set or invalid.
+func getEnvDuration(key string) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Invalid duration in environment variable, using the default", "key", key, "value", value)
+		return 0
 	}
+	return d
 }
@@ -0,0 +1,179 @@
+package dataloader
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/vikstrous/dataloadgen"
+
+	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/weather"
+)
+
+type ctxKey string
+
+const loadersKey = ctxKey("dataloaders")
+
+// batchWait is how long the loaders collect keys before a batch is sent to the api.
+const batchWait = 2 * time.Millisecond
+
+// ForecastKey identifies a weather forecast request for a single location.
+type ForecastKey struct {
+	// Provider is the name of the weather provider, empty uses the default provider.
+	Provider    string
+	Coordinates repository.Coordinates
+	Options     repository.ForecastOptions
+}
+
+// DailyWeatherKey identifies a daily weather request for a single location.
+type DailyWeatherKey struct {
+	Coordinates repository.Coordinates
+	Days        int
+}
+
+// HistoricalWeatherKey identifies a historical weather request for a single location.
+type HistoricalWeatherKey struct {
+	Coordinates repository.Coordinates
+	Options     repository.HistoricalOptions
+}
+
+// Loaders batches the elevation and weather requests of a single GraphQL operation.
+type Loaders struct {
+	ElevationLoader       *dataloadgen.Loader[repository.Coordinates, float64]
+	WeatherForecastLoader *dataloadgen.Loader[ForecastKey, *weather.Forecast]
+	DailyWeatherLoader    *dataloadgen.Loader[DailyWeatherKey, *repository.DailyWeatherResponse]
+	HistoricalLoader      *dataloadgen.Loader[HistoricalWeatherKey, *repository.HistoricalWeatherResponse]
+}
+
+// NewLoaders creates new loaders, they cache the results and should be created for every request.
+func NewLoaders(openMeteoRepo repository.OpenMeteoRepository, weatherProviders *weather.Providers, opts Options) *Loaders {
+	opts = opts.withDefaults()
+	r := &reader{openMeteoRepo: openMeteoRepo, weatherProviders: weatherProviders, opts: opts, sem: make(chan struct{}, opts.Concurrency)}
+	return &Loaders{
+		ElevationLoader:       dataloadgen.NewLoader(r.getElevations, dataloadgen.WithWait(batchWait)),
+		WeatherForecastLoader: dataloadgen.NewLoader(r.getWeatherForecasts, dataloadgen.WithWait(batchWait)),
+		DailyWeatherLoader:    dataloadgen.NewLoader(r.getDailyWeather, dataloadgen.WithWait(batchWait)),
+		HistoricalLoader:      dataloadgen.NewLoader(r.getHistoricalWeather, dataloadgen.WithWait(batchWait)),
+	}
+}
+
+// Middleware injects new loaders into the context of every request.
+func Middleware(openMeteoRepo repository.OpenMeteoRepository, weatherProviders *weather.Providers, opts Options, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithLoaders(r.Context(), NewLoaders(openMeteoRepo, weatherProviders, opts))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WithLoaders returns a copy of the context with the given loaders.
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey, loaders)
+}
+
+// For returns the loaders of the request, or nil if there are none.
+func For(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersKey).(*Loaders)
+	return loaders
+}
+
+type reader struct {
+	openMeteoRepo    repository.OpenMeteoRepository
+	weatherProviders *weather.Providers
+	opts             Options
+	// sem limits the concurrent api calls of all loaders of the request
+	sem chan struct{}
+}
+
+// forecastGroup are the forecast requests that can be sent to a provider together.
+type forecastGroup struct {
+	provider string
+	options  repository.ForecastOptions
+}
+
+// getElevations fetches the elevations of all collected locations with as few requests as possible.
+func (r *reader) getElevations(ctx context.Context, coordinates []repository.Coordinates) ([]float64, []error) {
+	indexes := make([]int, len(coordinates))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	return loadChunks(ctx, r.opts, r.sem, coordinates, chunk(indexes, r.opts.MaxBatchSize),
+		func(ctx context.Context, c []repository.Coordinates) ([]float64, error) {
+			return r.openMeteoRepo.GetElevations(ctx, c)
+		})
+}
+
+// getWeatherForecasts fetches the weather forecasts of all collected locations with at least one request
+// per provider and forecast options.
+func (r *reader) getWeatherForecasts(ctx context.Context, keys []ForecastKey) ([]*weather.Forecast, []error) {
+	// group the keys by provider and options, the providers accept multiple locations but only one set of options
+	groups := map[forecastGroup][]int{}
+	for i, key := range keys {
+		group := forecastGroup{provider: key.Provider, options: key.Options}
+		groups[group] = append(groups[group], i)
+	}
+
+	var chunks [][]int
+	for _, indexes := range groups {
+		chunks = append(chunks, chunk(indexes, r.opts.MaxBatchSize)...)
+	}
+
+	return loadChunks(ctx, r.opts, r.sem, keys, chunks,
+		func(ctx context.Context, k []ForecastKey) ([]*weather.Forecast, error) {
+			provider, err := r.weatherProviders.Get(k[0].Provider)
+			if err != nil {
+				return nil, err
+			}
+
+			coordinates := make([]repository.Coordinates, 0, len(k))
+			for _, key := range k {
+				coordinates = append(coordinates, key.Coordinates)
+			}
+			return provider.GetForecasts(ctx, coordinates, k[0].Options)
+		})
+}
+
+// getDailyWeather fetches the daily weather of all collected locations with at least one request per number of days.
+func (r *reader) getDailyWeather(ctx context.Context, keys []DailyWeatherKey) ([]*repository.DailyWeatherResponse, []error) {
+	groups := map[int][]int{}
+	for i, key := range keys {
+		groups[key.Days] = append(groups[key.Days], i)
+	}
+
+	var chunks [][]int
+	for _, indexes := range groups {
+		chunks = append(chunks, chunk(indexes, r.opts.MaxBatchSize)...)
+	}
+
+	return loadChunks(ctx, r.opts, r.sem, keys, chunks,
+		func(ctx context.Context, k []DailyWeatherKey) ([]*repository.DailyWeatherResponse, error) {
+			coordinates := make([]repository.Coordinates, 0, len(k))
+			for _, key := range k {
+				coordinates = append(coordinates, key.Coordinates)
+			}
+			return r.openMeteoRepo.GetDailyWeather(ctx, coordinates, k[0].Days)
+		})
+}
+
+// getHistoricalWeather fetches the historical weather of all collected locations with at least one request per range.
+func (r *reader) getHistoricalWeather(ctx context.Context, keys []HistoricalWeatherKey) ([]*repository.HistoricalWeatherResponse, []error) {
+	groups := map[repository.HistoricalOptions][]int{}
+	for i, key := range keys {
+		groups[key.Options] = append(groups[key.Options], i)
+	}
+
+	var chunks [][]int
+	for _, indexes := range groups {
+		chunks = append(chunks, chunk(indexes, r.opts.MaxBatchSize)...)
+	}
+
+	return loadChunks(ctx, r.opts, r.sem, keys, chunks,
+		func(ctx context.Context, k []HistoricalWeatherKey) ([]*repository.HistoricalWeatherResponse, error) {
+			coordinates := make([]repository.Coordinates, 0, len(k))
+			for _, key := range k {
+				coordinates = append(coordinates, key.Coordinates)
+			}
+			return r.openMeteoRepo.GetHistoricalWeather(ctx, coordinates, k[0].Options)
+		})
+}
@@ -0,0 +1,230 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vikstrous/dataloadgen"
+
+	"github.com/glower/kaze/mocks"
+	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/weather"
+)
+
+func TestElevationLoader(t *testing.T) {
+	t.Run("batch concurrent loads into a single request", func(t *testing.T) {
+		mockOpenMeteo := mocks.NewOpenMeteoRepository(t)
+		loaders := NewLoaders(mockOpenMeteo, nil, DefaultOptions)
+
+		coordinates := []repository.Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}
+		mockOpenMeteo.On("GetElevations", mock.Anything, mock.MatchedBy(func(c []repository.Coordinates) bool {
+			return assert.ElementsMatch(t, coordinates, c)
+		})).Return(func(_ context.Context, c []repository.Coordinates) ([]float64, error) {
+			elevations := make([]float64, len(c))
+			for i := range c {
+				elevations[i] = c[i].Latitude * 10
+			}
+			return elevations, nil
+		}).Once()
+
+		elevations := make([]float64, len(coordinates))
+		var wg sync.WaitGroup
+		for i := range coordinates {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				elevation, err := loaders.ElevationLoader.Load(context.Background(), coordinates[i])
+				assert.NoError(t, err)
+				elevations[i] = elevation
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, []float64{10, 30}, elevations)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+
+	t.Run("return the error to all callers", func(t *testing.T) {
+		mockOpenMeteo := mocks.NewOpenMeteoRepository(t)
+		loaders := NewLoaders(mockOpenMeteo, nil, DefaultOptions)
+
+		mockOpenMeteo.On("GetElevations", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+
+		_, err := loaders.ElevationLoader.Load(context.Background(), repository.Coordinates{Latitude: 1, Longitude: 2})
+		assert.ErrorIs(t, err, assert.AnError)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+
+	t.Run("return the error only to the failing location of a batch", func(t *testing.T) {
+		mockOpenMeteo := mocks.NewOpenMeteoRepository(t)
+		loaders := NewLoaders(mockOpenMeteo, nil, DefaultOptions)
+
+		good, bad := repository.Coordinates{Latitude: 1, Longitude: 2}, repository.Coordinates{Latitude: 91, Longitude: 2}
+		mockOpenMeteo.On("GetElevations", mock.Anything, mock.MatchedBy(func(c []repository.Coordinates) bool { return len(c) == 2 })).
+			Return(nil, &repository.RequestError{Err: assert.AnError}).Once()
+		mockOpenMeteo.On("GetElevations", mock.Anything, []repository.Coordinates{good}).Return([]float64{10}, nil).Once()
+		mockOpenMeteo.On("GetElevations", mock.Anything, []repository.Coordinates{bad}).Return(nil, &repository.RequestError{Err: assert.AnError}).Once()
+
+		elevations, err := loaders.ElevationLoader.LoadAll(context.Background(), []repository.Coordinates{good, bad})
+		var errs dataloadgen.ErrorSlice
+		assert.ErrorAs(t, err, &errs)
+		assert.Equal(t, 10.0, elevations[0])
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], assert.AnError)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+
+	t.Run("return the error of an unavailable api to all locations of a batch", func(t *testing.T) {
+		mockOpenMeteo := mocks.NewOpenMeteoRepository(t)
+		loaders := NewLoaders(mockOpenMeteo, nil, DefaultOptions)
+
+		mockOpenMeteo.On("GetElevations", mock.Anything, mock.Anything).Return(nil, repository.ErrCircuitOpen).Once()
+
+		_, err := loaders.ElevationLoader.LoadAll(context.Background(), []repository.Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}})
+		var errs dataloadgen.ErrorSlice
+		assert.ErrorAs(t, err, &errs)
+		assert.ErrorIs(t, errs[0], repository.ErrCircuitOpen)
+		assert.ErrorIs(t, errs[1], repository.ErrCircuitOpen)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+}
+
+func TestWeatherForecastLoader(t *testing.T) {
+	t.Run("batch loads with one request per provider and forecast options", func(t *testing.T) {
+		openMeteo, metNo := mockProvider(t, weather.OpenMeteo), mockProvider(t, weather.METNorway)
+		providers, err := weather.NewProviders(weather.OpenMeteo, openMeteo, metNo)
+		assert.NoError(t, err)
+		loaders := NewLoaders(mocks.NewOpenMeteoRepository(t), providers, DefaultOptions)
+
+		week := repository.ForecastOptions{ForecastDays: 7}
+		today := repository.ForecastOptions{ForecastDays: 1}
+		keys := []ForecastKey{
+			{Coordinates: repository.Coordinates{Latitude: 1, Longitude: 2}, Options: week},
+			{Coordinates: repository.Coordinates{Latitude: 3, Longitude: 4}, Options: week},
+			{Coordinates: repository.Coordinates{Latitude: 1, Longitude: 2}, Options: today},
+			{Provider: weather.METNorway, Coordinates: repository.Coordinates{Latitude: 5, Longitude: 6}, Options: week},
+		}
+
+		forecastsFor := func(_ context.Context, c []repository.Coordinates, opts repository.ForecastOptions) ([]*weather.Forecast, error) {
+			forecasts := make([]*weather.Forecast, len(c))
+			for i := range c {
+				forecasts[i] = &weather.Forecast{Hourly: []weather.HourlyForecast{{Temperature: c[i].Latitude * float64(opts.ForecastDays)}}}
+			}
+			return forecasts, nil
+		}
+		openMeteo.On("GetForecasts", mock.Anything, mock.Anything, week).Return(forecastsFor).Once()
+		openMeteo.On("GetForecasts", mock.Anything, mock.Anything, today).Return(forecastsFor).Once()
+		metNo.On("GetForecasts", mock.Anything, mock.Anything, week).Return(forecastsFor).Once()
+
+		temperatures := make([]float64, len(keys))
+		var wg sync.WaitGroup
+		for i := range keys {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				forecast, err := loaders.WeatherForecastLoader.Load(context.Background(), keys[i])
+				assert.NoError(t, err)
+				temperatures[i] = forecast.Hourly[0].Temperature
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, []float64{7, 21, 1, 35}, temperatures)
+		openMeteo.AssertExpectations(t)
+		metNo.AssertExpectations(t)
+	})
+
+	t.Run("fail for an unknown provider", func(t *testing.T) {
+		providers, err := weather.NewProviders(weather.OpenMeteo, mockProvider(t, weather.OpenMeteo))
+		assert.NoError(t, err)
+		loaders := NewLoaders(mocks.NewOpenMeteoRepository(t), providers, DefaultOptions)
+
+		_, err = loaders.WeatherForecastLoader.Load(context.Background(), ForecastKey{Provider: "unknown", Options: repository.ForecastOptions{ForecastDays: 1}})
+		assert.ErrorIs(t, err, weather.ErrUnknownProvider)
+	})
+}
+
+func TestDailyWeatherLoader(t *testing.T) {
+	t.Run("batch loads with one request per number of days", func(t *testing.T) {
+		mockOpenMeteo := mocks.NewOpenMeteoRepository(t)
+		loaders := NewLoaders(mockOpenMeteo, nil, DefaultOptions)
+
+		keys := []DailyWeatherKey{
+			{Coordinates: repository.Coordinates{Latitude: 1, Longitude: 2}, Days: 7},
+			{Coordinates: repository.Coordinates{Latitude: 3, Longitude: 4}, Days: 7},
+			{Coordinates: repository.Coordinates{Latitude: 1, Longitude: 2}, Days: 3},
+		}
+
+		dailyFor := func(_ context.Context, c []repository.Coordinates, days int) ([]*repository.DailyWeatherResponse, error) {
+			daily := make([]*repository.DailyWeatherResponse, len(c))
+			for i := range c {
+				daily[i] = &repository.DailyWeatherResponse{UTCOffsetSeconds: int(c[i].Latitude) * days}
+			}
+			return daily, nil
+		}
+		mockOpenMeteo.On("GetDailyWeather", mock.Anything, mock.Anything, 7).Return(dailyFor).Once()
+		mockOpenMeteo.On("GetDailyWeather", mock.Anything, mock.Anything, 3).Return(dailyFor).Once()
+
+		daily, err := loaders.DailyWeatherLoader.LoadAll(context.Background(), keys)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, daily[0].UTCOffsetSeconds)
+		assert.Equal(t, 21, daily[1].UTCOffsetSeconds)
+		assert.Equal(t, 3, daily[2].UTCOffsetSeconds)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+}
+
+func TestHistoricalLoader(t *testing.T) {
+	t.Run("batch loads with one request per range", func(t *testing.T) {
+		mockOpenMeteo := mocks.NewOpenMeteoRepository(t)
+		loaders := NewLoaders(mockOpenMeteo, nil, DefaultOptions)
+
+		january := repository.HistoricalOptions{
+			From:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+			Resolution: repository.ResolutionHourly,
+		}
+		daily := january
+		daily.Resolution = repository.ResolutionDaily
+		keys := []HistoricalWeatherKey{
+			{Coordinates: repository.Coordinates{Latitude: 1, Longitude: 2}, Options: january},
+			{Coordinates: repository.Coordinates{Latitude: 3, Longitude: 4}, Options: january},
+			{Coordinates: repository.Coordinates{Latitude: 1, Longitude: 2}, Options: daily},
+		}
+
+		historicalFor := func(_ context.Context, c []repository.Coordinates, opts repository.HistoricalOptions) ([]*repository.HistoricalWeatherResponse, error) {
+			historical := make([]*repository.HistoricalWeatherResponse, len(c))
+			for i := range c {
+				historical[i] = &repository.HistoricalWeatherResponse{Timezone: string(opts.Resolution), UTCOffsetSeconds: int(c[i].Latitude)}
+			}
+			return historical, nil
+		}
+		mockOpenMeteo.On("GetHistoricalWeather", mock.Anything, mock.Anything, january).Return(historicalFor).Once()
+		mockOpenMeteo.On("GetHistoricalWeather", mock.Anything, mock.Anything, daily).Return(historicalFor).Once()
+
+		historical, err := loaders.HistoricalLoader.LoadAll(context.Background(), keys)
+		assert.NoError(t, err)
+		assert.Equal(t, "hourly", historical[0].Timezone)
+		assert.Equal(t, 3, historical[1].UTCOffsetSeconds)
+		assert.Equal(t, "daily", historical[2].Timezone)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+}
+
+// mockProvider returns a mocked weather provider with the given name.
+func mockProvider(t *testing.T, name string) *mocks.WeatherProvider {
+	provider := mocks.NewWeatherProvider(t)
+	provider.On("Name").Return(name)
+	return provider
+}
+
+func TestFor(t *testing.T) {
+	assert.Nil(t, For(context.Background()))
+
+	loaders := NewLoaders(mocks.NewOpenMeteoRepository(t), nil, DefaultOptions)
+	assert.Same(t, loaders, For(WithLoaders(context.Background(), loaders)))
+}
@@ -0,0 +1,146 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// Options configures how the loaders request data from the api.
+type Options struct {
+	// MaxBatchSize is the maximum number of locations requested with a single api call.
+	MaxBatchSize int
+	// Concurrency is the maximum number of concurrent api calls of a GraphQL request, shared by all of its batches.
+	Concurrency int
+	// Timeout is the deadline for loading a batch, including the retries of single locations.
+	Timeout time.Duration
+}
+
+// DefaultOptions are used for options that are not set.
+var DefaultOptions = Options{
+	MaxBatchSize: 50,
+	Concurrency:  4,
+	Timeout:      10 * time.Second,
+}
+
+// withDefaults returns the options with the unset fields set to the default options.
+func (o Options) withDefaults() Options {
+	if o.MaxBatchSize <= 0 {
+		o.MaxBatchSize = DefaultOptions.MaxBatchSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultOptions.Concurrency
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultOptions.Timeout
+	}
+	return o
+}
+
+// loadChunks loads the values of the keys with one call per chunk, a chunk is a list of indexes of keys.
+// The calls run in parallel, limited by the semaphore that is shared by the batches of a request. If the call
+// of a chunk fails due to its keys, e.g. a location the api rejects, every key of the chunk is loaded on its
+// own, so a single failing key does not fail the other keys. Failures of the api fail all keys of the chunk,
+// as retrying every key would only multiply the load on an api that is unavailable or rate limited.
+// The values and errors are returned in the order of the keys.
+func loadChunks[K, V any](ctx context.Context, opts Options, sem chan struct{}, keys []K, chunks [][]int, load func(context.Context, []K) ([]V, error)) ([]V, []error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	values := make([]V, len(keys))
+	errs := make([]error, len(keys))
+
+	var retry []int
+	var mu sync.Mutex
+	chunkErrs := forEach(ctx, len(chunks), sem, func(c int) error {
+		chunk := chunks[c]
+		chunkKeys := make([]K, 0, len(chunk))
+		for _, i := range chunk {
+			chunkKeys = append(chunkKeys, keys[i])
+		}
+
+		chunkValues, err := load(ctx, chunkKeys)
+		if err != nil {
+			var requestErr *repository.RequestError
+			if len(chunk) > 1 && errors.As(err, &requestErr) && ctx.Err() == nil {
+				mu.Lock()
+				retry = append(retry, chunk...)
+				mu.Unlock()
+				slog.Warn("Batch request failed, requesting every location on its own", "error", err, "locations", len(chunk))
+				return nil
+			}
+			return err
+		}
+
+		for j, i := range chunk {
+			values[i] = chunkValues[j]
+		}
+		return nil
+	})
+	for c, err := range chunkErrs {
+		for _, i := range chunks[c] {
+			errs[i] = err
+		}
+	}
+
+	retryErrs := forEach(ctx, len(retry), sem, func(r int) error {
+		i := retry[r]
+		value, err := load(ctx, []K{keys[i]})
+		if err != nil {
+			return err
+		}
+		values[i] = value[0]
+		return nil
+	})
+	for r, err := range retryErrs {
+		errs[retry[r]] = err
+	}
+
+	return values, errs
+}
+
+// chunk splits the indexes into chunks of at most size indexes.
+func chunk(indexes []int, size int) [][]int {
+	chunks := make([][]int, 0, (len(indexes)+size-1)/size)
+	for start := 0; start < len(indexes); start += size {
+		chunks = append(chunks, indexes[start:min(start+size, len(indexes))])
+	}
+	return chunks
+}
+
+// forEach calls fn for the indexes 0 to n-1, a call runs once it acquired the semaphore, so at most its capacity
+// of calls are running at the same time, also of other forEach calls with the same semaphore.
+// Once the context is done no new calls are started and the context error is returned for the remaining indexes.
+func forEach(ctx context.Context, n int, sem chan struct{}, fn func(i int) error) []error {
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,175 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestChunk(t *testing.T) {
+	testCases := []struct {
+		name     string
+		indexes  []int
+		size     int
+		expected [][]int
+	}{
+		{name: "empty", indexes: []int{}, size: 2, expected: [][]int{}},
+		{name: "single chunk", indexes: []int{0, 1}, size: 2, expected: [][]int{{0, 1}}},
+		{name: "last chunk smaller", indexes: []int{0, 2, 4, 6, 8}, size: 2, expected: [][]int{{0, 2}, {4, 6}, {8}}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, chunk(tc.indexes, tc.size), "Failed test: "+tc.name)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	t.Run("limit the number of concurrent calls", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		results := make([]int, 10)
+
+		errs := forEach(context.Background(), len(results), make(chan struct{}, 3), func(i int) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			results[i] = i * 2
+			return nil
+		})
+
+		assert.Equal(t, int32(3), maxRunning.Load())
+		assert.Equal(t, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}, results)
+		assert.Equal(t, make([]error, 10), errs)
+	})
+
+	t.Run("stop starting calls when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls atomic.Int32
+
+		errs := forEach(ctx, 5, make(chan struct{}, 1), func(i int) error {
+			calls.Add(1)
+			cancel()
+			return nil
+		})
+
+		assert.Equal(t, int32(1), calls.Load())
+		assert.NoError(t, errs[0])
+		for _, err := range errs[1:] {
+			assert.ErrorIs(t, err, context.Canceled)
+		}
+	})
+}
+
+func TestLoadChunks(t *testing.T) {
+	opts := Options{MaxBatchSize: 2, Concurrency: 2, Timeout: time.Second}
+	keys := []int{1, 2, 3, 4, 5}
+	chunks := chunk([]int{0, 1, 2, 3, 4}, opts.MaxBatchSize)
+
+	t.Run("load chunks in parallel and keep the order of the keys", func(t *testing.T) {
+		var calls atomic.Int32
+		values, errs := loadChunks(context.Background(), opts, make(chan struct{}, opts.Concurrency), keys, chunks, func(_ context.Context, k []int) ([]string, error) {
+			calls.Add(1)
+			assert.LessOrEqual(t, len(k), opts.MaxBatchSize)
+			values := make([]string, len(k))
+			for i := range k {
+				values[i] = fmt.Sprint(k[i] * 10)
+			}
+			return values, nil
+		})
+
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Equal(t, []string{"10", "20", "30", "40", "50"}, values)
+		assert.Equal(t, make([]error, 5), errs)
+	})
+
+	t.Run("retry the keys of a chunk that failed due to a key on their own", func(t *testing.T) {
+		values, errs := loadChunks(context.Background(), opts, make(chan struct{}, opts.Concurrency), keys, chunks, func(_ context.Context, k []int) ([]string, error) {
+			for _, key := range k {
+				if key == 4 {
+					return nil, &repository.RequestError{Err: assert.AnError}
+				}
+			}
+			values := make([]string, len(k))
+			for i := range k {
+				values[i] = fmt.Sprint(k[i])
+			}
+			return values, nil
+		})
+
+		assert.Equal(t, []string{"1", "2", "3", "", "5"}, values)
+		assert.NoError(t, errs[2])
+		assert.ErrorIs(t, errs[3], assert.AnError)
+		assert.NoError(t, errs[4])
+	})
+
+	t.Run("fail the keys of a chunk without retrying them if the api fails", func(t *testing.T) {
+		var calls atomic.Int32
+		values, errs := loadChunks(context.Background(), opts, make(chan struct{}, opts.Concurrency), keys, chunks, func(_ context.Context, k []int) ([]string, error) {
+			calls.Add(1)
+			if k[0] == 3 {
+				return nil, assert.AnError
+			}
+			values := make([]string, len(k))
+			for i := range k {
+				values[i] = fmt.Sprint(k[i])
+			}
+			return values, nil
+		})
+
+		assert.Equal(t, int32(3), calls.Load(), "Expected no retries of single keys")
+		assert.Equal(t, []string{"1", "2", "", "", "5"}, values)
+		assert.ErrorIs(t, errs[2], assert.AnError)
+		assert.ErrorIs(t, errs[3], assert.AnError)
+		assert.NoError(t, errs[4])
+	})
+
+	t.Run("share the concurrency with the other batches of the request", func(t *testing.T) {
+		sem := make(chan struct{}, 1)
+		var running, maxRunning atomic.Int32
+		load := func(_ context.Context, k []int) ([]string, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return make([]string, len(k)), nil
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			loadChunks(context.Background(), opts, sem, keys, chunks, load)
+		}()
+		loadChunks(context.Background(), opts, sem, keys, chunks, load)
+		<-done
+
+		assert.Equal(t, int32(1), maxRunning.Load())
+	})
+
+	t.Run("fail all keys after the deadline", func(t *testing.T) {
+		opts := Options{MaxBatchSize: 5, Concurrency: 1, Timeout: 10 * time.Millisecond}
+		_, errs := loadChunks(context.Background(), opts, make(chan struct{}, opts.Concurrency), keys, chunk([]int{0, 1, 2, 3, 4}, opts.MaxBatchSize), func(ctx context.Context, k []int) ([]string, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		for _, err := range errs {
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		}
+	})
+}
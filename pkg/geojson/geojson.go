@@ -0,0 +1,182 @@
+// Package geojson encodes and decodes the GeoJSON objects (RFC 7946) used to exchange power plants with GIS tools
+// and to select power plants by area.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	TypePoint             = "Point"
+	TypePolygon           = "Polygon"
+	TypeMultiPolygon      = "MultiPolygon"
+	TypeFeature           = "Feature"
+	TypeFeatureCollection = "FeatureCollection"
+)
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature, the properties are decoded by the user of the feature.
+type Feature struct {
+	Type string `json:"type"`
+	// ID is the identifier of the feature, a string or a number, nil if the feature has none
+	ID         any             `json:"id,omitempty"`
+	Geometry   *Geometry       `json:"geometry"`
+	Properties json.RawMessage `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry object, the coordinates are decoded according to the type.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Position is a longitude and a latitude in degrees, in this order as in GeoJSON.
+type Position [2]float64
+
+// Longitude returns the longitude of the position in degrees.
+func (p Position) Longitude() float64 { return p[0] }
+
+// Latitude returns the latitude of the position in degrees.
+func (p Position) Latitude() float64 { return p[1] }
+
+// Polygon is a list of closed linear rings, the first ring is the exterior ring and the others are holes.
+type Polygon [][]Position
+
+// NewFeatureCollection returns a feature collection of the features.
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: TypeFeatureCollection, Features: features}
+}
+
+// Validate checks the types of the collection and its features.
+func (c FeatureCollection) Validate() error {
+	if c.Type != TypeFeatureCollection {
+		return fmt.Errorf("GeoJSON must be a %s, got %q", TypeFeatureCollection, c.Type)
+	}
+	for i, feature := range c.Features {
+		if feature.Type != TypeFeature {
+			return fmt.Errorf("element %d of the features must be a %s, got %q", i, TypeFeature, feature.Type)
+		}
+	}
+	return nil
+}
+
+// FeatureID returns the ID of the feature as a string, empty if the feature has no ID.
+func (f Feature) FeatureID() string {
+	switch id := f.ID.(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	case json.Number:
+		return id.String()
+	default:
+		return ""
+	}
+}
+
+// NewPoint returns a Point geometry at the position.
+func NewPoint(position Position) Geometry {
+	coordinates, _ := json.Marshal(position)
+	return Geometry{Type: TypePoint, Coordinates: coordinates}
+}
+
+// Point decodes a Point geometry into its position, an altitude is ignored.
+func (g Geometry) Point() (Position, error) {
+	if g.Type != TypePoint {
+		return Position{}, fmt.Errorf("geometry must be a %s, got %q", TypePoint, g.Type)
+	}
+
+	var coordinates []float64
+	if err := decodePositions(g.Coordinates, &coordinates); err != nil {
+		return Position{}, err
+	}
+	if len(coordinates) < 2 {
+		return Position{}, fmt.Errorf("position must have a longitude and a latitude, got %d values", len(coordinates))
+	}
+
+	position := Position{coordinates[0], coordinates[1]}
+	if err := position.validate(); err != nil {
+		return Position{}, err
+	}
+	return position, nil
+}
+
+// Polygons decodes a Polygon or MultiPolygon geometry into its polygons and validates their rings.
+func (g Geometry) Polygons() ([]Polygon, error) {
+	var polygons []Polygon
+	switch g.Type {
+	case TypePolygon:
+		var polygon Polygon
+		if err := decodePositions(g.Coordinates, &polygon); err != nil {
+			return nil, err
+		}
+		polygons = []Polygon{polygon}
+	case TypeMultiPolygon:
+		if err := decodePositions(g.Coordinates, &polygons); err != nil {
+			return nil, err
+		}
+		if len(polygons) == 0 {
+			return nil, fmt.Errorf("multi polygon has no polygons")
+		}
+	default:
+		return nil, fmt.Errorf("geometry must be a %s or %s, got %q", TypePolygon, TypeMultiPolygon, g.Type)
+	}
+
+	for _, polygon := range polygons {
+		if err := polygon.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return polygons, nil
+}
+
+// Validate checks that the polygon has an exterior ring and that all rings are closed, have at least
+// four positions and lie on the earth.
+func (p Polygon) Validate() error {
+	if len(p) == 0 {
+		return fmt.Errorf("polygon has no exterior ring")
+	}
+	for _, ring := range p {
+		if len(ring) < 4 {
+			return fmt.Errorf("ring of a polygon must have at least 4 positions, got %d", len(ring))
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return fmt.Errorf("ring of a polygon must be closed, the first position %v differs from the last position %v", ring[0], ring[len(ring)-1])
+		}
+		for _, position := range ring {
+			if err := position.validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks that the position is on the earth.
+func (p Position) validate() error {
+	if p.Latitude() < -90 || p.Latitude() > 90 || p.Longitude() < -180 || p.Longitude() > 180 {
+		return fmt.Errorf("invalid position [%g, %g], longitude must be between -180 and 180 and latitude between -90 and 90", p.Longitude(), p.Latitude())
+	}
+	return nil
+}
+
+// decodePositions decodes the coordinates of a geometry.
+func decodePositions(coordinates json.RawMessage, v any) error {
+	if len(coordinates) == 0 {
+		return fmt.Errorf("geometry has no coordinates")
+	}
+	if err := json.Unmarshal(coordinates, v); err != nil {
+		return fmt.Errorf("invalid coordinates of the geometry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeometryPolygons(t *testing.T) {
+	square := `[[139.5, 35.5], [140.0, 35.5], [140.0, 36.0], [139.5, 36.0], [139.5, 35.5]]`
+	hole := `[[139.7, 35.7], [139.8, 35.7], [139.8, 35.8], [139.7, 35.7]]`
+
+	testCases := []struct {
+		name          string
+		geometry      Geometry
+		expectedRings []int
+		expectError   string
+	}{
+		{
+			name:          "Polygon with a hole",
+			geometry:      Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[` + square + `, ` + hole + `]`)},
+			expectedRings: []int{2},
+		},
+		{
+			name:          "MultiPolygon",
+			geometry:      Geometry{Type: TypeMultiPolygon, Coordinates: json.RawMessage(`[[` + square + `], [` + hole + `]]`)},
+			expectedRings: []int{1, 1},
+		},
+		{
+			name:        "Point",
+			geometry:    Geometry{Type: "Point", Coordinates: json.RawMessage(`[139.5, 35.5]`)},
+			expectError: "must be a Polygon or MultiPolygon",
+		},
+		{
+			name:        "Without coordinates",
+			geometry:    Geometry{Type: TypePolygon},
+			expectError: "no coordinates",
+		},
+		{
+			name:        "Empty MultiPolygon",
+			geometry:    Geometry{Type: TypeMultiPolygon, Coordinates: json.RawMessage(`[]`)},
+			expectError: "no polygons",
+		},
+		{
+			name:        "Open ring",
+			geometry:    Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[139.5, 35.5], [140.0, 35.5], [140.0, 36.0], [139.5, 36.0]]]`)},
+			expectError: "must be closed",
+		},
+		{
+			name:        "Ring with 3 positions",
+			geometry:    Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[139.5, 35.5], [140.0, 35.5], [139.5, 35.5]]]`)},
+			expectError: "at least 4 positions",
+		},
+		{
+			name:        "Latitude first",
+			geometry:    Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[35.5, 139.5], [35.5, 140.0], [36.0, 140.0], [35.5, 139.5]]]`)},
+			expectError: "invalid position",
+		},
+		{
+			name:        "Positions are not numbers",
+			geometry:    Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[["a", "b"]]]`)},
+			expectError: "invalid coordinates",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			polygons, err := tc.geometry.Polygons()
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError, "Failed test: "+tc.name)
+				return
+			}
+			assert.NoError(t, err, "Failed test: "+tc.name)
+
+			rings := make([]int, 0, len(polygons))
+			for _, polygon := range polygons {
+				rings = append(rings, len(polygon))
+			}
+			assert.Equal(t, tc.expectedRings, rings, "Failed test: "+tc.name)
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	p := Position{139.69, 35.68}
+	assert.Equal(t, 139.69, p.Longitude())
+	assert.Equal(t, 35.68, p.Latitude())
+}
+
+func TestFeatureCollection(t *testing.T) {
+	var collection FeatureCollection
+	err := json.Unmarshal([]byte(`{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "id": "7", "geometry": {"type": "Point", "coordinates": [139.6380, 35.4437, 12.5]}, "properties": {"name": "Yokohama"}},
+		{"type": "Feature", "id": 8, "geometry": null, "properties": null},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [139.6]}, "properties": {}}
+	]}`), &collection)
+	assert.NoError(t, err)
+	assert.NoError(t, collection.Validate())
+	assert.Len(t, collection.Features, 3)
+
+	assert.Equal(t, "7", collection.Features[0].FeatureID())
+	assert.Equal(t, "8", collection.Features[1].FeatureID())
+	assert.Equal(t, "", collection.Features[2].FeatureID())
+
+	position, err := collection.Features[0].Geometry.Point()
+	assert.NoError(t, err)
+	assert.Equal(t, Position{139.6380, 35.4437}, position)
+
+	_, err = collection.Features[2].Geometry.Point()
+	assert.ErrorContains(t, err, "must have a longitude and a latitude")
+
+	assert.Error(t, FeatureCollection{Type: TypeFeature}.Validate())
+	assert.Error(t, FeatureCollection{Type: TypeFeatureCollection, Features: []Feature{{Type: TypePoint}}}.Validate())
+}
+
+func TestNewFeatureCollection(t *testing.T) {
+	point := NewPoint(Position{139.69, 35.68})
+	collection := NewFeatureCollection([]Feature{{Type: TypeFeature, ID: "1", Geometry: &point, Properties: json.RawMessage(`{"name":"Tokyo"}`)}})
+
+	data, err := json.Marshal(collection)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "id": "1", "geometry": {"type": "Point", "coordinates": [139.69, 35.68]}, "properties": {"name": "Tokyo"}}
+	]}`, string(data))
+
+	data, err = json.Marshal(NewFeatureCollection(nil))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, string(data))
+
+	_, err = Geometry{Type: TypePolygon}.Point()
+	assert.ErrorContains(t, err, "must be a Point")
+}
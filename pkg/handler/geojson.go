@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/service"
+)
+
+// geoJSONContentType is the media type of GeoJSON (RFC 7946).
+const geoJSONContentType = "application/geo+json"
+
+// exportGeoJSON serves the power plants matching the filter of the query parameters as a GeoJSON FeatureCollection.
+func (s *Server) exportGeoJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collection, err := s.powerPlantService.ExportPowerPlants(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Failed to export power plants", "error", err)
+		http.Error(w, "failed to export power plants", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", geoJSONContentType)
+	if err := json.NewEncoder(w).Encode(collection); err != nil {
+		slog.Error("Failed to write power plants", "error", err)
+	}
+}
+
+// parseFilter translates the query parameters into a filter of the power plants, e.g.
+// ?type=WIND,SOLAR&status=OPERATING&minCapacityMw=100&bbox=128,30,146,46. The parameters are parsed into the
+// filter input of the GraphQL API and converted the same way. The bounding box is
+// minLongitude,minLatitude,maxLongitude,maxLatitude as the bbox of GeoJSON.
+func parseFilter(query url.Values) (repository.PowerPlantFilter, error) {
+	var input model.PowerPlantFilter
+	if v := query.Get("name"); v != "" {
+		input.NameContains = &v
+	}
+	if v := query.Get("search"); v != "" {
+		input.Search = &v
+	}
+
+	var err error
+	includeDeleted := false
+	if v := query.Get("includeDeleted"); v != "" {
+		if includeDeleted, err = strconv.ParseBool(v); err != nil {
+			return repository.PowerPlantFilter{}, fmt.Errorf("includeDeleted must be true or false, got %q", v)
+		}
+	}
+	for _, v := range splitValues(query["type"]) {
+		var plantType model.PlantType
+		if err := plantType.UnmarshalGQL(strings.ToUpper(v)); err != nil {
+			return repository.PowerPlantFilter{}, fmt.Errorf("type: %w", err)
+		}
+		input.PlantTypes = append(input.PlantTypes, plantType)
+	}
+	for _, v := range splitValues(query["status"]) {
+		var status model.PlantStatus
+		if err := status.UnmarshalGQL(strings.ToUpper(v)); err != nil {
+			return repository.PowerPlantFilter{}, fmt.Errorf("status: %w", err)
+		}
+		input.Statuses = append(input.Statuses, status)
+	}
+	if input.MinCapacityMw, err = parseFloat(query, "minCapacityMw"); err != nil {
+		return repository.PowerPlantFilter{}, err
+	}
+	if input.MaxCapacityMw, err = parseFloat(query, "maxCapacityMw"); err != nil {
+		return repository.PowerPlantFilter{}, err
+	}
+	if v := query.Get("bbox"); v != "" {
+		if input.BoundingBox, err = parseBoundingBox(v); err != nil {
+			return repository.PowerPlantFilter{}, err
+		}
+	}
+	if v := query.Get("createdAfter"); v != "" {
+		createdAfter, err := model.UnmarshalDateTime(v)
+		if err != nil {
+			return repository.PowerPlantFilter{}, fmt.Errorf("createdAfter: %w", err)
+		}
+		input.CreatedAfter = &createdAfter
+	}
+
+	return repository.NewPowerPlantFilter(&input, includeDeleted), nil
+}
+
+// parseBoundingBox parses a bounding box in the order of the bbox of GeoJSON,
+// minLongitude,minLatitude,maxLongitude,maxLatitude.
+func parseBoundingBox(v string) (*model.BoundingBoxInput, error) {
+	bboxErr := fmt.Errorf("bbox must be minLongitude,minLatitude,maxLongitude,maxLatitude, got %q", v)
+	values := strings.Split(v, ",")
+	if len(values) != 4 {
+		return nil, bboxErr
+	}
+
+	var bbox [4]float64
+	for i, value := range values {
+		var err error
+		if bbox[i], err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			return nil, bboxErr
+		}
+	}
+	return &model.BoundingBoxInput{MinLongitude: bbox[0], MinLatitude: bbox[1], MaxLongitude: bbox[2], MaxLatitude: bbox[3]}, nil
+}
+
+// splitValues splits repeated and comma separated query parameter values.
+func splitValues(values []string) []string {
+	var split []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				split = append(split, v)
+			}
+		}
+	}
+	return split
+}
+
+// parseFloat parses an optional number of the query parameters, nil if it is not set.
+func parseFloat(query url.Values, name string) (*float64, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number, got %q", name, v)
+	}
+	return &f, nil
+}
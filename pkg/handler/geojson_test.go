@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/mocks"
+	"github.com/glower/kaze/pkg/dataloader"
+	"github.com/glower/kaze/pkg/geojson"
+	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/service"
+)
+
+func TestParseFilter(t *testing.T) {
+	minCapacity := 100.0
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		query       string
+		expected    repository.PowerPlantFilter
+		expectError string
+	}{
+		{
+			name:     "Without parameters",
+			expected: repository.PowerPlantFilter{},
+		},
+		{
+			name:  "All parameters",
+			query: "name=farm&search=hokaido&type=wind,Solar&type=HYDRO&status=OPERATING&minCapacityMw=100&bbox=128,30,146,46&createdAfter=2024-01-01T09:00:00%2B09:00&includeDeleted=true",
+			expected: repository.PowerPlantFilter{
+				IncludeDeleted: true,
+				NameContains:   "farm",
+				Search:         "hokaido",
+				PlantTypes:     []model.PlantType{model.PlantTypeWind, model.PlantTypeSolar, model.PlantTypeHydro},
+				Statuses:       []model.PlantStatus{model.PlantStatusOperating},
+				MinCapacityMw:  &minCapacity,
+				BoundingBox:    &repository.BoundingBox{MinLatitude: 30, MinLongitude: 128, MaxLatitude: 46, MaxLongitude: 146},
+				CreatedAfter:   &createdAfter,
+			},
+		},
+		{
+			name:        "Unknown plant type",
+			query:       "type=FUSION",
+			expectError: "FUSION is not a valid PlantType",
+		},
+		{
+			name:        "Capacity is not a number",
+			query:       "maxCapacityMw=large",
+			expectError: "maxCapacityMw must be a number",
+		},
+		{
+			name:        "Bounding box with three values",
+			query:       "bbox=128,30,146",
+			expectError: "bbox must be",
+		},
+		{
+			name:        "Time without an offset",
+			query:       "createdAfter=2024-01-01",
+			expectError: "RFC 3339",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			assert.NoError(t, err)
+
+			filter, err := parseFilter(query)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError, "Failed test: "+tc.name)
+				return
+			}
+			assert.NoError(t, err, "Failed test: "+tc.name)
+			assert.Equal(t, tc.expected, filter, "Failed test: "+tc.name)
+		})
+	}
+}
+
+func TestExportGeoJSON(t *testing.T) {
+	setup := func(t *testing.T) (*mocks.PowerPlantService, http.Handler) {
+		mockService := mocks.NewPowerPlantService(t)
+		return mockService, NewServer(mockService, nil, nil, dataloader.Options{}).SetupRoutes()
+	}
+
+	t.Run("serve the feature collection", func(t *testing.T) {
+		mockService, mux := setup(t)
+
+		point := geojson.NewPoint(geojson.Position{140.0245, 40.2039})
+		collection := geojson.NewFeatureCollection([]geojson.Feature{{Type: geojson.TypeFeature, ID: "2", Geometry: &point, Properties: json.RawMessage(`{"name":"Noshiro Wind Farm"}`)}})
+		filter := repository.PowerPlantFilter{PlantTypes: []model.PlantType{model.PlantTypeWind}}
+		mockService.On("ExportPowerPlants", mock.Anything, filter).Return(&collection, nil).Once()
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geojson?type=WIND", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/geo+json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "id": "2", "geometry": {"type": "Point", "coordinates": [140.0245, 40.2039]}, "properties": {"name": "Noshiro Wind Farm"}}
+		]}`, rec.Body.String())
+	})
+
+	t.Run("fail due to an invalid parameter", func(t *testing.T) {
+		_, mux := setup(t)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geojson?status=RUNNING", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "RUNNING is not a valid PlantStatus")
+	})
+
+	t.Run("fail due to an invalid filter", func(t *testing.T) {
+		mockService, mux := setup(t)
+
+		mockService.On("ExportPowerPlants", mock.Anything, mock.Anything).Return(nil, service.ErrValidation).Once()
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geojson?minCapacityMw=10&maxCapacityMw=1", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("fail due to database error", func(t *testing.T) {
+		mockService, mux := setup(t)
+
+		mockService.On("ExportPowerPlants", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geojson", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotContains(t, rec.Body.String(), assert.AnError.Error())
+	})
+
+	t.Run("reject other methods", func(t *testing.T) {
+		_, mux := setup(t)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/geojson", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
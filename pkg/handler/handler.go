@@ -1,24 +1,35 @@
 package handler
 
 import (
+	"expvar"
 	"net/http"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 
 	"github.com/glower/kaze/graph"
+	"github.com/glower/kaze/pkg/dataloader"
+	"github.com/glower/kaze/pkg/repository"
 	"github.com/glower/kaze/pkg/service"
+	"github.com/glower/kaze/pkg/weather"
 )
 
 // Server struct represents the GraphQL server
 type Server struct {
 	powerPlantService service.PowerPlantService
+	openMeteoRepo     repository.OpenMeteoRepository
+	weatherProviders  *weather.Providers
+	loaderOpts        dataloader.Options
 }
 
 // NewServer creates a new GraphQL server
-func NewServer(powerPlantService service.PowerPlantService) *Server {
+func NewServer(powerPlantService service.PowerPlantService, openMeteoRepo repository.OpenMeteoRepository,
+	weatherProviders *weather.Providers, loaderOpts dataloader.Options) *Server {
 	return &Server{
 		powerPlantService: powerPlantService,
+		openMeteoRepo:     openMeteoRepo,
+		weatherProviders:  weatherProviders,
+		loaderOpts:        loaderOpts,
 	}
 }
 
@@ -32,10 +43,25 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 
 	// Setup GraphQL handler
 	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
-	mux.Handle("/graphql", srv)
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+	mux.Handle("/graphql", dataloader.Middleware(s.openMeteoRepo, s.weatherProviders, s.loaderOpts, srv))
+
+	// Export the power plants as a GeoJSON FeatureCollection for GIS tools
+	mux.HandleFunc("/geojson", s.exportGeoJSON)
 
 	// Setup the GraphQL playground handler
 	mux.Handle("/", playground.Handler("GraphQL playground", "/graphql"))
 
 	return mux
 }
+
+// SetupAdminRoutes returns the routes of the admin listener, they are not authenticated and must not be
+// reachable from the public network.
+func SetupAdminRoutes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// Expose the metrics, e.g. the hits and misses of the Open-Meteo cache
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return mux
+}
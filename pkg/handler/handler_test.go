@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/mocks"
+	"github.com/glower/kaze/pkg/dataloader"
+)
+
+func TestDebugVars(t *testing.T) {
+	t.Run("not exposed by the public routes", func(t *testing.T) {
+		mux := NewServer(mocks.NewPowerPlantService(t), nil, nil, dataloader.Options{}).SetupRoutes()
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+		assert.NotContains(t, rec.Body.String(), "memstats")
+	})
+
+	t.Run("exposed by the admin routes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		SetupAdminRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "memstats")
+	})
+}
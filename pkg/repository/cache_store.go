@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CacheItem is a cached Open-Meteo response.
+type CacheItem struct {
+	// Value is the JSON encoded response.
+	Value []byte `db:"value"`
+	// ExpiresAt is the time the response has to be fetched again.
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// CacheStore is a persistent tier of the Open-Meteo cache, shared by all instances of the app.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=CacheStore --filename=cache_store.go --output=../../mocks/
+type CacheStore interface {
+	// Get returns the item, also if it is expired, or nil if there is none.
+	Get(ctx context.Context, key string) (*CacheItem, error)
+	Set(ctx context.Context, key string, item CacheItem) error
+}
+
+const (
+	// CacheRetention is how long expired items are kept as fallback if the API is not available,
+	// older items are purged from the cache table.
+	CacheRetention = 7 * 24 * time.Hour
+	// cachePurgeInterval is the minimum interval between two purges of the cache table by an instance.
+	cachePurgeInterval = time.Hour
+)
+
+type postgresCacheStore struct {
+	db  *sqlx.DB
+	now func() time.Time
+
+	purgeMu   sync.Mutex
+	lastPurge time.Time
+}
+
+// NewPostgresCacheStore creates a cache store backed by the open_meteo_cache table. Items that expired more than
+// CacheRetention ago are purged when items are stored.
+func NewPostgresCacheStore(db *sqlx.DB) CacheStore {
+	return &postgresCacheStore{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+// Get retrieves an item from the cache table, expired items are kept as fallback if the API is not available.
+func (s *postgresCacheStore) Get(ctx context.Context, key string) (*CacheItem, error) {
+	var item CacheItem
+	query := `SELECT value, expires_at FROM open_meteo_cache WHERE key = $1`
+	if err := s.db.GetContext(ctx, &item, query, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting cached item: %w", err)
+	}
+
+	// the column is stored without time zone in UTC
+	item.ExpiresAt = item.ExpiresAt.UTC()
+	return &item, nil
+}
+
+// Set inserts or replaces an item in the cache table.
+func (s *postgresCacheStore) Set(ctx context.Context, key string, item CacheItem) error {
+	slog.Debug("Storing cached item", "key", key, "expiresAt", item.ExpiresAt)
+
+	query := `INSERT INTO open_meteo_cache (key, value, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`
+	if _, err := s.db.ExecContext(ctx, query, key, item.Value, item.ExpiresAt.UTC()); err != nil {
+		return fmt.Errorf("error storing cached item: %w", err)
+	}
+
+	if s.purgeDue() {
+		s.purge(ctx)
+	}
+	return nil
+}
+
+// purgeDue reports whether the cache table has to be purged, at most once per cachePurgeInterval.
+func (s *postgresCacheStore) purgeDue() bool {
+	s.purgeMu.Lock()
+	defer s.purgeMu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastPurge) < cachePurgeInterval {
+		return false
+	}
+	s.lastPurge = now
+	return true
+}
+
+// purge deletes the items that expired more than CacheRetention ago, a failed purge is retried with the next one.
+func (s *postgresCacheStore) purge(ctx context.Context) {
+	query := `DELETE FROM open_meteo_cache WHERE expires_at < $1`
+	result, err := s.db.ExecContext(ctx, query, s.now().Add(-CacheRetention).UTC())
+	if err != nil {
+		slog.Warn("Can't purge expired items from the cache table", "error", err)
+		return
+	}
+
+	if purged, err := result.RowsAffected(); err == nil && purged > 0 {
+		slog.Info("Purged expired items from the cache table", "count", purged)
+	}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresCacheStorePurgeDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := &postgresCacheStore{now: func() time.Time { return now }}
+
+	assert.True(t, store.purgeDue(), "the first item stored purges the table")
+	assert.False(t, store.purgeDue())
+
+	now = now.Add(cachePurgeInterval - time.Second)
+	assert.False(t, store.purgeDue())
+
+	now = now.Add(time.Second)
+	assert.True(t, store.purgeDue())
+}
@@ -2,24 +2,57 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/glower/kaze/graph/model"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound is returned when no power plant with the given ID exists.
+	ErrNotFound = errors.New("power plant not found")
+	// ErrConflict is returned when the power plant is already in the state requested by the operation.
+	ErrConflict = errors.New("power plant is already in the requested state")
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2 --name=PowerPlantRepository --filename=power_plant_repository.go --output=../../mocks/
 type PowerPlantRepository interface {
 	Create(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error)
-	GetByID(ctx context.Context, id string) (*model.PowerPlant, error)
-	Update(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error)
-	List(ctx context.Context, offset, limit int) ([]model.PowerPlant, int, error)
+	GetByID(ctx context.Context, id string, includeDeleted bool) (*model.PowerPlant, error)
+	Update(ctx context.Context, patch *model.PowerPlantPatch) error
+	List(ctx context.Context, offset, limit int, filter PowerPlantFilter, sort PowerPlantSort) ([]model.PowerPlant, int, error)
+	ListPage(ctx context.Context, filter PowerPlantFilter, sort PowerPlantSort, page Page) (*PowerPlantPage, error)
+	Count(ctx context.Context, filter PowerPlantFilter) (int, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	ListWithoutElevation(ctx context.Context, limit int) ([]model.PowerPlant, error)
+	UpdateElevation(ctx context.Context, id string, elevation float64) error
+	// ListNear fetches the power plants within a radius around a point with their distances, the nearest first.
+	ListNear(ctx context.Context, opts NearOptions) ([]PowerPlantDistance, error)
+	// ListWithin fetches the power plants inside any of the polygons of the options.
+	ListWithin(ctx context.Context, opts WithinOptions) ([]model.PowerPlant, error)
 }
 
+// powerPlantColumns are the columns selected into a model.PowerPlant.
+const powerPlantColumns = "id, name, latitude, longitude, elevation, created_at, deleted_at, weather_provider, " +
+	"plant_type, capacity_mw, commissioning_date, status, operator"
+
+// notDeleted is the condition excluding soft deleted power plants.
+const notDeleted = "deleted_at IS NULL"
+
 type powerPlantRepo struct {
 	db *sqlx.DB
+
+	postGISMu sync.Mutex
+	// postGIS caches whether the PostGIS extension is installed, nil until it is checked
+	postGIS *bool
 }
 
 func NewPowerPlantRepository(db *sqlx.DB) PowerPlantRepository {
@@ -32,11 +65,17 @@ func NewPowerPlantRepository(db *sqlx.DB) PowerPlantRepository {
 func (r *powerPlantRepo) Create(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
 	slog.Debug("Inserting new power plant", "name", plant.Name)
 
-	query := `INSERT INTO power_plants (name, latitude, longitude) VALUES ($1, $2, $3) RETURNING id`
-	row := r.db.QueryRowContext(ctx, query, plant.Name, plant.Latitude, plant.Longitude)
+	if plant.Status == "" {
+		plant.Status = model.PlantStatusOperating
+	}
+
+	query := `INSERT INTO power_plants (name, latitude, longitude, elevation, weather_provider, plant_type, capacity_mw, commissioning_date, status, operator)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`
+	row := r.db.QueryRowContext(ctx, query, plant.Name, plant.Latitude, plant.Longitude, plant.Elevation, plant.WeatherProvider,
+		plant.PlantType, plant.CapacityMw, plant.CommissioningDate, plant.Status, plant.Operator)
 
 	var id int
-	if err := row.Scan(&id); err != nil {
+	if err := row.Scan(&id, &plant.CreatedAt); err != nil {
 		slog.Error("Failed to insert power plant", "error", err)
 		return nil, err
 	}
@@ -45,13 +84,19 @@ func (r *powerPlantRepo) Create(ctx context.Context, plant *model.PowerPlant) (*
 	return plant, nil
 }
 
-// GetByID retrieves a power plant by its ID.
-func (r *powerPlantRepo) GetByID(ctx context.Context, id string) (*model.PowerPlant, error) {
-	slog.Debug("Retrieving power plant", "id", id)
+// GetByID retrieves a power plant by its ID, deleted power plants are only returned if includeDeleted is set.
+func (r *powerPlantRepo) GetByID(ctx context.Context, id string, includeDeleted bool) (*model.PowerPlant, error) {
+	slog.Debug("Retrieving power plant", "id", id, "includeDeleted", includeDeleted)
 
 	var plant model.PowerPlant
-	query := `SELECT id, name, latitude, longitude FROM power_plants WHERE id = $1`
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plants WHERE id = $1`
+	if !includeDeleted {
+		query += ` AND ` + notDeleted
+	}
 	if err := r.db.GetContext(ctx, &plant, query, id); err != nil {
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
 		slog.Error("Failed to get power plant by ID", "error", err)
 		return nil, err
 	}
@@ -59,62 +104,244 @@ func (r *powerPlantRepo) GetByID(ctx context.Context, id string) (*model.PowerPl
 	return &plant, nil
 }
 
-// Update modifies an existing power plant.
-func (r *powerPlantRepo) Update(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
-	slog.Debug("Updating power plant", "id", plant.ID)
+// Update modifies the fields of an existing power plant that are set in the patch.
+// Changing the coordinates without providing an elevation clears the stored elevation.
+func (r *powerPlantRepo) Update(ctx context.Context, patch *model.PowerPlantPatch) error {
+	slog.Debug("Updating power plant", "id", patch.ID)
 
 	setParts := []string{}
 	params := map[string]interface{}{}
 
-	if plant.Name != "" {
+	if patch.Name != nil {
 		setParts = append(setParts, "name = :name")
-		params["name"] = plant.Name
+		params["name"] = *patch.Name
 	}
-	if plant.Latitude != 0 {
+	if patch.Latitude != nil {
 		setParts = append(setParts, "latitude = :latitude")
-		params["latitude"] = plant.Latitude
+		params["latitude"] = *patch.Latitude
 	}
-	if plant.Longitude != 0 {
+	if patch.Longitude != nil {
 		setParts = append(setParts, "longitude = :longitude")
-		params["longitude"] = plant.Longitude
+		params["longitude"] = *patch.Longitude
+	}
+	if patch.WeatherProvider != nil {
+		// an empty name resets the power plant to the default provider
+		setParts = append(setParts, "weather_provider = NULLIF(:weather_provider, '')")
+		params["weather_provider"] = *patch.WeatherProvider
+	}
+	// the details are cleared if they are set to nil
+	if plantType, ok := patch.PlantType.ValueOK(); ok {
+		setParts = append(setParts, "plant_type = :plant_type")
+		params["plant_type"] = plantType
+	}
+	if capacity, ok := patch.CapacityMw.ValueOK(); ok {
+		setParts = append(setParts, "capacity_mw = :capacity_mw")
+		params["capacity_mw"] = capacity
+	}
+	if date, ok := patch.CommissioningDate.ValueOK(); ok {
+		setParts = append(setParts, "commissioning_date = :commissioning_date")
+		params["commissioning_date"] = date
+	}
+	if patch.Status != nil {
+		setParts = append(setParts, "status = :status")
+		params["status"] = *patch.Status
+	}
+	if patch.Operator != nil {
+		// an empty name removes the operator
+		setParts = append(setParts, "operator = NULLIF(:operator, '')")
+		params["operator"] = *patch.Operator
+	}
+	if patch.Elevation != nil {
+		setParts = append(setParts, "elevation = :elevation")
+		params["elevation"] = *patch.Elevation
+	} else if patch.Latitude != nil || patch.Longitude != nil {
+		setParts = append(setParts, "elevation = NULL")
 	}
 
 	if len(setParts) == 0 {
-		return plant, nil // No update needed
+		return nil // No update needed
 	}
 
-	query := fmt.Sprintf("UPDATE power_plants SET %s WHERE id = :id", strings.Join(setParts, ", "))
-	params["id"] = plant.ID
+	query := fmt.Sprintf("UPDATE power_plants SET %s WHERE id = :id AND %s", strings.Join(setParts, ", "), notDeleted)
+	params["id"] = patch.ID
 
-	_, err := r.db.NamedExecContext(ctx, query, params)
+	result, err := r.db.NamedExecContext(ctx, query, params)
 	if err != nil {
+		if isNotFound(err) {
+			return ErrNotFound
+		}
 		slog.Error("Failed to update power plant", "error", err)
-		return nil, fmt.Errorf("error updating power plant: %w", err)
+		return fmt.Errorf("error updating power plant: %w", err)
 	}
 
-	return plant, nil
-}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting number of updated power plants: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
 
-// List fetches a list of power plants with pagination.
-func (r *powerPlantRepo) List(ctx context.Context, offset, limit int) ([]model.PowerPlant, int, error) {
-	slog.Debug("Listing power plants", "offset", offset, "limit", limit)
+	return nil
+}
 
-	var powerPlants []model.PowerPlant
-	var total int
+// List fetches a page of the power plants matching the filter in the order of the sort,
+// the total is the number of all matching power plants.
+func (r *powerPlantRepo) List(ctx context.Context, offset, limit int, filter PowerPlantFilter, sort PowerPlantSort) ([]model.PowerPlant, int, error) {
+	slog.Debug("Listing power plants", "offset", offset, "limit", limit, "filter", filter, "sort", sort)
 
-	countQuery := "SELECT COUNT(*) FROM power_plants"
-	if err := r.db.GetContext(ctx, &total, countQuery); err != nil {
-		slog.Error("Error getting total number of power plants", "error", err)
-		return nil, 0, fmt.Errorf("error getting total number of power plants: %w", err)
+	total, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	slog.Debug("total number of all power plants", "total", countQuery)
+	slog.Debug("total number of all power plants", "total", total)
 
-	listQuery := `SELECT id, name, latitude, longitude FROM power_plants ORDER BY id LIMIT $1 OFFSET $2`
-	if err := r.db.SelectContext(ctx, &powerPlants, listQuery, limit, offset); err != nil {
+	var powerPlants []model.PowerPlant
+	var args queryArgs
+	listQuery := `SELECT ` + powerPlantColumns + ` FROM power_plants` + filter.where(&args) + sort.orderBy(&args) +
+		` LIMIT ` + args.add(limit) + ` OFFSET ` + args.add(offset)
+	if err := r.db.SelectContext(ctx, &powerPlants, listQuery, args...); err != nil {
 		slog.Error("Error querying power plants", "error", err)
 		return nil, 0, fmt.Errorf("error querying power plants: %w", err)
 	}
 
 	return powerPlants, total, nil
 }
+
+// ListPage fetches the power plants matching the filter after the cursor of the page in the order of the sort,
+// or before the cursor if the page is backward. The power plants of the page are always in the order of the sort.
+func (r *powerPlantRepo) ListPage(ctx context.Context, filter PowerPlantFilter, sort PowerPlantSort, page Page) (*PowerPlantPage, error) {
+	slog.Debug("Listing a page of power plants", "filter", filter, "sort", sort, "page", page)
+
+	var args queryArgs
+	expression := sort.expression(&args)
+	sortKey := expression
+	if sortKey == "" {
+		sortKey = "NULL"
+	}
+
+	conditions := filter.conditions(&args)
+	if page.Cursor != nil {
+		conditions = append(conditions, sort.keyset(&args, expression, *page.Cursor, page.Backward))
+	}
+
+	// one more power plant than the limit tells if there are more pages
+	var rows []pageRow
+	query := `SELECT ` + powerPlantColumns + `, ` + sortKey + ` AS sort_key FROM power_plants` + whereClause(conditions) +
+		sort.orderClause(expression, page.Backward) + ` LIMIT ` + args.add(page.Limit+1)
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		slog.Error("Error querying a page of power plants", "error", err)
+		return nil, fmt.Errorf("error querying a page of power plants: %w", err)
+	}
+
+	result := &PowerPlantPage{HasMore: len(rows) > page.Limit}
+	rows = rows[:min(len(rows), page.Limit)]
+	if page.Backward {
+		slices.Reverse(rows)
+	}
+	for _, row := range rows {
+		result.PowerPlants = append(result.PowerPlants, row.PowerPlant)
+		result.Cursors = append(result.Cursors, sort.cursorOf(row))
+	}
+
+	return result, nil
+}
+
+// Count returns the number of power plants matching the filter.
+func (r *powerPlantRepo) Count(ctx context.Context, filter PowerPlantFilter) (int, error) {
+	var total int
+	var args queryArgs
+	countQuery := "SELECT COUNT(*) FROM power_plants" + filter.where(&args)
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		slog.Error("Error getting total number of power plants", "error", err)
+		return 0, fmt.Errorf("error getting total number of power plants: %w", err)
+	}
+
+	return total, nil
+}
+
+// ListWithoutElevation fetches power plants without a stored elevation.
+func (r *powerPlantRepo) ListWithoutElevation(ctx context.Context, limit int) ([]model.PowerPlant, error) {
+	slog.Debug("Listing power plants without elevation", "limit", limit)
+
+	var powerPlants []model.PowerPlant
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plants WHERE elevation IS NULL AND ` + notDeleted + ` ORDER BY id LIMIT $1`
+	if err := r.db.SelectContext(ctx, &powerPlants, query, limit); err != nil {
+		slog.Error("Error querying power plants without elevation", "error", err)
+		return nil, fmt.Errorf("error querying power plants without elevation: %w", err)
+	}
+
+	return powerPlants, nil
+}
+
+// UpdateElevation stores the elevation of a power plant.
+func (r *powerPlantRepo) UpdateElevation(ctx context.Context, id string, elevation float64) error {
+	slog.Debug("Updating elevation of power plant", "id", id, "elevation", elevation)
+
+	query := `UPDATE power_plants SET elevation = $1 WHERE id = $2`
+	if _, err := r.db.ExecContext(ctx, query, elevation, id); err != nil {
+		slog.Error("Failed to update elevation of power plant", "error", err)
+		return fmt.Errorf("error updating elevation of power plant: %w", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a power plant by setting its deletion time.
+func (r *powerPlantRepo) Delete(ctx context.Context, id string) error {
+	slog.Debug("Deleting power plant", "id", id)
+
+	query := `UPDATE power_plants SET deleted_at = (NOW() AT TIME ZONE 'utc') WHERE id = $1 AND ` + notDeleted
+	return r.execOne(ctx, query, id)
+}
+
+// Restore restores a soft deleted power plant.
+func (r *powerPlantRepo) Restore(ctx context.Context, id string) error {
+	slog.Debug("Restoring power plant", "id", id)
+
+	query := `UPDATE power_plants SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+	return r.execOne(ctx, query, id)
+}
+
+// execOne executes a query that is expected to modify exactly one power plant.
+// It returns ErrNotFound if the power plant does not exist and ErrConflict if it was not modified.
+func (r *powerPlantRepo) execOne(ctx context.Context, query string, id string) error {
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		if isNotFound(err) {
+			return ErrNotFound
+		}
+		slog.Error("Failed to modify power plant", "error", err)
+		return fmt.Errorf("error modifying power plant: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting number of modified power plants: %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM power_plants WHERE id = $1)`, id); err != nil {
+		return fmt.Errorf("error checking if power plant exists: %w", err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+
+	return ErrConflict
+}
+
+// isNotFound reports whether the error means that no power plant matches the ID,
+// either because there is no such row or because the ID is not a valid integer.
+func isNotFound(err error) bool {
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "22P02" // invalid_text_representation
+}
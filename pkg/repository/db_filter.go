@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/lib/pq"
+)
+
+// PowerPlantFilter selects the power plants of a list, the conditions that are set must all match.
+type PowerPlantFilter struct {
+	// IncludeDeleted also lists soft deleted power plants
+	IncludeDeleted bool
+	// NameContains is a case insensitive part of the name
+	NameContains string
+	// Search is a fuzzy search term matched against the words of the name with trigram similarity,
+	// e.g. "hokaido" matches "Hokkaido Wind Farm"
+	Search string
+	// PlantTypes matches power plants of any of the types
+	PlantTypes []model.PlantType
+	// Statuses matches power plants with any of the statuses
+	Statuses []model.PlantStatus
+	// MinCapacityMw is the minimum nameplate capacity in MW, inclusive
+	MinCapacityMw *float64
+	// MaxCapacityMw is the maximum nameplate capacity in MW, inclusive
+	MaxCapacityMw *float64
+	// BoundingBox matches power plants within the box
+	BoundingBox *BoundingBox
+	// CreatedAfter matches power plants created after the time
+	CreatedAfter *time.Time
+}
+
+// BoundingBox is an area between two latitudes and two longitudes in degrees. The box crosses the antimeridian
+// if the minimum longitude is greater than the maximum longitude.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// NewPowerPlantFilter converts the filter input of the API, the filter argument of the GraphQL fields or the
+// query parameters of the GeoJSON export, into a filter. A nil input matches all power plants, the filter is
+// validated by the service.
+func NewPowerPlantFilter(input *model.PowerPlantFilter, includeDeleted bool) PowerPlantFilter {
+	f := PowerPlantFilter{IncludeDeleted: includeDeleted}
+	if input == nil {
+		return f
+	}
+
+	if input.NameContains != nil {
+		f.NameContains = *input.NameContains
+	}
+	if input.Search != nil {
+		f.Search = *input.Search
+	}
+	f.PlantTypes = input.PlantTypes
+	f.Statuses = input.Statuses
+	f.MinCapacityMw = input.MinCapacityMw
+	f.MaxCapacityMw = input.MaxCapacityMw
+	if box := input.BoundingBox; box != nil {
+		f.BoundingBox = &BoundingBox{
+			MinLatitude:  box.MinLatitude,
+			MinLongitude: box.MinLongitude,
+			MaxLatitude:  box.MaxLatitude,
+			MaxLongitude: box.MaxLongitude,
+		}
+	}
+	if input.CreatedAfter != nil {
+		createdAfter := input.CreatedAfter.UTC()
+		f.CreatedAfter = &createdAfter
+	}
+	return f
+}
+
+// SortField is the field a list of power plants is sorted by.
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByName      SortField = "name"
+	SortByCapacity  SortField = "capacity"
+	SortByCreatedAt SortField = "createdAt"
+	SortByDistance  SortField = "distance"
+)
+
+// PowerPlantSort is the order of a list of power plants, power plants with the same value are sorted by ID.
+// The zero value sorts by ID.
+type PowerPlantSort struct {
+	Field      SortField
+	Descending bool
+	// From is the point the distance is measured from, required for SortByDistance
+	From *Coordinates
+}
+
+// earthRadiusKm is the mean radius of the earth used for distances.
+const earthRadiusKm = 6371.0
+
+// Validate checks that the ranges of the filter are not empty and the bounding box is valid.
+func (f PowerPlantFilter) Validate() error {
+	if f.MinCapacityMw != nil && f.MaxCapacityMw != nil && *f.MinCapacityMw > *f.MaxCapacityMw {
+		return fmt.Errorf("minimum capacity %g MW is greater than the maximum capacity %g MW", *f.MinCapacityMw, *f.MaxCapacityMw)
+	}
+	if box := f.BoundingBox; box != nil {
+		if box.MinLatitude < -90 || box.MaxLatitude > 90 || box.MinLatitude > box.MaxLatitude {
+			return fmt.Errorf("latitudes of the bounding box must be between -90 and 90 with the minimum first, got %g and %g", box.MinLatitude, box.MaxLatitude)
+		}
+		if box.MinLongitude < -180 || box.MinLongitude > 180 || box.MaxLongitude < -180 || box.MaxLongitude > 180 {
+			return fmt.Errorf("longitudes of the bounding box must be between -180 and 180, got %g and %g", box.MinLongitude, box.MaxLongitude)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the sort field is known and the distance has a point to be measured from.
+func (s PowerPlantSort) Validate() error {
+	switch s.Field {
+	case "", SortByID, SortByName, SortByCapacity, SortByCreatedAt:
+	case SortByDistance:
+		if s.From == nil {
+			return fmt.Errorf("sorting by distance requires a point")
+		}
+		if err := s.From.validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown sort field %q", s.Field)
+	}
+	return nil
+}
+
+// validate checks that the coordinates are on the earth.
+func (c Coordinates) validate() error {
+	if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+		return fmt.Errorf("invalid coordinates %g, %g", c.Latitude, c.Longitude)
+	}
+	return nil
+}
+
+// queryArgs are the arguments of a query with positional parameters.
+type queryArgs []any
+
+// add appends the argument and returns its parameter.
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// where translates the filter into a WHERE clause, the values are added to the arguments.
+func (f PowerPlantFilter) where(args *queryArgs) string {
+	return whereClause(f.conditions(args))
+}
+
+// conditions translates the filter into SQL conditions, the values are added to the arguments.
+func (f PowerPlantFilter) conditions(args *queryArgs) []string {
+	conditions := []string{}
+	if !f.IncludeDeleted {
+		conditions = append(conditions, notDeleted)
+	}
+	if f.NameContains != "" {
+		conditions = append(conditions, "name ILIKE "+args.add("%"+escapeLike(f.NameContains)+"%"))
+	}
+	if f.Search != "" {
+		conditions = append(conditions, args.add(f.Search)+" <% name")
+	}
+	if len(f.PlantTypes) > 0 {
+		types := make([]string, 0, len(f.PlantTypes))
+		for _, t := range f.PlantTypes {
+			types = append(types, string(t))
+		}
+		conditions = append(conditions, "plant_type = ANY("+args.add(pq.Array(types))+")")
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, 0, len(f.Statuses))
+		for _, s := range f.Statuses {
+			statuses = append(statuses, string(s))
+		}
+		conditions = append(conditions, "status = ANY("+args.add(pq.Array(statuses))+")")
+	}
+	if f.MinCapacityMw != nil {
+		conditions = append(conditions, "capacity_mw >= "+args.add(*f.MinCapacityMw))
+	}
+	if f.MaxCapacityMw != nil {
+		conditions = append(conditions, "capacity_mw <= "+args.add(*f.MaxCapacityMw))
+	}
+	if box := f.BoundingBox; box != nil {
+		conditions = append(conditions, "latitude BETWEEN "+args.add(box.MinLatitude)+" AND "+args.add(box.MaxLatitude))
+		if box.MinLongitude <= box.MaxLongitude {
+			conditions = append(conditions, "longitude BETWEEN "+args.add(box.MinLongitude)+" AND "+args.add(box.MaxLongitude))
+		} else {
+			conditions = append(conditions, "(longitude >= "+args.add(box.MinLongitude)+" OR longitude <= "+args.add(box.MaxLongitude)+")")
+		}
+	}
+	if f.CreatedAfter != nil {
+		// created_at is stored in UTC without a time zone
+		conditions = append(conditions, "created_at > ("+args.add(*f.CreatedAfter)+"::timestamptz AT TIME ZONE 'utc')")
+	}
+
+	return conditions
+}
+
+// whereClause joins the conditions into a WHERE clause.
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// orderBy translates the sort into an ORDER BY clause, power plants without a value are sorted last.
+func (s PowerPlantSort) orderBy(args *queryArgs) string {
+	return s.orderClause(s.expression(args), false)
+}
+
+// expression is the SQL expression of the sort field, empty if the power plants are sorted by ID.
+func (s PowerPlantSort) expression(args *queryArgs) string {
+	switch s.Field {
+	case SortByName:
+		return "name"
+	case SortByCapacity:
+		return "capacity_mw"
+	case SortByCreatedAt:
+		return "created_at"
+	case SortByDistance:
+		return distanceKm(args, *s.From)
+	default:
+		return ""
+	}
+}
+
+// distanceKm is the SQL expression of the great-circle distance of a power plant to the point in km (haversine formula).
+func distanceKm(args *queryArgs, from Coordinates) string {
+	lat, lon := args.add(from.Latitude), args.add(from.Longitude)
+	// LEAST guards ASIN against rounding errors above 1 for antipodal points
+	return fmt.Sprintf("(%g * 2 * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(latitude - %s) / 2), 2) + "+
+		"COS(RADIANS(%s)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - %s) / 2), 2)))))",
+		earthRadiusKm, lat, lat, lon)
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPowerPlantFilter(t *testing.T) {
+	assert.Equal(t, PowerPlantFilter{}, NewPowerPlantFilter(nil, false))
+	assert.Equal(t, PowerPlantFilter{IncludeDeleted: true}, NewPowerPlantFilter(nil, true))
+
+	search := "hokaido"
+	maxCapacity := 500.0
+	createdAfter := time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	filter := NewPowerPlantFilter(&model.PowerPlantFilter{
+		Search:        &search,
+		PlantTypes:    []model.PlantType{model.PlantTypeWind},
+		MaxCapacityMw: &maxCapacity,
+		BoundingBox:   &model.BoundingBoxInput{MinLatitude: -10, MinLongitude: 170, MaxLatitude: 10, MaxLongitude: -170},
+		CreatedAfter:  &createdAfter,
+	}, false)
+
+	utc := createdAfter.UTC()
+	assert.Equal(t, PowerPlantFilter{
+		Search:        "hokaido",
+		PlantTypes:    []model.PlantType{model.PlantTypeWind},
+		MaxCapacityMw: &maxCapacity,
+		BoundingBox:   &BoundingBox{MinLatitude: -10, MinLongitude: 170, MaxLatitude: 10, MaxLongitude: -170},
+		CreatedAfter:  &utc,
+	}, filter)
+}
+
+func TestPowerPlantFilterWhere(t *testing.T) {
+	minCapacity, maxCapacity := 10.0, 500.0
+	createdAfter := time.Date(2024, 1, 1, 9, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	testCases := []struct {
+		name          string
+		filter        PowerPlantFilter
+		expectedWhere string
+		expectedArgs  queryArgs
+	}{
+		{
+			name:          "Without conditions",
+			filter:        PowerPlantFilter{},
+			expectedWhere: " WHERE deleted_at IS NULL",
+		},
+		{
+			name:          "Including deleted power plants",
+			filter:        PowerPlantFilter{IncludeDeleted: true},
+			expectedWhere: "",
+		},
+		{
+			name:          "Name with wildcards",
+			filter:        PowerPlantFilter{IncludeDeleted: true, NameContains: "100%_solar"},
+			expectedWhere: " WHERE name ILIKE $1",
+			expectedArgs:  queryArgs{`%100\%\_solar%`},
+		},
+		{
+			name: "All conditions",
+			filter: PowerPlantFilter{
+				Search:        "hokaido",
+				PlantTypes:    []model.PlantType{model.PlantTypeWind, model.PlantTypeSolar},
+				Statuses:      []model.PlantStatus{model.PlantStatusOperating},
+				MinCapacityMw: &minCapacity,
+				MaxCapacityMw: &maxCapacity,
+				BoundingBox:   &BoundingBox{MinLatitude: 30, MinLongitude: 130, MaxLatitude: 45, MaxLongitude: 145},
+				CreatedAfter:  &createdAfter,
+			},
+			expectedWhere: " WHERE deleted_at IS NULL AND $1 <% name AND plant_type = ANY($2) AND status = ANY($3)" +
+				" AND capacity_mw >= $4 AND capacity_mw <= $5 AND latitude BETWEEN $6 AND $7 AND longitude BETWEEN $8 AND $9" +
+				" AND created_at > ($10::timestamptz AT TIME ZONE 'utc')",
+			expectedArgs: queryArgs{
+				"hokaido", pq.Array([]string{"WIND", "SOLAR"}), pq.Array([]string{"OPERATING"}),
+				10.0, 500.0, 30.0, 45.0, 130.0, 145.0, createdAfter,
+			},
+		},
+		{
+			name:          "Bounding box across the antimeridian",
+			filter:        PowerPlantFilter{IncludeDeleted: true, BoundingBox: &BoundingBox{MinLatitude: -20, MinLongitude: 170, MaxLatitude: -10, MaxLongitude: -170}},
+			expectedWhere: " WHERE latitude BETWEEN $1 AND $2 AND (longitude >= $3 OR longitude <= $4)",
+			expectedArgs:  queryArgs{-20.0, -10.0, 170.0, -170.0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args queryArgs
+			assert.Equal(t, tc.expectedWhere, tc.filter.where(&args), "Failed test: "+tc.name)
+			assert.Equal(t, tc.expectedArgs, args, "Failed test: "+tc.name)
+		})
+	}
+}
+
+func TestPowerPlantSortOrderBy(t *testing.T) {
+	args := queryArgs{"filter"}
+	assert.Equal(t, " ORDER BY id ASC", PowerPlantSort{}.orderBy(&args))
+	assert.Equal(t, " ORDER BY capacity_mw DESC NULLS LAST, id DESC", PowerPlantSort{Field: SortByCapacity, Descending: true}.orderBy(&args))
+
+	orderBy := PowerPlantSort{Field: SortByDistance, From: &Coordinates{Latitude: 35.68, Longitude: 139.69}}.orderBy(&args)
+	assert.Contains(t, orderBy, "RADIANS(latitude - $2)")
+	assert.Contains(t, orderBy, "RADIANS(longitude - $3)")
+	assert.Contains(t, orderBy, "ASC NULLS LAST, id ASC")
+	assert.Equal(t, queryArgs{"filter", 35.68, 139.69}, args, "Expected the point after the arguments of the filter")
+}
+
+func TestPowerPlantFilterValidate(t *testing.T) {
+	assert.NoError(t, PowerPlantFilter{}.Validate())
+	assert.NoError(t, PowerPlantFilter{BoundingBox: &BoundingBox{MinLatitude: -10, MinLongitude: 170, MaxLatitude: 10, MaxLongitude: -170}}.Validate())
+
+	minCapacity, maxCapacity := 500.0, 10.0
+	assert.Error(t, PowerPlantFilter{MinCapacityMw: &minCapacity, MaxCapacityMw: &maxCapacity}.Validate())
+	assert.Error(t, PowerPlantFilter{BoundingBox: &BoundingBox{MinLatitude: 10, MaxLatitude: -10}}.Validate())
+	assert.Error(t, PowerPlantFilter{BoundingBox: &BoundingBox{MaxLatitude: 10, MinLongitude: -200}}.Validate())
+}
+
+func TestPowerPlantSortValidate(t *testing.T) {
+	assert.NoError(t, PowerPlantSort{}.Validate())
+	assert.NoError(t, PowerPlantSort{Field: SortByDistance, From: &Coordinates{Latitude: 35.68, Longitude: 139.69}}.Validate())
+	assert.Error(t, PowerPlantSort{Field: SortByDistance}.Validate())
+	assert.Error(t, PowerPlantSort{Field: SortByDistance, From: &Coordinates{Latitude: 91}}.Validate())
+	assert.Error(t, PowerPlantSort{Field: "elevation"}.Validate())
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/geojson"
+)
+
+const (
+	// DefaultNearLimit is the number of power plants near a point if no limit is requested.
+	DefaultNearLimit = 10
+	// MaxNearLimit is the maximum number of power plants near a point.
+	MaxNearLimit = 100
+	// MaxNearRadiusKm is the maximum radius around a point in km.
+	MaxNearRadiusKm = 1000
+	// DefaultWithinLimit is the number of power plants inside a region if no limit is requested.
+	DefaultWithinLimit = 100
+	// MaxWithinLimit is the maximum number of power plants inside a region.
+	MaxWithinLimit = 1000
+)
+
+// NearOptions selects the power plants within a radius around a point, the nearest first.
+type NearOptions struct {
+	Center   Coordinates
+	RadiusKm float64
+	// Limit is the maximum number of power plants
+	Limit int
+}
+
+// WithinOptions selects the power plants inside any of the polygons, ordered by ID.
+type WithinOptions struct {
+	Polygons []geojson.Polygon
+	// Limit is the maximum number of power plants
+	Limit int
+}
+
+// Validate checks the limit, the polygons are validated when they are decoded from GeoJSON.
+func (o WithinOptions) Validate() error {
+	if o.Limit < 1 || o.Limit > MaxWithinLimit {
+		return fmt.Errorf("number of power plants must be between 1 and %d, got %d", MaxWithinLimit, o.Limit)
+	}
+	return nil
+}
+
+// PowerPlantDistance is a power plant with its great-circle distance to a point.
+type PowerPlantDistance struct {
+	model.PowerPlant
+	DistanceKm float64 `db:"distance_km"`
+}
+
+// Validate checks the center, the radius and the limit.
+func (o NearOptions) Validate() error {
+	if err := o.Center.validate(); err != nil {
+		return err
+	}
+	if o.RadiusKm <= 0 || o.RadiusKm > MaxNearRadiusKm {
+		return fmt.Errorf("radius must be greater than 0 and at most %d km, got %g", MaxNearRadiusKm, o.RadiusKm)
+	}
+	if o.Limit < 1 || o.Limit > MaxNearLimit {
+		return fmt.Errorf("number of power plants must be between 1 and %d, got %d", MaxNearLimit, o.Limit)
+	}
+	return nil
+}
+
+// The PostGIS expressions of the location of a power plant, they must match the indexes of the migrations.
+const (
+	plantGeometry  = "ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)"
+	plantGeography = "(" + plantGeometry + "::geography)"
+	plantPoint     = "point(longitude, latitude)"
+)
+
+// ListNear fetches the power plants within the radius around the center, the nearest first. With PostGIS the
+// distances are measured on the WGS 84 spheroid, without it on a sphere with a bounding box prefilter.
+func (r *powerPlantRepo) ListNear(ctx context.Context, opts NearOptions) ([]PowerPlantDistance, error) {
+	slog.Debug("Listing power plants near a point", "opts", opts)
+
+	var args queryArgs
+	var query string
+	if r.hasPostGIS(ctx) {
+		query = nearPostGISQuery(&args, opts)
+	} else {
+		query = nearQuery(&args, opts)
+	}
+
+	var plants []PowerPlantDistance
+	if err := r.db.SelectContext(ctx, &plants, query, args...); err != nil {
+		slog.Error("Error querying power plants near a point", "error", err)
+		return nil, fmt.Errorf("error querying power plants near a point: %w", err)
+	}
+
+	return plants, nil
+}
+
+// ListWithin fetches the power plants inside any of the polygons of the options, ordered by ID. The edges of
+// the polygons are straight lines between the longitudes and latitudes as in GeoJSON.
+func (r *powerPlantRepo) ListWithin(ctx context.Context, opts WithinOptions) ([]model.PowerPlant, error) {
+	slog.Debug("Listing power plants within polygons", "polygons", len(opts.Polygons), "limit", opts.Limit)
+
+	var args queryArgs
+	var query string
+	if r.hasPostGIS(ctx) {
+		var err error
+		if query, err = withinPostGISQuery(&args, opts); err != nil {
+			return nil, err
+		}
+	} else {
+		query = withinQuery(&args, opts)
+	}
+
+	var plants []model.PowerPlant
+	if err := r.db.SelectContext(ctx, &plants, query, args...); err != nil {
+		slog.Error("Error querying power plants within polygons", "error", err)
+		return nil, fmt.Errorf("error querying power plants within polygons: %w", err)
+	}
+
+	return plants, nil
+}
+
+// hasPostGIS reports whether the PostGIS extension is installed. The result is cached once the check succeeds.
+func (r *powerPlantRepo) hasPostGIS(ctx context.Context) bool {
+	r.postGISMu.Lock()
+	defer r.postGISMu.Unlock()
+
+	if r.postGIS != nil {
+		return *r.postGIS
+	}
+
+	var installed bool
+	query := `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')`
+	if err := r.db.GetContext(ctx, &installed, query); err != nil {
+		slog.Error("Failed to check if PostGIS is installed", "error", err)
+		return false
+	}
+
+	slog.Info("Checked geospatial support of the database", "postgis", installed)
+	r.postGIS = &installed
+	return installed
+}
+
+// nearPostGISQuery is the query of the power plants near a point with the geography index of PostGIS.
+func nearPostGISQuery(args *queryArgs, opts NearOptions) string {
+	center := fmt.Sprintf("ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography", args.add(opts.Center.Longitude), args.add(opts.Center.Latitude))
+	return `SELECT ` + powerPlantColumns + `, ST_Distance(` + plantGeography + `, ` + center + `) / 1000 AS distance_km` +
+		` FROM power_plants WHERE ` + notDeleted + ` AND ST_DWithin(` + plantGeography + `, ` + center + `, ` + args.add(opts.RadiusKm*1000) + `)` +
+		` ORDER BY distance_km, id LIMIT ` + args.add(opts.Limit)
+}
+
+// nearQuery is the query of the power plants near a point without PostGIS. The bounding box of the circle
+// selects the candidates with the point index, the haversine distance the power plants within the circle.
+func nearQuery(args *queryArgs, opts NearOptions) string {
+	candidates := `SELECT ` + powerPlantColumns + `, ` + distanceKm(args, opts.Center) + ` AS distance_km` +
+		` FROM power_plants WHERE ` + notDeleted + ` AND ` + boxCondition(args, boundingBoxOf(opts.Center, opts.RadiusKm))
+	return `SELECT * FROM (` + candidates + `) AS candidates WHERE distance_km <= ` + args.add(opts.RadiusKm) +
+		` ORDER BY distance_km, id LIMIT ` + args.add(opts.Limit)
+}
+
+// boundingBoxOf returns the smallest bounding box containing the circle around the center. The box spans all
+// longitudes if the circle contains a pole and crosses the antimeridian if the circle does.
+func boundingBoxOf(center Coordinates, radiusKm float64) BoundingBox {
+	// angular radius of the circle in degrees
+	radius := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := BoundingBox{
+		MinLatitude:  center.Latitude - radius,
+		MinLongitude: -180,
+		MaxLatitude:  center.Latitude + radius,
+		MaxLongitude: 180,
+	}
+	if box.MinLatitude <= -90 || box.MaxLatitude >= 90 {
+		box.MinLatitude, box.MaxLatitude = max(box.MinLatitude, -90), min(box.MaxLatitude, 90)
+		return box
+	}
+
+	// the meridians tangent to the circle
+	sinRadius := math.Sin(radius * math.Pi / 180)
+	cosLatitude := math.Cos(center.Latitude * math.Pi / 180)
+	delta := math.Asin(min(1, sinRadius/cosLatitude)) * 180 / math.Pi
+	box.MinLongitude, box.MaxLongitude = center.Longitude-delta, center.Longitude+delta
+	if box.MinLongitude < -180 {
+		box.MinLongitude += 360
+	}
+	if box.MaxLongitude > 180 {
+		box.MaxLongitude -= 360
+	}
+	return box
+}
+
+// boxCondition is the SQL condition of the power plants within the bounding box that uses the point index.
+// A box crossing the antimeridian is split in two.
+func boxCondition(args *queryArgs, box BoundingBox) string {
+	within := func(minLongitude, maxLongitude float64) string {
+		return fmt.Sprintf("%s <@ box(point(%s, %s), point(%s, %s))", plantPoint,
+			args.add(minLongitude), args.add(box.MinLatitude), args.add(maxLongitude), args.add(box.MaxLatitude))
+	}
+
+	if box.MinLongitude <= box.MaxLongitude {
+		return within(box.MinLongitude, box.MaxLongitude)
+	}
+	return "(" + within(box.MinLongitude, 180) + " OR " + within(-180, box.MaxLongitude) + ")"
+}
+
+// withinPostGISQuery is the query of the power plants inside the polygons with the geometry index of PostGIS.
+func withinPostGISQuery(args *queryArgs, opts WithinOptions) (string, error) {
+	coordinates, err := json.Marshal(opts.Polygons)
+	if err != nil {
+		return "", fmt.Errorf("error encoding polygons: %w", err)
+	}
+	geometry, err := json.Marshal(geojson.Geometry{Type: geojson.TypeMultiPolygon, Coordinates: coordinates})
+	if err != nil {
+		return "", fmt.Errorf("error encoding polygons: %w", err)
+	}
+
+	area := "ST_SetSRID(ST_GeomFromGeoJSON(" + args.add(string(geometry)) + "), 4326)"
+	return `SELECT ` + powerPlantColumns + ` FROM power_plants WHERE ` + notDeleted +
+		` AND ST_Intersects(` + plantGeometry + `, ` + area + `) ORDER BY id LIMIT ` + args.add(opts.Limit), nil
+}
+
+// withinQuery is the query of the power plants inside the polygons with the polygon type of PostgreSQL and
+// the point index. A power plant is inside a polygon if it is inside its exterior ring and not inside a hole.
+func withinQuery(args *queryArgs, opts WithinOptions) string {
+	conditions := make([]string, 0, len(opts.Polygons))
+	for _, polygon := range opts.Polygons {
+		rings := make([]string, 0, len(polygon))
+		for i, ring := range polygon {
+			condition := plantPoint + " <@ " + args.add(polygonText(ring)) + "::polygon"
+			if i > 0 {
+				condition = "NOT " + condition
+			}
+			rings = append(rings, condition)
+		}
+		conditions = append(conditions, "("+strings.Join(rings, " AND ")+")")
+	}
+
+	return `SELECT ` + powerPlantColumns + ` FROM power_plants WHERE ` + notDeleted +
+		` AND (` + strings.Join(conditions, " OR ") + `) ORDER BY id LIMIT ` + args.add(opts.Limit)
+}
+
+// polygonText is the text representation of a ring as a PostgreSQL polygon, e.g. "((139.7,35.6),(139.8,35.6),...)".
+func polygonText(ring []geojson.Position) string {
+	points := make([]string, 0, len(ring))
+	for _, position := range ring {
+		points = append(points, "("+strconv.FormatFloat(position.Longitude(), 'f', -1, 64)+","+strconv.FormatFloat(position.Latitude(), 'f', -1, 64)+")")
+	}
+	return "(" + strings.Join(points, ",") + ")"
+}
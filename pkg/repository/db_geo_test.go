@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/geojson"
+)
+
+func TestNearOptionsValidate(t *testing.T) {
+	tokyo := Coordinates{Latitude: 35.68, Longitude: 139.69}
+
+	assert.NoError(t, NearOptions{Center: tokyo, RadiusKm: 50, Limit: 10}.Validate())
+	assert.NoError(t, NearOptions{Center: tokyo, RadiusKm: MaxNearRadiusKm, Limit: MaxNearLimit}.Validate())
+
+	assert.ErrorContains(t, NearOptions{Center: Coordinates{Latitude: 139.69, Longitude: 35.68}, RadiusKm: 50, Limit: 10}.Validate(), "invalid coordinates")
+	assert.ErrorContains(t, NearOptions{Center: tokyo, RadiusKm: 0, Limit: 10}.Validate(), "radius")
+	assert.ErrorContains(t, NearOptions{Center: tokyo, RadiusKm: MaxNearRadiusKm + 1, Limit: 10}.Validate(), "radius")
+	assert.ErrorContains(t, NearOptions{Center: tokyo, RadiusKm: 50, Limit: 0}.Validate(), "number of power plants")
+	assert.ErrorContains(t, NearOptions{Center: tokyo, RadiusKm: 50, Limit: MaxNearLimit + 1}.Validate(), "number of power plants")
+}
+
+func TestBoundingBoxOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		center   Coordinates
+		radiusKm float64
+		expected BoundingBox
+	}{
+		{
+			// 111.19 km are one degree of latitude, and of longitude at the equator
+			name:     "Equator",
+			center:   Coordinates{Latitude: 0, Longitude: 10},
+			radiusKm: 111.19,
+			expected: BoundingBox{MinLatitude: -1, MinLongitude: 9, MaxLatitude: 1, MaxLongitude: 11},
+		},
+		{
+			// the degrees of longitude are half as long at 60°
+			name:     "Wider at high latitudes",
+			center:   Coordinates{Latitude: 60, Longitude: 10},
+			radiusKm: 111.19,
+			expected: BoundingBox{MinLatitude: 59, MinLongitude: 7.9997, MaxLatitude: 61, MaxLongitude: 12.0003},
+		},
+		{
+			name:     "Across the antimeridian",
+			center:   Coordinates{Latitude: 0, Longitude: 179.5},
+			radiusKm: 111.19,
+			expected: BoundingBox{MinLatitude: -1, MinLongitude: 178.5, MaxLatitude: 1, MaxLongitude: -179.5},
+		},
+		{
+			name:     "Around the north pole",
+			center:   Coordinates{Latitude: 89.5, Longitude: 10},
+			radiusKm: 111.19,
+			expected: BoundingBox{MinLatitude: 88.5, MinLongitude: -180, MaxLatitude: 90, MaxLongitude: 180},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			box := boundingBoxOf(tc.center, tc.radiusKm)
+			assert.InDelta(t, tc.expected.MinLatitude, box.MinLatitude, 0.001, "Failed test: "+tc.name)
+			assert.InDelta(t, tc.expected.MaxLatitude, box.MaxLatitude, 0.001, "Failed test: "+tc.name)
+			assert.InDelta(t, tc.expected.MinLongitude, box.MinLongitude, 0.001, "Failed test: "+tc.name)
+			assert.InDelta(t, tc.expected.MaxLongitude, box.MaxLongitude, 0.001, "Failed test: "+tc.name)
+		})
+	}
+}
+
+func TestBoxCondition(t *testing.T) {
+	var args queryArgs
+	condition := boxCondition(&args, BoundingBox{MinLatitude: -1, MinLongitude: 9, MaxLatitude: 1, MaxLongitude: 11})
+	assert.Equal(t, "point(longitude, latitude) <@ box(point($1, $2), point($3, $4))", condition)
+	assert.Equal(t, queryArgs{9.0, -1.0, 11.0, 1.0}, args)
+
+	args = nil
+	condition = boxCondition(&args, BoundingBox{MinLatitude: -1, MinLongitude: 178.5, MaxLatitude: 1, MaxLongitude: -179.5})
+	assert.Equal(t, "(point(longitude, latitude) <@ box(point($1, $2), point($3, $4)) OR "+
+		"point(longitude, latitude) <@ box(point($5, $6), point($7, $8)))", condition)
+	assert.Equal(t, queryArgs{178.5, -1.0, 180.0, 1.0, -180.0, -1.0, -179.5, 1.0}, args)
+}
+
+func TestNearQuery(t *testing.T) {
+	opts := NearOptions{Center: Coordinates{Latitude: 0, Longitude: 10}, RadiusKm: 50, Limit: 5}
+
+	var args queryArgs
+	query := nearQuery(&args, opts)
+	assert.Contains(t, query, "deleted_at IS NULL AND point(longitude, latitude) <@ box(point($3, $4), point($5, $6))")
+	assert.Contains(t, query, ") AS candidates WHERE distance_km <= $7 ORDER BY distance_km, id LIMIT $8")
+	assert.Len(t, args, 8)
+	assert.Equal(t, 50.0, args[6])
+	assert.Equal(t, 5, args[7])
+
+	args = nil
+	query = nearPostGISQuery(&args, opts)
+	assert.Contains(t, query, "ST_DWithin((ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography), "+
+		"ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)")
+	assert.Contains(t, query, "ORDER BY distance_km, id LIMIT $4")
+	assert.Equal(t, queryArgs{10.0, 0.0, 50000.0, 5}, args)
+}
+
+func TestWithinQuery(t *testing.T) {
+	square := []geojson.Position{{139.5, 35.5}, {140, 35.5}, {140, 36}, {139.5, 36}, {139.5, 35.5}}
+	hole := []geojson.Position{{139.7, 35.7}, {139.8, 35.7}, {139.8, 35.8}, {139.7, 35.7}}
+	island := []geojson.Position{{141, 36}, {141.5, 36}, {141.5, 36.5}, {141, 36}}
+	opts := WithinOptions{Polygons: []geojson.Polygon{{square, hole}, {island}}, Limit: 50}
+
+	var args queryArgs
+	query := withinQuery(&args, opts)
+	assert.Contains(t, query, "WHERE deleted_at IS NULL AND ((point(longitude, latitude) <@ $1::polygon AND "+
+		"NOT point(longitude, latitude) <@ $2::polygon) OR (point(longitude, latitude) <@ $3::polygon)) ORDER BY id LIMIT $4")
+	assert.Equal(t, queryArgs{
+		"((139.5,35.5),(140,35.5),(140,36),(139.5,36),(139.5,35.5))",
+		"((139.7,35.7),(139.8,35.7),(139.8,35.8),(139.7,35.7))",
+		"((141,36),(141.5,36),(141.5,36.5),(141,36))",
+		50,
+	}, args)
+
+	args = nil
+	opts.Polygons = opts.Polygons[1:]
+	query, err := withinPostGISQuery(&args, opts)
+	assert.NoError(t, err)
+	assert.Contains(t, query, "ST_Intersects(ST_SetSRID(ST_MakePoint(longitude, latitude), 4326), ST_SetSRID(ST_GeomFromGeoJSON($1), 4326)) ORDER BY id LIMIT $2")
+	assert.Equal(t, queryArgs{`{"type":"MultiPolygon","coordinates":[[[[141,36],[141.5,36],[141.5,36.5],[141,36]]]]}`, 50}, args)
+}
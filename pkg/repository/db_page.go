@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/glower/kaze/graph/model"
+)
+
+const (
+	// DefaultPageLimit is the number of power plants of a page if no limit is requested.
+	DefaultPageLimit = 10
+	// MaxPageLimit is the maximum number of power plants of a page.
+	MaxPageLimit = 100
+)
+
+// Cursor is the position of a power plant in a sorted list, the value of the sort field and the ID
+// of the power plant. It is passed to clients as an opaque string.
+type Cursor struct {
+	Field SortField `json:"f"`
+	// Value of the sort field, a string for names and times, a number for capacities and distances
+	// and nil if the power plant has no value
+	Value any    `json:"v,omitempty"`
+	ID    string `json:"id"`
+}
+
+// String encodes the cursor.
+func (c Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a cursor encoded with Cursor.String.
+func ParseCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+	if _, err := strconv.ParseInt(c.ID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+	return &c, nil
+}
+
+// Page is a page of a keyset pagination of power plants. Unlike an offset, the cursor keeps its position
+// when power plants are added or deleted while paging.
+type Page struct {
+	// Limit is the maximum number of power plants of the page
+	Limit int
+	// Cursor is the power plant the page starts after, or ends before if the page is backward.
+	// Nil starts at the first power plant, or ends at the last one if the page is backward
+	Cursor *Cursor
+	// Backward pages towards the first power plant
+	Backward bool
+}
+
+// PowerPlantPage is a page of power plants in the order of the sort with the cursors of the power plants.
+type PowerPlantPage struct {
+	PowerPlants []model.PowerPlant
+	Cursors     []Cursor
+	// HasMore reports whether there are more power plants in the direction of the page
+	HasMore bool
+}
+
+// Validate checks the limit of the page and that the cursor belongs to the sort.
+func (p Page) Validate(sort PowerPlantSort) error {
+	if p.Limit < 1 || p.Limit > MaxPageLimit {
+		return fmt.Errorf("number of power plants of a page must be between 1 and %d, got %d", MaxPageLimit, p.Limit)
+	}
+	if p.Cursor == nil {
+		return nil
+	}
+
+	if p.Cursor.Field != sort.field() {
+		return fmt.Errorf("cursor of the sort field %s can't be used to sort by %s", p.Cursor.Field, sort.field())
+	}
+	switch p.Cursor.Value.(type) {
+	case nil:
+	case string:
+		if sort.field() != SortByName && sort.field() != SortByCreatedAt {
+			return fmt.Errorf("invalid cursor value for the sort field %s", sort.field())
+		}
+	case float64:
+		if sort.field() != SortByCapacity && sort.field() != SortByDistance {
+			return fmt.Errorf("invalid cursor value for the sort field %s", sort.field())
+		}
+	default:
+		return fmt.Errorf("invalid cursor value for the sort field %s", sort.field())
+	}
+	return nil
+}
+
+// field returns the sort field, SortByID for the zero value.
+func (s PowerPlantSort) field() SortField {
+	if s.Field == "" {
+		return SortByID
+	}
+	return s.Field
+}
+
+// pageRow is a power plant with the value of the sort field.
+type pageRow struct {
+	model.PowerPlant
+	SortKey any `db:"sort_key"`
+}
+
+// keyset is the SQL condition of the power plants after the cursor in the order of the sort,
+// or before the cursor if backward. expression is the SQL expression of the sort field.
+func (s PowerPlantSort) keyset(args *queryArgs, expression string, cursor Cursor, backward bool) string {
+	// the power plants after the cursor have greater values in ascending order
+	op := ">"
+	if s.Descending != backward {
+		op = "<"
+	}
+	id := args.add(cursor.ID)
+
+	switch {
+	case expression == "":
+		return fmt.Sprintf("id %s %s", op, id)
+	case cursor.Value == nil && !backward:
+		// power plants without a value are sorted last, only the ones with a greater ID follow
+		return fmt.Sprintf("(%s IS NULL AND id %s %s)", expression, op, id)
+	case cursor.Value == nil:
+		return fmt.Sprintf("(%s IS NOT NULL OR id %s %s)", expression, op, id)
+	}
+
+	value := args.add(cursor.Value)
+	condition := fmt.Sprintf("%s %s %s OR (%s = %s AND id %s %s)", expression, op, value, expression, value, op, id)
+	if !backward {
+		condition += " OR " + expression + " IS NULL"
+	}
+	return "(" + condition + ")"
+}
+
+// orderClause is the ORDER BY clause of the sort expression, the page is sorted in reverse order if backward.
+func (s PowerPlantSort) orderClause(expression string, backward bool) string {
+	direction, nulls := "ASC", "NULLS LAST"
+	if s.Descending != backward {
+		direction = "DESC"
+	}
+	if backward {
+		nulls = "NULLS FIRST"
+	}
+
+	if expression == "" {
+		return " ORDER BY id " + direction
+	}
+	return fmt.Sprintf(" ORDER BY %s %s %s, id %s", expression, direction, nulls, direction)
+}
+
+// cursorOf returns the cursor of a power plant with the value of its sort field.
+func (s PowerPlantSort) cursorOf(row pageRow) Cursor {
+	c := Cursor{Field: s.field(), ID: row.ID}
+	switch v := row.SortKey.(type) {
+	case time.Time:
+		// created_at is a timestamp without time zone
+		c.Value = v.Format("2006-01-02 15:04:05.999999")
+	case []byte:
+		c.Value = string(v)
+	case string, float64:
+		c.Value = v
+	}
+	return c
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		cursor := Cursor{Field: SortByCapacity, Value: 120.5, ID: "42"}
+		parsed, err := ParseCursor(cursor.String())
+		assert.NoError(t, err)
+		assert.Equal(t, &cursor, parsed)
+	})
+
+	t.Run("round trip without a value", func(t *testing.T) {
+		cursor := Cursor{Field: SortByID, ID: "7"}
+		parsed, err := ParseCursor(cursor.String())
+		assert.NoError(t, err)
+		assert.Equal(t, &cursor, parsed)
+	})
+
+	t.Run("fail due to an invalid cursor", func(t *testing.T) {
+		_, err := ParseCursor("not a cursor")
+		assert.Error(t, err)
+
+		_, err = ParseCursor(Cursor{Field: SortByID, ID: "1 OR 1=1"}.String())
+		assert.Error(t, err)
+	})
+}
+
+func TestPageValidate(t *testing.T) {
+	byName := PowerPlantSort{Field: SortByName}
+
+	assert.NoError(t, Page{Limit: 10}.Validate(PowerPlantSort{}))
+	assert.NoError(t, Page{Limit: 10, Cursor: &Cursor{Field: SortByID, ID: "1"}}.Validate(PowerPlantSort{}))
+	assert.NoError(t, Page{Limit: 10, Cursor: &Cursor{Field: SortByName, Value: "Kobe", ID: "1"}}.Validate(byName))
+	assert.NoError(t, Page{Limit: 10, Cursor: &Cursor{Field: SortByName, ID: "1"}}.Validate(byName))
+
+	assert.Error(t, Page{Limit: 0}.Validate(byName))
+	assert.Error(t, Page{Limit: MaxPageLimit + 1}.Validate(byName))
+	assert.Error(t, Page{Limit: 10, Cursor: &Cursor{Field: SortByID, ID: "1"}}.Validate(byName), "Expected an error for a cursor of another sort")
+	assert.Error(t, Page{Limit: 10, Cursor: &Cursor{Field: SortByName, Value: 1.5, ID: "1"}}.Validate(byName))
+}
+
+func TestPowerPlantSortKeyset(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sort     PowerPlantSort
+		cursor   Cursor
+		backward bool
+		expected string
+	}{
+		{
+			name:     "After an ID",
+			sort:     PowerPlantSort{},
+			cursor:   Cursor{Field: SortByID, ID: "3"},
+			expected: "id > $1",
+		},
+		{
+			name:     "Before an ID in descending order",
+			sort:     PowerPlantSort{Descending: true},
+			cursor:   Cursor{Field: SortByID, ID: "3"},
+			backward: true,
+			expected: "id > $1",
+		},
+		{
+			name:     "After a capacity",
+			sort:     PowerPlantSort{Field: SortByCapacity},
+			cursor:   Cursor{Field: SortByCapacity, Value: 100.0, ID: "3"},
+			expected: "(capacity_mw > $2 OR (capacity_mw = $2 AND id > $1) OR capacity_mw IS NULL)",
+		},
+		{
+			name:     "After a capacity in descending order",
+			sort:     PowerPlantSort{Field: SortByCapacity, Descending: true},
+			cursor:   Cursor{Field: SortByCapacity, Value: 100.0, ID: "3"},
+			expected: "(capacity_mw < $2 OR (capacity_mw = $2 AND id < $1) OR capacity_mw IS NULL)",
+		},
+		{
+			name:     "Before a capacity",
+			sort:     PowerPlantSort{Field: SortByCapacity},
+			cursor:   Cursor{Field: SortByCapacity, Value: 100.0, ID: "3"},
+			backward: true,
+			expected: "(capacity_mw < $2 OR (capacity_mw = $2 AND id < $1))",
+		},
+		{
+			name:     "After a power plant without capacity",
+			sort:     PowerPlantSort{Field: SortByCapacity},
+			cursor:   Cursor{Field: SortByCapacity, ID: "3"},
+			expected: "(capacity_mw IS NULL AND id > $1)",
+		},
+		{
+			name:     "Before a power plant without capacity",
+			sort:     PowerPlantSort{Field: SortByCapacity},
+			cursor:   Cursor{Field: SortByCapacity, ID: "3"},
+			backward: true,
+			expected: "(capacity_mw IS NOT NULL OR id < $1)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args queryArgs
+			expression := tc.sort.expression(&args)
+			assert.Equal(t, tc.expected, tc.sort.keyset(&args, expression, tc.cursor, tc.backward), "Failed test: "+tc.name)
+		})
+	}
+}
+
+func TestPowerPlantSortOrderClause(t *testing.T) {
+	byName := PowerPlantSort{Field: SortByName}
+	assert.Equal(t, " ORDER BY name ASC NULLS LAST, id ASC", byName.orderClause("name", false))
+	assert.Equal(t, " ORDER BY name DESC NULLS FIRST, id DESC", byName.orderClause("name", true), "Expected the reverse order for a backward page")
+	assert.Equal(t, " ORDER BY id ASC", PowerPlantSort{Descending: true}.orderClause("", true))
+}
+
+func TestPowerPlantSortCursorOf(t *testing.T) {
+	row := pageRow{PowerPlant: model.PowerPlant{ID: "5"}, SortKey: time.Date(2024, 1, 1, 12, 30, 0, 500, time.UTC)}
+	assert.Equal(t, Cursor{Field: SortByCreatedAt, Value: "2024-01-01 12:30:00", ID: "5"}, PowerPlantSort{Field: SortByCreatedAt}.cursorOf(row))
+
+	row = pageRow{PowerPlant: model.PowerPlant{ID: "5"}}
+	assert.Equal(t, Cursor{Field: SortByID, ID: "5"}, PowerPlantSort{}.cursorOf(row))
+}
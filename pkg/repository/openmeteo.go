@@ -3,23 +3,151 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // WeatherForecastResponse represents the response structure for weather forecasts.
 type WeatherForecastResponse struct {
-	Hourly Hourly `json:"hourly"`
+	// Timezone is the IANA timezone of the location, e.g. "Asia/Tokyo".
+	Timezone string `json:"timezone"`
+	// TimezoneAbbreviation is the abbreviation of the timezone, e.g. "JST".
+	TimezoneAbbreviation string `json:"timezone_abbreviation"`
+	// UTCOffsetSeconds is the offset of the hourly times to UTC.
+	UTCOffsetSeconds int    `json:"utc_offset_seconds"`
+	Hourly           Hourly `json:"hourly"`
+	// FetchedAt is the time the forecast was fetched from the API.
+	FetchedAt time.Time `json:"fetched_at"`
+	// Stale is set if the forecast is outdated, because the API was not available to fetch a new one.
+	Stale bool `json:"-"`
 }
 
 type Hourly struct {
-	Time             []string  `json:"time"`
-	Precipitation    []float64 `json:"precipitation"`
-	WindSpeed10m     []float64 `json:"wind_speed_10m"`
-	Temperature2m    []float64 `json:"temperature_2m"`
-	WindDirection10m []float64 `json:"wind_direction_10m"`
+	Time             []string   `json:"time"`
+	Precipitation    []*float64 `json:"precipitation"`
+	WindSpeed10m     []*float64 `json:"wind_speed_10m"`
+	Temperature2m    []*float64 `json:"temperature_2m"`
+	WindDirection10m []*float64 `json:"wind_direction_10m"`
+
+	// The optional variables are only set if they are requested, single values are null if the
+	// weather model has no data for the hour.
+	WindSpeed80m           []*float64 `json:"wind_speed_80m,omitempty"`
+	WindSpeed120m          []*float64 `json:"wind_speed_120m,omitempty"`
+	WindSpeed180m          []*float64 `json:"wind_speed_180m,omitempty"`
+	WindDirection80m       []*float64 `json:"wind_direction_80m,omitempty"`
+	WindDirection120m      []*float64 `json:"wind_direction_120m,omitempty"`
+	WindDirection180m      []*float64 `json:"wind_direction_180m,omitempty"`
+	WindGusts10m           []*float64 `json:"wind_gusts_10m,omitempty"`
+	CloudCoverLow          []*float64 `json:"cloud_cover_low,omitempty"`
+	CloudCoverMid          []*float64 `json:"cloud_cover_mid,omitempty"`
+	CloudCoverHigh         []*float64 `json:"cloud_cover_high,omitempty"`
+	ShortwaveRadiation     []*float64 `json:"shortwave_radiation,omitempty"`
+	DirectRadiation        []*float64 `json:"direct_radiation,omitempty"`
+	DiffuseRadiation       []*float64 `json:"diffuse_radiation,omitempty"`
+	GlobalTiltedIrradiance []*float64 `json:"global_tilted_irradiance,omitempty"`
+	SurfacePressure        []*float64 `json:"surface_pressure,omitempty"`
+	RelativeHumidity2m     []*float64 `json:"relative_humidity_2m,omitempty"`
+	Snowfall               []*float64 `json:"snowfall,omitempty"`
+	SnowDepth              []*float64 `json:"snow_depth,omitempty"`
+}
+
+// Location returns the timezone of the times in the response.
+func (r *WeatherForecastResponse) Location() *time.Location {
+	return location(r.Timezone, r.TimezoneAbbreviation, r.UTCOffsetSeconds)
+}
+
+// DailyWeatherResponse represents the response structure for daily weather aggregates.
+type DailyWeatherResponse struct {
+	// Timezone is the IANA timezone of the location, the days are the local calendar days of the location.
+	Timezone string `json:"timezone"`
+	// TimezoneAbbreviation is the abbreviation of the timezone, e.g. "JST".
+	TimezoneAbbreviation string `json:"timezone_abbreviation"`
+	// UTCOffsetSeconds is the offset of the local times to UTC.
+	UTCOffsetSeconds int   `json:"utc_offset_seconds"`
+	Daily            Daily `json:"daily"`
+	// FetchedAt is the time the aggregates were fetched from the API.
+	FetchedAt time.Time `json:"fetched_at"`
+	// Stale is set if the aggregates are outdated, because the API was not available to fetch new ones.
+	Stale bool `json:"-"`
+}
+
+// Location returns the timezone of the days and times in the response.
+func (r *DailyWeatherResponse) Location() *time.Location {
+	return location(r.Timezone, r.TimezoneAbbreviation, r.UTCOffsetSeconds)
+}
+
+// Daily are the daily aggregates, single values are null if the weather model has no data for the day.
+type Daily struct {
+	Time                     []string   `json:"time"`
+	Temperature2mMin         []*float64 `json:"temperature_2m_min"`
+	Temperature2mMax         []*float64 `json:"temperature_2m_max"`
+	PrecipitationSum         []*float64 `json:"precipitation_sum"`
+	WindSpeed10mMax          []*float64 `json:"wind_speed_10m_max"`
+	WindGusts10mMax          []*float64 `json:"wind_gusts_10m_max"`
+	WindDirection10mDominant []*float64 `json:"wind_direction_10m_dominant"`
+	// Sunrise and Sunset are local times, e.g. "2024-01-01T08:17".
+	Sunrise []string `json:"sunrise"`
+	Sunset  []string `json:"sunset"`
+	// SunshineDuration is in seconds.
+	SunshineDuration []*float64 `json:"sunshine_duration"`
+}
+
+// hourlyVariables are the base hourly variables that are always requested from Open-Meteo.
+const hourlyVariables = "temperature_2m,precipitation,wind_speed_10m,wind_direction_10m"
+
+// dailyVariables are the daily aggregates requested from Open-Meteo.
+const dailyVariables = "temperature_2m_min,temperature_2m_max,precipitation_sum,wind_speed_10m_max,wind_gusts_10m_max," +
+	"wind_direction_10m_dominant,sunrise,sunset,sunshine_duration"
+
+// ForecastOptions controls the time range and the variables of a weather forecast request.
+type ForecastOptions struct {
+	// ForecastDays is the number of days to forecast, starting today.
+	ForecastDays int
+	// PastDays is the number of past days to include in the forecast.
+	PastDays int
+	// Model is the Open-Meteo weather model, e.g. "icon_seamless". Empty uses the best model for the location.
+	Model string
+	// Variables are the optional hourly variables to request in addition to the base variables.
+	Variables Variables
+	// Tilt of the solar panels for the global tilted irradiance in degrees, 0 is horizontal and 90 vertical.
+	Tilt float64
+	// Azimuth of the solar panels for the global tilted irradiance in degrees, 0 is south, -90 east and 90 west.
+	Azimuth float64
+	// Units of the temperature, wind speeds and precipitation, the metric defaults if they are not set.
+	Units Units
+}
+
+const (
+	// DefaultForecastDays is the number of forecast days used when none are requested.
+	DefaultForecastDays = 7
+	// MaxForecastDays is the maximum number of forecast days supported by Open-Meteo.
+	MaxForecastDays = 16
+	// MaxPastDays is the maximum number of past days supported by Open-Meteo.
+	MaxPastDays = 92
+)
+
+// Validate checks that the options are within the range supported by Open-Meteo.
+func (o ForecastOptions) Validate() error {
+	if o.ForecastDays < 1 || o.ForecastDays > MaxForecastDays {
+		return fmt.Errorf("forecastDays must be between 1 and %d, got %d", MaxForecastDays, o.ForecastDays)
+	}
+	if o.PastDays < 0 || o.PastDays > MaxPastDays {
+		return fmt.Errorf("pastDays must be between 0 and %d, got %d", MaxPastDays, o.PastDays)
+	}
+	if o.Tilt < 0 || o.Tilt > 90 {
+		return fmt.Errorf("tilt must be between 0 and 90, got %g", o.Tilt)
+	}
+	if o.Azimuth < -180 || o.Azimuth > 180 {
+		return fmt.Errorf("azimuth must be between -180 and 180, got %g", o.Azimuth)
+	}
+	return o.Units.Validate()
 }
 
 // ElevationResponse represents the response structure for elevation data.
@@ -27,92 +155,427 @@ type ElevationResponse struct {
 	Elevation []float64 `json:"elevation"`
 }
 
+// Coordinates is a geographic location in degrees.
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
 // OpenMeteoRepository defines the interface for interacting with the Open-Meteo API.
 //
 //go:generate go run github.com/vektra/mockery/v2@v2 --name=OpenMeteoRepository --filename=open_meteo_repository.go --output=../../mocks/
 type OpenMeteoRepository interface {
 	GetElevation(ctx context.Context, latitude, longitude float64) (float64, error)
-	GetWeatherForecast(ctx context.Context, latitude, longitude float64) (*WeatherForecastResponse, error)
+	GetWeatherForecast(ctx context.Context, latitude, longitude float64, opts ForecastOptions) (*WeatherForecastResponse, error)
+	// GetElevations retrieves the elevations of multiple locations with a single request.
+	GetElevations(ctx context.Context, coordinates []Coordinates) ([]float64, error)
+	// GetWeatherForecasts retrieves the weather forecasts of multiple locations with a single request.
+	GetWeatherForecasts(ctx context.Context, coordinates []Coordinates, opts ForecastOptions) ([]*WeatherForecastResponse, error)
+	// GetDailyWeather retrieves the daily aggregates of the next days of multiple locations with a single request.
+	GetDailyWeather(ctx context.Context, coordinates []Coordinates, days int) ([]*DailyWeatherResponse, error)
+	// GetHistoricalWeather retrieves the historical weather of multiple locations, long ranges with multiple requests.
+	GetHistoricalWeather(ctx context.Context, coordinates []Coordinates, opts HistoricalOptions) ([]*HistoricalWeatherResponse, error)
+}
+
+const (
+	// openMeteoBaseURL is the URL of the free Open-Meteo API.
+	openMeteoBaseURL = "https://api.open-meteo.com"
+	// openMeteoCustomerBaseURL is the URL of the commercial Open-Meteo API, it requires an API key.
+	openMeteoCustomerBaseURL = "https://customer-api.open-meteo.com"
+	// openMeteoArchiveBaseURL is the URL of the free Open-Meteo archive API.
+	openMeteoArchiveBaseURL = "https://archive-api.open-meteo.com"
+	// openMeteoCustomerArchiveBaseURL is the URL of the commercial Open-Meteo archive API, it requires an API key.
+	openMeteoCustomerArchiveBaseURL = "https://customer-archive-api.open-meteo.com"
+)
+
+// OpenMeteoConfig configures the Open-Meteo client.
+type OpenMeteoConfig struct {
+	// APIKey of the commercial API, requests are sent to the commercial API if it is set and no BaseURL is configured.
+	APIKey string
+	// BaseURL of the API, e.g. the URL of a self-hosted Open-Meteo.
+	BaseURL string
+	// ArchiveBaseURL of the archive API with the historical weather. If it is empty the BaseURL is used
+	// if it is set, otherwise the free archive API or the commercial one if an API key is set.
+	ArchiveBaseURL string
+	// HTTPClient is used for the requests, a client with the Timeout is created if it is nil.
+	HTTPClient *http.Client
+	// Timeout of a single request, 0 uses the default.
+	Timeout time.Duration
+	// MaxRetries is the number of retries of requests failing with 429 or 5xx, 0 uses the default
+	// and a negative number disables retries.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry, it doubles with every retry. 0 uses the default.
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the delay between retries, a longer Retry-After of the API fails the request.
+	// 0 uses the default.
+	MaxRetryDelay time.Duration
+}
+
+// DefaultOpenMeteoConfig is used for the options that are not set.
+var DefaultOpenMeteoConfig = OpenMeteoConfig{
+	BaseURL:       openMeteoBaseURL,
+	Timeout:       10 * time.Second,
+	MaxRetries:    3,
+	RetryDelay:    200 * time.Millisecond,
+	MaxRetryDelay: 5 * time.Second,
 }
 
 type openMeteoRepo struct {
-	apiKey string
+	apiKey         string
+	baseURL        string
+	archiveBaseURL string
+	client         *http.Client
+	maxRetries     int
+	retryDelay     time.Duration
+	maxRetryDelay  time.Duration
+	sleep          func(ctx context.Context, d time.Duration) error
 }
 
-func NewOpenMeteoRepository(apiKey string) OpenMeteoRepository {
+// NewOpenMeteoRepository creates a client of the Open-Meteo API.
+func NewOpenMeteoRepository(cfg OpenMeteoConfig) OpenMeteoRepository {
+	if cfg.ArchiveBaseURL == "" {
+		cfg.ArchiveBaseURL = cfg.BaseURL
+	}
+	if cfg.ArchiveBaseURL == "" {
+		cfg.ArchiveBaseURL = openMeteoArchiveBaseURL
+		if cfg.APIKey != "" {
+			cfg.ArchiveBaseURL = openMeteoCustomerArchiveBaseURL
+		}
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultOpenMeteoConfig.BaseURL
+		if cfg.APIKey != "" {
+			cfg.BaseURL = openMeteoCustomerBaseURL
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultOpenMeteoConfig.Timeout
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultOpenMeteoConfig.MaxRetries
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultOpenMeteoConfig.RetryDelay
+	}
+	if cfg.MaxRetryDelay <= 0 {
+		cfg.MaxRetryDelay = DefaultOpenMeteoConfig.MaxRetryDelay
+	}
+
 	return &openMeteoRepo{
-		apiKey: apiKey,
+		apiKey:         cfg.APIKey,
+		baseURL:        strings.TrimSuffix(cfg.BaseURL, "/"),
+		archiveBaseURL: strings.TrimSuffix(cfg.ArchiveBaseURL, "/"),
+		client:         cfg.HTTPClient,
+		maxRetries:     max(cfg.MaxRetries, 0),
+		retryDelay:     cfg.RetryDelay,
+		maxRetryDelay:  cfg.MaxRetryDelay,
+		sleep:          sleep,
 	}
 }
 
 // GetElevation retrieves elevation data from the Open-Meteo API.
 func (r *openMeteoRepo) GetElevation(ctx context.Context, latitude, longitude float64) (float64, error) {
-	url := fmt.Sprintf("https://api.open-meteo.com/v1/elevation?latitude=%f&longitude=%f&apikey=%s", latitude, longitude, r.apiKey)
-	slog.Debug("Fetching elevation data", "url", url)
-
-	// Create a new request with context
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	elevations, err := r.GetElevations(ctx, []Coordinates{{Latitude: latitude, Longitude: longitude}})
 	if err != nil {
-		return 0, fmt.Errorf("error creating request: %w", err)
+		return 0, err
 	}
 
-	// Make the request
-	resp, err := http.DefaultClient.Do(req)
+	return elevations[0], nil
+}
+
+// GetWeatherForecast retrieves weather forecast data from the Open-Meteo API.
+// The hourly times are returned in the local timezone of the given coordinates.
+func (r *openMeteoRepo) GetWeatherForecast(ctx context.Context, latitude, longitude float64, opts ForecastOptions) (*WeatherForecastResponse, error) {
+	forecasts, err := r.GetWeatherForecasts(ctx, []Coordinates{{Latitude: latitude, Longitude: longitude}}, opts)
 	if err != nil {
-		return 0, fmt.Errorf("error making request to Open-Meteo API: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("received non-OK response from Open-Meteo API: %d", resp.StatusCode)
-	}
+	return forecasts[0], nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// GetElevations retrieves elevation data for multiple locations from the Open-Meteo API.
+// The elevations are returned in the order of the given coordinates.
+func (r *openMeteoRepo) GetElevations(ctx context.Context, coordinates []Coordinates) ([]float64, error) {
+	latitudes, longitudes := joinCoordinates(coordinates)
+	query := url.Values{}
+	query.Set("latitude", latitudes)
+	query.Set("longitude", longitudes)
+	slog.Debug("Fetching elevation data", "query", query.Encode(), "locations", len(coordinates))
+
+	body, err := r.get(ctx, "/v1/elevation", query)
 	if err != nil {
-		return 0, fmt.Errorf("error reading response body: %w", err)
+		return nil, err
 	}
 
 	var elevationResponse ElevationResponse
 	if err := json.Unmarshal(body, &elevationResponse); err != nil {
-		return 0, fmt.Errorf("error unmarshaling response body: %w", err)
+		return nil, &RequestError{Err: fmt.Errorf("error unmarshaling response body: %w", err)}
 	}
 
-	if len(elevationResponse.Elevation) == 0 {
-		return 0, fmt.Errorf("no elevation data received")
+	if len(elevationResponse.Elevation) != len(coordinates) {
+		return nil, &RequestError{Err: fmt.Errorf("received elevation data for %d of %d locations", len(elevationResponse.Elevation), len(coordinates))}
 	}
 
-	return elevationResponse.Elevation[0], nil
+	return elevationResponse.Elevation, nil
 }
 
-// GetWeatherForecast retrieves weather forecast data from the Open-Meteo API.
-func (r *openMeteoRepo) GetWeatherForecast(ctx context.Context, latitude, longitude float64) (*WeatherForecastResponse, error) {
-	url := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&hourly=temperature_2m,precipitation,wind_speed_10m,wind_direction_10m", latitude, longitude)
-	slog.Debug("Fetching weather forecast data", "url", url)
+// GetWeatherForecasts retrieves weather forecast data for multiple locations from the Open-Meteo API.
+// The forecasts are returned in the order of the given coordinates, the hourly times are returned
+// in the local timezone of each location.
+func (r *openMeteoRepo) GetWeatherForecasts(ctx context.Context, coordinates []Coordinates, opts ForecastOptions) ([]*WeatherForecastResponse, error) {
+	latitudes, longitudes := joinCoordinates(coordinates)
+	query := url.Values{}
+	query.Set("latitude", latitudes)
+	query.Set("longitude", longitudes)
+	hourly := hourlyVariables
+	if opts.Variables != 0 {
+		hourly += "," + opts.Variables.String()
+	}
+	query.Set("hourly", hourly)
+	if opts.Variables.Has(GlobalTiltedIrradiance) {
+		query.Set("tilt", strconv.FormatFloat(opts.Tilt, 'f', -1, 64))
+		query.Set("azimuth", strconv.FormatFloat(opts.Azimuth, 'f', -1, 64))
+	}
+	query.Set("forecast_days", strconv.Itoa(opts.ForecastDays))
+	query.Set("past_days", strconv.Itoa(opts.PastDays))
+	query.Set("timezone", "auto")
+	if opts.Model != "" {
+		query.Set("models", opts.Model)
+	}
+	setUnits(query, opts.Units)
+	slog.Debug("Fetching weather forecast data", "query", query.Encode(), "locations", len(coordinates))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	body, err := r.get(ctx, "/v1/forecast", query)
+	if err != nil {
+		return nil, err
+	}
+
+	forecastResponses, err := decodeLocations[WeatherForecastResponse](body, len(coordinates))
+	if err != nil {
+		return nil, fmt.Errorf("invalid weather forecast data: %w", err)
+	}
+
+	fetchedAt := time.Now().UTC()
+	for _, forecast := range forecastResponses {
+		forecast.FetchedAt = fetchedAt
+	}
+
+	return forecastResponses, nil
+}
+
+// setUnits adds the units to the query, the metric defaults are left out.
+func setUnits(query url.Values, units Units) {
+	if units.Temperature != "" && units.Temperature != Celsius {
+		query.Set("temperature_unit", string(units.Temperature))
+	}
+	if units.WindSpeed != "" && units.WindSpeed != KilometersPerHour {
+		query.Set("wind_speed_unit", string(units.WindSpeed))
+	}
+	if units.Precipitation != "" && units.Precipitation != Millimeters {
+		query.Set("precipitation_unit", string(units.Precipitation))
+	}
+}
+
+// GetDailyWeather retrieves the daily aggregates for multiple locations from the Open-Meteo API, starting today.
+// The aggregates are returned in the order of the given coordinates, the days are the local calendar days
+// of each location.
+func (r *openMeteoRepo) GetDailyWeather(ctx context.Context, coordinates []Coordinates, days int) ([]*DailyWeatherResponse, error) {
+	latitudes, longitudes := joinCoordinates(coordinates)
+	query := url.Values{}
+	query.Set("latitude", latitudes)
+	query.Set("longitude", longitudes)
+	query.Set("daily", dailyVariables)
+	query.Set("forecast_days", strconv.Itoa(days))
+	query.Set("timezone", "auto")
+	slog.Debug("Fetching daily weather data", "query", query.Encode(), "locations", len(coordinates))
+
+	body, err := r.get(ctx, "/v1/forecast", query)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyResponses, err := decodeLocations[DailyWeatherResponse](body, len(coordinates))
+	if err != nil {
+		return nil, fmt.Errorf("invalid daily weather data: %w", err)
+	}
+
+	fetchedAt := time.Now().UTC()
+	for _, daily := range dailyResponses {
+		daily.FetchedAt = fetchedAt
+	}
+
+	return dailyResponses, nil
+}
+
+// decodeLocations decodes the responses of n locations, Open-Meteo returns a single object for one location
+// and a list for multiple locations.
+func decodeLocations[T any](body []byte, n int) ([]*T, error) {
+	var responses []*T
+	var err error
+	if n == 1 {
+		var response T
+		err = json.Unmarshal(body, &response)
+		responses = append(responses, &response)
+	} else {
+		err = json.Unmarshal(body, &responses)
+	}
+	if err != nil {
+		return nil, &RequestError{Err: fmt.Errorf("error unmarshaling response body: %w", err)}
+	}
+
+	if len(responses) != n {
+		return nil, &RequestError{Err: fmt.Errorf("received data for %d of %d locations", len(responses), n)}
+	}
+
+	return responses, nil
+}
+
+// get performs a GET request against the Open-Meteo API and returns the response body.
+func (r *openMeteoRepo) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	return r.getFrom(ctx, r.baseURL, path, query)
+}
+
+// getFrom performs a GET request against the Open-Meteo API at baseURL and returns the response body.
+// Requests failing with 429 or 5xx are retried with exponential backoff, honoring the Retry-After header.
+func (r *openMeteoRepo) getFrom(ctx context.Context, baseURL, path string, query url.Values) ([]byte, error) {
+	if r.apiKey != "" {
+		query.Set("apikey", r.apiKey)
+	}
+	requestURL := baseURL + path + "?" + query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		body, err := r.do(ctx, requestURL)
+		var retryErr *retryableError
+		if err == nil || !errors.As(err, &retryErr) || attempt >= r.maxRetries || ctx.Err() != nil {
+			return body, err
+		}
+
+		delay := min(r.retryDelay<<attempt, r.maxRetryDelay)
+		if retryErr.retryAfter > r.maxRetryDelay {
+			return nil, fmt.Errorf("%w, retry after %s", err, retryErr.retryAfter)
+		}
+		if retryErr.retryAfter > 0 {
+			delay = retryErr.retryAfter
+		}
+
+		slog.Warn("Request to Open-Meteo API failed, retrying", "error", err, "path", path, "attempt", attempt+1, "delay", delay)
+		if err := r.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryableError is returned for requests that can be retried, after the delay requested by the API if it is set.
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// RequestError is returned for errors caused by the requested locations, e.g. a location the API rejects or
+// returns invalid data for. Unlike a failure of the API, requesting the locations of a batch on their own can succeed.
+type RequestError struct {
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// do performs a single GET request and returns the response body.
+func (r *openMeteoRepo) do(ctx context.Context, requestURL string) ([]byte, error) {
+	// Create a new request with context
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	// Make the request
+	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request to Open-Meteo API: %w", err)
+		return nil, &retryableError{err: fmt.Errorf("error making request to Open-Meteo API: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-OK response from Open-Meteo API: %d", resp.StatusCode)
+		err := fmt.Errorf("received non-OK response from Open-Meteo API: %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return nil, &retryableError{err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+		}
+		if resp.StatusCode == http.StatusBadRequest {
+			return nil, &RequestError{Err: err}
+		}
+		return nil, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, &retryableError{err: fmt.Errorf("error reading response body: %w", err)}
+	}
+
+	return body, nil
+}
+
+// parseRetryAfter parses the Retry-After header, given in seconds or as HTTP date. It returns 0 if it is not set or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
 	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// location returns the timezone of the local times in a response.
+func location(timezone, abbreviation string, utcOffsetSeconds int) *time.Location {
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			return loc
+		}
+	}
+	return time.FixedZone(abbreviation, utcOffsetSeconds)
+}
 
-	var forecastResponse WeatherForecastResponse
-	if err := json.Unmarshal(body, &forecastResponse); err != nil {
-		return nil, fmt.Errorf("error unmarshaling response body: %w", err)
+// joinCoordinates formats the coordinates as comma-separated latitude and longitude lists.
+func joinCoordinates(coordinates []Coordinates) (string, string) {
+	latitudes := make([]string, 0, len(coordinates))
+	longitudes := make([]string, 0, len(coordinates))
+	for _, c := range coordinates {
+		latitudes = append(latitudes, strconv.FormatFloat(c.Latitude, 'f', 6, 64))
+		longitudes = append(longitudes, strconv.FormatFloat(c.Longitude, 'f', 6, 64))
 	}
 
-	return &forecastResponse, nil
+	return strings.Join(latitudes, ","), strings.Join(longitudes, ",")
 }
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// Resolution is the time resolution of historical weather data.
+type Resolution string
+
+const (
+	// ResolutionHourly returns the hourly weather.
+	ResolutionHourly Resolution = "hourly"
+	// ResolutionDaily returns the daily aggregates.
+	ResolutionDaily Resolution = "daily"
+)
+
+const (
+	// MaxHistoricalHourlyDays is the maximum number of days of hourly historical weather of a single request.
+	MaxHistoricalHourlyDays = 366
+	// MaxHistoricalDailyDays is the maximum number of days of daily historical weather of a single request.
+	MaxHistoricalDailyDays = 10 * 366
+	// historicalChunkDays is the number of days requested from the archive API with a single call,
+	// longer ranges are split into multiple calls to keep the responses small.
+	historicalChunkDays = 92
+	// ArchiveDelayDays is the delay of the ERA5 reanalysis, the archive has no data of the most recent days.
+	ArchiveDelayDays = 5
+)
+
+// archiveStart is the first day of the ERA5 reanalysis.
+var archiveStart = time.Date(1940, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// HistoricalOptions controls the time range and resolution of a historical weather request.
+type HistoricalOptions struct {
+	// From is the first local day of the range at midnight UTC.
+	From time.Time
+	// To is the last local day of the range at midnight UTC, inclusive.
+	To time.Time
+	// Resolution of the data, hourly or daily.
+	Resolution Resolution
+}
+
+// Validate checks that the range is covered by the archive API and not too long for the resolution.
+// The archive has no data of the last ArchiveDelayDays days.
+func (o HistoricalOptions) Validate(now time.Time) error {
+	if o.Resolution != ResolutionHourly && o.Resolution != ResolutionDaily {
+		return fmt.Errorf("unknown resolution %q", o.Resolution)
+	}
+	if o.To.Before(o.From) {
+		return fmt.Errorf("from %s must not be after to %s", o.From.Format(time.DateOnly), o.To.Format(time.DateOnly))
+	}
+	if o.From.Before(archiveStart) {
+		return fmt.Errorf("from must not be before %s, got %s", archiveStart.Format(time.DateOnly), o.From.Format(time.DateOnly))
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if latest := today.AddDate(0, 0, -ArchiveDelayDays); o.To.After(latest) {
+		return fmt.Errorf("to must not be after %s, the archive has no data of the last %d days, got %s",
+			latest.Format(time.DateOnly), ArchiveDelayDays, o.To.Format(time.DateOnly))
+	}
+
+	maxDays := MaxHistoricalHourlyDays
+	if o.Resolution == ResolutionDaily {
+		maxDays = MaxHistoricalDailyDays
+	}
+	if days := o.days(); days > maxDays {
+		return fmt.Errorf("the range must not be longer than %d days for the %s resolution, got %d", maxDays, o.Resolution, days)
+	}
+	return nil
+}
+
+// days returns the number of days of the range.
+func (o HistoricalOptions) days() int {
+	return int(o.To.Sub(o.From).Hours()/24) + 1
+}
+
+// chunks splits the range into ranges of at most historicalChunkDays days.
+func (o HistoricalOptions) chunks() []HistoricalOptions {
+	var chunks []HistoricalOptions
+	for from := o.From; !from.After(o.To); from = from.AddDate(0, 0, historicalChunkDays) {
+		chunk := o
+		chunk.From = from
+		if to := from.AddDate(0, 0, historicalChunkDays-1); to.Before(o.To) {
+			chunk.To = to
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// HistoricalWeatherResponse represents the response structure of the archive API, Hourly or Daily
+// is set depending on the requested resolution.
+type HistoricalWeatherResponse struct {
+	// Timezone is the IANA timezone of the location, e.g. "Asia/Tokyo".
+	Timezone string `json:"timezone"`
+	// TimezoneAbbreviation is the abbreviation of the timezone, e.g. "JST".
+	TimezoneAbbreviation string `json:"timezone_abbreviation"`
+	// UTCOffsetSeconds is the offset of the local times to UTC.
+	UTCOffsetSeconds int              `json:"utc_offset_seconds"`
+	Hourly           HistoricalHourly `json:"hourly"`
+	Daily            Daily            `json:"daily"`
+}
+
+// HistoricalHourly is the hourly weather of the archive, single values are null if the reanalysis has
+// no data for the hour yet.
+type HistoricalHourly struct {
+	Time             []string   `json:"time"`
+	Precipitation    []*float64 `json:"precipitation"`
+	WindSpeed10m     []*float64 `json:"wind_speed_10m"`
+	Temperature2m    []*float64 `json:"temperature_2m"`
+	WindDirection10m []*float64 `json:"wind_direction_10m"`
+}
+
+// Location returns the timezone of the times in the response.
+func (r *HistoricalWeatherResponse) Location() *time.Location {
+	return location(r.Timezone, r.TimezoneAbbreviation, r.UTCOffsetSeconds)
+}
+
+// Times parses the local times of the hourly data.
+func (r *HistoricalWeatherResponse) Times() ([]time.Time, error) {
+	return parseHourlyTimes(r.Hourly.Time, r.Location())
+}
+
+// DailyWeather returns the daily aggregates in the format of the daily weather.
+func (r *HistoricalWeatherResponse) DailyWeather() *DailyWeatherResponse {
+	return &DailyWeatherResponse{
+		Timezone:             r.Timezone,
+		TimezoneAbbreviation: r.TimezoneAbbreviation,
+		UTCOffsetSeconds:     r.UTCOffsetSeconds,
+		Daily:                r.Daily,
+	}
+}
+
+// append adds the data of the following chunk.
+func (r *HistoricalWeatherResponse) append(next *HistoricalWeatherResponse) {
+	r.Hourly.Time = append(r.Hourly.Time, next.Hourly.Time...)
+	r.Hourly.Temperature2m = append(r.Hourly.Temperature2m, next.Hourly.Temperature2m...)
+	r.Hourly.Precipitation = append(r.Hourly.Precipitation, next.Hourly.Precipitation...)
+	r.Hourly.WindSpeed10m = append(r.Hourly.WindSpeed10m, next.Hourly.WindSpeed10m...)
+	r.Hourly.WindDirection10m = append(r.Hourly.WindDirection10m, next.Hourly.WindDirection10m...)
+
+	r.Daily.Time = append(r.Daily.Time, next.Daily.Time...)
+	r.Daily.Temperature2mMin = append(r.Daily.Temperature2mMin, next.Daily.Temperature2mMin...)
+	r.Daily.Temperature2mMax = append(r.Daily.Temperature2mMax, next.Daily.Temperature2mMax...)
+	r.Daily.PrecipitationSum = append(r.Daily.PrecipitationSum, next.Daily.PrecipitationSum...)
+	r.Daily.WindSpeed10mMax = append(r.Daily.WindSpeed10mMax, next.Daily.WindSpeed10mMax...)
+	r.Daily.WindGusts10mMax = append(r.Daily.WindGusts10mMax, next.Daily.WindGusts10mMax...)
+	r.Daily.WindDirection10mDominant = append(r.Daily.WindDirection10mDominant, next.Daily.WindDirection10mDominant...)
+	r.Daily.Sunrise = append(r.Daily.Sunrise, next.Daily.Sunrise...)
+	r.Daily.Sunset = append(r.Daily.Sunset, next.Daily.Sunset...)
+	r.Daily.SunshineDuration = append(r.Daily.SunshineDuration, next.Daily.SunshineDuration...)
+}
+
+// GetHistoricalWeather retrieves the historical weather of multiple locations from the archive API (ERA5 reanalysis).
+// Long ranges are requested in chunks, the data is returned in the order of the given coordinates with
+// the local times of each location.
+func (r *openMeteoRepo) GetHistoricalWeather(ctx context.Context, coordinates []Coordinates, opts HistoricalOptions) ([]*HistoricalWeatherResponse, error) {
+	latitudes, longitudes := joinCoordinates(coordinates)
+
+	var responses []*HistoricalWeatherResponse
+	for _, chunk := range opts.chunks() {
+		query := url.Values{}
+		query.Set("latitude", latitudes)
+		query.Set("longitude", longitudes)
+		query.Set("start_date", chunk.From.Format(time.DateOnly))
+		query.Set("end_date", chunk.To.Format(time.DateOnly))
+		query.Set("timezone", "auto")
+		if opts.Resolution == ResolutionDaily {
+			query.Set("daily", dailyVariables)
+		} else {
+			query.Set("hourly", hourlyVariables)
+		}
+		slog.Debug("Fetching historical weather data", "query", query.Encode(), "locations", len(coordinates))
+
+		body, err := r.getFrom(ctx, r.archiveBaseURL, "/v1/archive", query)
+		if err != nil {
+			return nil, err
+		}
+
+		chunkResponses, err := decodeLocations[HistoricalWeatherResponse](body, len(coordinates))
+		if err != nil {
+			return nil, fmt.Errorf("invalid historical weather data: %w", err)
+		}
+
+		if responses == nil {
+			responses = chunkResponses
+			continue
+		}
+		for i, response := range chunkResponses {
+			responses[i].append(response)
+		}
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoricalOptionsValidate(t *testing.T) {
+	now := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		opts    HistoricalOptions
+		wantErr bool
+	}{
+		{"single day", HistoricalOptions{day(2024, 1, 1), day(2024, 1, 1), ResolutionHourly}, false},
+		{"last day of the reanalysis", HistoricalOptions{day(2024, 6, 1), day(2024, 6, 10), ResolutionHourly}, false},
+		{"a year of hourly weather", HistoricalOptions{day(2023, 1, 1), day(2023, 12, 31), ResolutionHourly}, false},
+		{"ten years of daily weather", HistoricalOptions{day(2014, 1, 1), day(2023, 12, 31), ResolutionDaily}, false},
+		{"unknown resolution", HistoricalOptions{day(2024, 1, 1), day(2024, 1, 1), "weekly"}, true},
+		{"from after to", HistoricalOptions{day(2024, 1, 2), day(2024, 1, 1), ResolutionHourly}, true},
+		{"before the archive", HistoricalOptions{day(1939, 12, 31), day(1940, 1, 31), ResolutionDaily}, true},
+		{"within the delay of the reanalysis", HistoricalOptions{day(2024, 6, 1), day(2024, 6, 11), ResolutionHourly}, true},
+		{"today", HistoricalOptions{day(2024, 6, 1), day(2024, 6, 15), ResolutionHourly}, true},
+		{"too long for hourly weather", HistoricalOptions{day(2022, 1, 1), day(2023, 12, 31), ResolutionHourly}, true},
+		{"too long for daily weather", HistoricalOptions{day(2000, 1, 1), day(2023, 12, 31), ResolutionDaily}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate(now)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetHistoricalWeather(t *testing.T) {
+	t.Run("success with hourly weather", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/archive", r.URL.Path)
+			assert.Equal(t, hourlyVariables, r.URL.Query().Get("hourly"))
+			assert.Empty(t, r.URL.Query().Get("daily"))
+			assert.Equal(t, "2024-01-01", r.URL.Query().Get("start_date"))
+			assert.Equal(t, "2024-01-01", r.URL.Query().Get("end_date"))
+			assert.Equal(t, "auto", r.URL.Query().Get("timezone"))
+			fmt.Fprint(w, `{"timezone":"Asia/Tokyo","utc_offset_seconds":32400,"hourly":{"time":["2024-01-01T00:00"],"temperature_2m":[2.5],"precipitation":[0],"wind_speed_10m":[3.1],"wind_direction_10m":[270]}}`)
+		})
+
+		day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		historical, err := repo.GetHistoricalWeather(context.Background(), []Coordinates{{Latitude: 37.4513, Longitude: 141.0334}},
+			HistoricalOptions{From: day, To: day, Resolution: ResolutionHourly})
+		assert.NoError(t, err)
+		assert.Len(t, historical, 1)
+		assert.Equal(t, "Asia/Tokyo", historical[0].Location().String())
+		assert.Equal(t, 2.5, *historical[0].Hourly.Temperature2m[0])
+	})
+
+	t.Run("success with hours the reanalysis has no data for", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"timezone":"Asia/Tokyo","hourly":{"time":["2024-01-01T22:00","2024-01-01T23:00"],"temperature_2m":[2.5,null],"precipitation":[0,null],"wind_speed_10m":[3.1,null],"wind_direction_10m":[270,null]}}`)
+		})
+
+		day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		historical, err := repo.GetHistoricalWeather(context.Background(), []Coordinates{{Latitude: 37.4513, Longitude: 141.0334}},
+			HistoricalOptions{From: day, To: day, Resolution: ResolutionHourly})
+		assert.NoError(t, err)
+		hourly := historical[0].Hourly
+		assert.Len(t, hourly.Time, 2)
+		assert.Equal(t, 0.0, *hourly.Precipitation[0])
+		assert.Nil(t, hourly.Temperature2m[1])
+		assert.Nil(t, hourly.Precipitation[1])
+		assert.Nil(t, hourly.WindSpeed10m[1])
+		assert.Nil(t, hourly.WindDirection10m[1])
+	})
+
+	t.Run("request long ranges in chunks", func(t *testing.T) {
+		var mu sync.Mutex
+		var ranges []string
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			assert.Equal(t, dailyVariables, query.Get("daily"))
+
+			mu.Lock()
+			ranges = append(ranges, query.Get("start_date")+"/"+query.Get("end_date"))
+			mu.Unlock()
+
+			fmt.Fprintf(w, `[{"timezone":"Asia/Tokyo","daily":{"time":[%[1]q],"temperature_2m_max":[9.5]}},{"timezone":"Europe/Berlin","daily":{"time":[%[1]q],"temperature_2m_max":[1.5]}}]`, query.Get("start_date"))
+		})
+
+		historical, err := repo.GetHistoricalWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}},
+			HistoricalOptions{
+				From:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+				Resolution: ResolutionDaily,
+			})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2023-01-01/2023-04-02", "2023-04-03/2023-07-03", "2023-07-04/2023-10-03", "2023-10-04/2023-12-31"}, ranges)
+		assert.Len(t, historical, 2)
+		assert.Equal(t, []string{"2023-01-01", "2023-04-03", "2023-07-04", "2023-10-04"}, historical[0].Daily.Time)
+		assert.Equal(t, "Europe/Berlin", historical[1].DailyWeather().Location().String())
+		assert.Len(t, historical[1].Daily.Temperature2mMax, 4)
+	})
+
+	t.Run("fail due to a failed chunk", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("start_date") != "2023-01-01" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"timezone":"Asia/Tokyo","daily":{"time":["2023-01-01"]}}`)
+		})
+
+		_, err := repo.GetHistoricalWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}},
+			HistoricalOptions{
+				From:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+				Resolution: ResolutionDaily,
+			})
+		assert.Error(t, err)
+	})
+}
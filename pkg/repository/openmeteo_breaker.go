@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the Open-Meteo API while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open, Open-Meteo API is unavailable")
+
+// BreakerOptions configures the circuit breaker of the Open-Meteo API.
+type BreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed requests that opens the circuit, 0 uses the default.
+	FailureThreshold int
+	// ProbeInterval is the interval the API is probed in while the circuit is open, 0 uses the default.
+	ProbeInterval time.Duration
+	// ProbeTimeout is the timeout of a probe request, 0 uses the default.
+	ProbeTimeout time.Duration
+}
+
+// DefaultBreakerOptions are used for options that are not set.
+var DefaultBreakerOptions = BreakerOptions{
+	FailureThreshold: 5,
+	ProbeInterval:    10 * time.Second,
+	ProbeTimeout:     5 * time.Second,
+}
+
+// probeCoordinates is the location requested to probe if an endpoint of the API is available again.
+var probeCoordinates = Coordinates{Latitude: 52.52, Longitude: 13.41}
+
+// probeDay is the day of the historical weather requested to probe the archive, it is always in the archive.
+var probeDay = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// breakerMetrics counts the trips and rejected requests of the circuit breakers per endpoint, e.g. forecast_trips,
+// they are published at /debug/vars.
+var breakerMetrics = expvar.NewMap("openmeteo_circuit_breaker")
+
+// endpoint is an endpoint of the Open-Meteo API with its own circuit, the endpoints fail independently,
+// e.g. the archive is served by other hosts than the forecasts.
+type endpoint string
+
+const (
+	endpointElevation endpoint = "elevation"
+	endpointForecast  endpoint = "forecast"
+	endpointArchive   endpoint = "archive"
+)
+
+// circuit is the circuit breaker of an endpoint.
+type circuit struct {
+	endpoint endpoint
+	opts     BreakerOptions
+	// probe requests the endpoint to check if it is available again
+	probe func(ctx context.Context) error
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+}
+
+type breakerOpenMeteoRepo struct {
+	next     OpenMeteoRepository
+	circuits map[endpoint]*circuit
+}
+
+// NewBreakerOpenMeteoRepository creates an OpenMeteoRepository that stops calling an endpoint of next after repeated
+// failures. While the circuit of an endpoint is open, its requests fail with ErrCircuitOpen and the endpoint is
+// probed in the background until it is available again.
+func NewBreakerOpenMeteoRepository(next OpenMeteoRepository, opts BreakerOptions) OpenMeteoRepository {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = DefaultBreakerOptions.FailureThreshold
+	}
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = DefaultBreakerOptions.ProbeInterval
+	}
+	if opts.ProbeTimeout <= 0 {
+		opts.ProbeTimeout = DefaultBreakerOptions.ProbeTimeout
+	}
+
+	probe := []Coordinates{probeCoordinates}
+	probes := map[endpoint]func(ctx context.Context) error{
+		endpointElevation: func(ctx context.Context) error {
+			_, err := next.GetElevations(ctx, probe)
+			return err
+		},
+		endpointForecast: func(ctx context.Context) error {
+			_, err := next.GetWeatherForecasts(ctx, probe, ForecastOptions{ForecastDays: 1})
+			return err
+		},
+		endpointArchive: func(ctx context.Context) error {
+			_, err := next.GetHistoricalWeather(ctx, probe, HistoricalOptions{From: probeDay, To: probeDay, Resolution: ResolutionDaily})
+			return err
+		},
+	}
+
+	circuits := make(map[endpoint]*circuit, len(probes))
+	for e, probe := range probes {
+		circuits[e] = &circuit{endpoint: e, opts: opts, probe: probe}
+	}
+	return &breakerOpenMeteoRepo{
+		next:     next,
+		circuits: circuits,
+	}
+}
+
+// GetElevation retrieves elevation data if the circuit of the elevation endpoint is closed.
+func (r *breakerOpenMeteoRepo) GetElevation(ctx context.Context, latitude, longitude float64) (float64, error) {
+	c := r.circuits[endpointElevation]
+	if err := c.allow(); err != nil {
+		return 0, err
+	}
+
+	elevation, err := r.next.GetElevation(ctx, latitude, longitude)
+	c.record(ctx, err)
+	return elevation, err
+}
+
+// GetWeatherForecast retrieves weather forecast data if the circuit of the forecast endpoint is closed.
+func (r *breakerOpenMeteoRepo) GetWeatherForecast(ctx context.Context, latitude, longitude float64, opts ForecastOptions) (*WeatherForecastResponse, error) {
+	c := r.circuits[endpointForecast]
+	if err := c.allow(); err != nil {
+		return nil, err
+	}
+
+	forecast, err := r.next.GetWeatherForecast(ctx, latitude, longitude, opts)
+	c.record(ctx, err)
+	return forecast, err
+}
+
+// GetElevations retrieves elevation data for multiple locations if the circuit of the elevation endpoint is closed.
+func (r *breakerOpenMeteoRepo) GetElevations(ctx context.Context, coordinates []Coordinates) ([]float64, error) {
+	c := r.circuits[endpointElevation]
+	if err := c.allow(); err != nil {
+		return nil, err
+	}
+
+	elevations, err := r.next.GetElevations(ctx, coordinates)
+	c.record(ctx, err)
+	return elevations, err
+}
+
+// GetWeatherForecasts retrieves weather forecast data for multiple locations if the circuit of the forecast
+// endpoint is closed.
+func (r *breakerOpenMeteoRepo) GetWeatherForecasts(ctx context.Context, coordinates []Coordinates, opts ForecastOptions) ([]*WeatherForecastResponse, error) {
+	c := r.circuits[endpointForecast]
+	if err := c.allow(); err != nil {
+		return nil, err
+	}
+
+	forecasts, err := r.next.GetWeatherForecasts(ctx, coordinates, opts)
+	c.record(ctx, err)
+	return forecasts, err
+}
+
+// GetDailyWeather retrieves daily weather data for multiple locations if the circuit of the forecast endpoint,
+// which also serves the daily aggregates, is closed.
+func (r *breakerOpenMeteoRepo) GetDailyWeather(ctx context.Context, coordinates []Coordinates, days int) ([]*DailyWeatherResponse, error) {
+	c := r.circuits[endpointForecast]
+	if err := c.allow(); err != nil {
+		return nil, err
+	}
+
+	daily, err := r.next.GetDailyWeather(ctx, coordinates, days)
+	c.record(ctx, err)
+	return daily, err
+}
+
+// GetHistoricalWeather retrieves historical weather data for multiple locations if the circuit of the archive
+// endpoint is closed.
+func (r *breakerOpenMeteoRepo) GetHistoricalWeather(ctx context.Context, coordinates []Coordinates, opts HistoricalOptions) ([]*HistoricalWeatherResponse, error) {
+	c := r.circuits[endpointArchive]
+	if err := c.allow(); err != nil {
+		return nil, err
+	}
+
+	historical, err := r.next.GetHistoricalWeather(ctx, coordinates, opts)
+	c.record(ctx, err)
+	return historical, err
+}
+
+// allow returns ErrCircuitOpen if the circuit is open.
+func (c *circuit) allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.open {
+		breakerMetrics.Add(string(c.endpoint)+"_rejected", 1)
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record counts the consecutive failures of the endpoint and opens the circuit once the threshold is reached.
+// Only failures of the API count, not invalid requests or requests canceled by the caller.
+func (c *circuit) record(ctx context.Context, err error) {
+	var retryErr *retryableError
+	failed := err != nil && errors.As(err, &retryErr) && ctx.Err() == nil
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !failed {
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.failures < c.opts.FailureThreshold || c.open {
+		return
+	}
+
+	slog.Warn("Open-Meteo API failed repeatedly, opening the circuit", "error", err, "endpoint", c.endpoint, "failures", c.failures)
+	breakerMetrics.Add(string(c.endpoint)+"_trips", 1)
+	c.open = true
+	go c.probeUntilAvailable()
+}
+
+// probeUntilAvailable requests the endpoint until it is available again and closes the circuit.
+func (c *circuit) probeUntilAvailable() {
+	ticker := time.NewTicker(c.opts.ProbeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.ProbeTimeout)
+		err := c.probe(ctx)
+		cancel()
+		if err != nil {
+			slog.Debug("Open-Meteo API is still unavailable", "error", err, "endpoint", c.endpoint)
+			continue
+		}
+
+		slog.Info("Open-Meteo API is available again, closing the circuit", "endpoint", c.endpoint)
+		c.mu.Lock()
+		c.open = false
+		c.failures = 0
+		c.mu.Unlock()
+		return
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	berlin := []Coordinates{{Latitude: 52.52, Longitude: 13.41}}
+
+	t.Run("open the circuit after consecutive failures", func(t *testing.T) {
+		repo, api := setupBreakerTests(t)
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.GetElevations(context.Background(), berlin)
+			assert.ErrorIs(t, err, assert.AnError)
+		}
+
+		_, err := repo.GetElevations(context.Background(), berlin)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), api.calls.Load())
+	})
+
+	t.Run("do not count invalid requests", func(t *testing.T) {
+		repo, api := setupBreakerTests(t)
+		api.err = assert.AnError
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.GetElevations(context.Background(), berlin)
+			assert.ErrorIs(t, err, assert.AnError)
+		}
+		assert.Equal(t, int32(3), api.calls.Load())
+	})
+
+	t.Run("do not count requests canceled by the caller", func(t *testing.T) {
+		repo, api := setupBreakerTests(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.GetElevations(ctx, berlin)
+			assert.ErrorIs(t, err, assert.AnError)
+		}
+		assert.Equal(t, int32(3), api.calls.Load())
+	})
+
+	t.Run("reset the failures after a success", func(t *testing.T) {
+		repo, api := setupBreakerTests(t)
+
+		_, _ = repo.GetElevations(context.Background(), berlin)
+		api.available.Store(true)
+		_, _ = repo.GetElevations(context.Background(), berlin)
+		api.available.Store(false)
+		_, err := repo.GetElevations(context.Background(), berlin)
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("close the circuit once the probe succeeds", func(t *testing.T) {
+		repo, api := setupBreakerTests(t)
+
+		for i := 0; i < 2; i++ {
+			_, _ = repo.GetWeatherForecasts(context.Background(), berlin, ForecastOptions{})
+		}
+		_, err := repo.GetWeatherForecasts(context.Background(), berlin, ForecastOptions{})
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		api.available.Store(true)
+		assert.Eventually(t, func() bool {
+			_, err := repo.GetWeatherForecasts(context.Background(), berlin, ForecastOptions{})
+			return err == nil
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("open the circuit only for the failing endpoint", func(t *testing.T) {
+		repo, _ := setupBreakerTests(t)
+
+		for i := 0; i < 2; i++ {
+			_, _ = repo.GetWeatherForecasts(context.Background(), berlin, ForecastOptions{})
+		}
+		_, err := repo.GetDailyWeather(context.Background(), berlin, 7)
+		assert.ErrorIs(t, err, ErrCircuitOpen, "the daily weather is served by the forecast endpoint")
+
+		_, err = repo.GetElevations(context.Background(), berlin)
+		assert.ErrorIs(t, err, assert.AnError)
+		_, err = repo.GetHistoricalWeather(context.Background(), berlin, HistoricalOptions{})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("probe the endpoint that opened the circuit", func(t *testing.T) {
+		repo, api := setupBreakerTests(t)
+
+		for i := 0; i < 2; i++ {
+			_, _ = repo.GetHistoricalWeather(context.Background(), berlin, HistoricalOptions{})
+		}
+		assert.Eventually(t, func() bool { return api.historicalCalls.Load() > 2 }, time.Second, time.Millisecond)
+
+		api.available.Store(true)
+		assert.Eventually(t, func() bool {
+			_, err := repo.GetHistoricalWeather(context.Background(), berlin, HistoricalOptions{})
+			return err == nil
+		}, time.Second, 5*time.Millisecond)
+		assert.Zero(t, api.elevationCalls.Load())
+	})
+}
+
+// fakeFlakyAPI fails with a retryable error while it is not available, or always with err if it is set.
+type fakeFlakyAPI struct {
+	OpenMeteoRepository
+	err       error
+	available atomic.Bool
+	calls     atomic.Int32
+
+	elevationCalls  atomic.Int32
+	historicalCalls atomic.Int32
+}
+
+func (f *fakeFlakyAPI) result() error {
+	f.calls.Add(1)
+	if f.err != nil {
+		return f.err
+	}
+	if !f.available.Load() {
+		return &retryableError{err: assert.AnError}
+	}
+	return nil
+}
+
+func (f *fakeFlakyAPI) GetElevation(context.Context, float64, float64) (float64, error) {
+	f.elevationCalls.Add(1)
+	return 34, f.result()
+}
+
+func (f *fakeFlakyAPI) GetElevations(_ context.Context, coordinates []Coordinates) ([]float64, error) {
+	f.elevationCalls.Add(1)
+	return make([]float64, len(coordinates)), f.result()
+}
+
+func (f *fakeFlakyAPI) GetWeatherForecasts(_ context.Context, coordinates []Coordinates, _ ForecastOptions) ([]*WeatherForecastResponse, error) {
+	return make([]*WeatherForecastResponse, len(coordinates)), f.result()
+}
+
+func (f *fakeFlakyAPI) GetDailyWeather(_ context.Context, coordinates []Coordinates, _ int) ([]*DailyWeatherResponse, error) {
+	return make([]*DailyWeatherResponse, len(coordinates)), f.result()
+}
+
+func (f *fakeFlakyAPI) GetHistoricalWeather(_ context.Context, coordinates []Coordinates, _ HistoricalOptions) ([]*HistoricalWeatherResponse, error) {
+	f.historicalCalls.Add(1)
+	return make([]*HistoricalWeatherResponse, len(coordinates)), f.result()
+}
+
+func setupBreakerTests(t *testing.T) (OpenMeteoRepository, *fakeFlakyAPI) {
+	api := &fakeFlakyAPI{}
+	repo := NewBreakerOpenMeteoRepository(api, BreakerOptions{FailureThreshold: 2, ProbeInterval: time.Millisecond})
+
+	return repo, api
+}
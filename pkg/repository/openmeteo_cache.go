@@ -0,0 +1,440 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// CacheOptions configures the Open-Meteo cache.
+type CacheOptions struct {
+	// Size is the maximum number of responses kept in memory.
+	Size int
+	// ElevationTTL is how long elevations are cached, they don't change so it can be long.
+	ElevationTTL time.Duration
+	// ForecastUpdateInterval is the interval Open-Meteo updates its forecasts in,
+	// cached forecasts expire at the next update.
+	ForecastUpdateInterval time.Duration
+	// HistoricalTTL is how long historical weather is cached, the archive corrects the last days
+	// with the final reanalysis so it should not be too long.
+	HistoricalTTL time.Duration
+	// LoadTimeout is the deadline of loading missing responses from the api. A load is shared by concurrent
+	// callers, so it is not canceled with the caller that started it.
+	LoadTimeout time.Duration
+	// Store is the optional persistent tier of the cache, nil disables it.
+	Store CacheStore
+}
+
+// DefaultCacheOptions are used for options that are not set.
+var DefaultCacheOptions = CacheOptions{
+	Size:                   10000,
+	ElevationTTL:           30 * 24 * time.Hour,
+	ForecastUpdateInterval: time.Hour,
+	HistoricalTTL:          24 * time.Hour,
+	LoadTimeout:            30 * time.Second,
+}
+
+const (
+	// elevationKeyPrecision is the number of decimals of the coordinates in elevation cache keys (~11 m),
+	// finer than the 90 m resolution of the elevation model.
+	elevationKeyPrecision = 4
+	// forecastKeyPrecision is the number of decimals of the coordinates in forecast cache keys (~1.1 km),
+	// finer than the grids of the weather models.
+	forecastKeyPrecision = 2
+)
+
+// cacheMetrics counts the hits and misses of the Open-Meteo cache, they are published at /debug/vars.
+var cacheMetrics = expvar.NewMap("openmeteo_cache")
+
+type cacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// cacheLoad is the load of a missing key, done is closed once value or err is set.
+type cacheLoad struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+type cachedOpenMeteoRepo struct {
+	next   OpenMeteoRepository
+	opts   CacheOptions
+	memory *lru.Cache[string, cacheEntry]
+	now    func() time.Time
+
+	// loads are the loads in flight by key, they are shared by concurrent callers
+	loadsMu sync.Mutex
+	loads   map[string]*cacheLoad
+}
+
+// NewCachedOpenMeteoRepository creates an OpenMeteoRepository that caches the responses of next in memory
+// and, if a store is configured, in the store. A location requested concurrently is sent to next only once.
+func NewCachedOpenMeteoRepository(next OpenMeteoRepository, opts CacheOptions) (OpenMeteoRepository, error) {
+	if opts.Size <= 0 {
+		opts.Size = DefaultCacheOptions.Size
+	}
+	if opts.ElevationTTL <= 0 {
+		opts.ElevationTTL = DefaultCacheOptions.ElevationTTL
+	}
+	if opts.ForecastUpdateInterval <= 0 {
+		opts.ForecastUpdateInterval = DefaultCacheOptions.ForecastUpdateInterval
+	}
+	if opts.HistoricalTTL <= 0 {
+		opts.HistoricalTTL = DefaultCacheOptions.HistoricalTTL
+	}
+	if opts.LoadTimeout <= 0 {
+		opts.LoadTimeout = DefaultCacheOptions.LoadTimeout
+	}
+
+	memory, err := lru.New[string, cacheEntry](opts.Size)
+	if err != nil {
+		return nil, fmt.Errorf("error creating in-memory cache: %w", err)
+	}
+
+	return &cachedOpenMeteoRepo{
+		next:   next,
+		opts:   opts,
+		memory: memory,
+		now:    time.Now,
+		loads:  map[string]*cacheLoad{},
+	}, nil
+}
+
+// GetElevation retrieves the elevation of a location from the cache or the api.
+func (r *cachedOpenMeteoRepo) GetElevation(ctx context.Context, latitude, longitude float64) (float64, error) {
+	elevations, err := r.GetElevations(ctx, []Coordinates{{Latitude: latitude, Longitude: longitude}})
+	if err != nil {
+		return 0, err
+	}
+
+	return elevations[0], nil
+}
+
+// GetWeatherForecast retrieves the weather forecast of a location from the cache or the api.
+func (r *cachedOpenMeteoRepo) GetWeatherForecast(ctx context.Context, latitude, longitude float64, opts ForecastOptions) (*WeatherForecastResponse, error) {
+	forecasts, err := r.GetWeatherForecasts(ctx, []Coordinates{{Latitude: latitude, Longitude: longitude}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return forecasts[0], nil
+}
+
+// GetElevations retrieves the elevations of multiple locations, the locations missing in the cache
+// are requested from the api with a single request.
+func (r *cachedOpenMeteoRepo) GetElevations(ctx context.Context, coordinates []Coordinates) ([]float64, error) {
+	keys := make([]string, len(coordinates))
+	for i, c := range coordinates {
+		keys[i] = "elevation:" + roundCoordinates(c, elevationKeyPrecision)
+	}
+
+	expiresAt := r.now().Add(r.opts.ElevationTTL)
+	load := func(ctx context.Context, indexes []int) ([]float64, error) {
+		return r.next.GetElevations(ctx, pickCoordinates(coordinates, indexes))
+	}
+	// elevations don't change, an expired elevation is as good as a new one
+	markStale := func(elevation float64) float64 { return elevation }
+
+	return loadCached(ctx, r, "elevation", keys, expiresAt, load, markStale)
+}
+
+// GetWeatherForecasts retrieves the weather forecasts of multiple locations, the locations missing in the cache
+// are requested from the api with a single request. Cached forecasts expire at the next forecast update.
+// If the api is not available, the last known forecasts are returned marked as stale.
+func (r *cachedOpenMeteoRepo) GetWeatherForecasts(ctx context.Context, coordinates []Coordinates, opts ForecastOptions) ([]*WeatherForecastResponse, error) {
+	keys := make([]string, len(coordinates))
+	for i, c := range coordinates {
+		keys[i] = forecastKey(c, opts)
+	}
+
+	expiresAt := r.now().Truncate(r.opts.ForecastUpdateInterval).Add(r.opts.ForecastUpdateInterval)
+	load := func(ctx context.Context, indexes []int) ([]*WeatherForecastResponse, error) {
+		return r.next.GetWeatherForecasts(ctx, pickCoordinates(coordinates, indexes), opts)
+	}
+	markStale := func(forecast *WeatherForecastResponse) *WeatherForecastResponse {
+		// the cached forecast is shared, mark a copy
+		stale := *forecast
+		stale.Stale = true
+		return &stale
+	}
+
+	return loadCached(ctx, r, "forecast", keys, expiresAt, load, markStale)
+}
+
+// GetDailyWeather retrieves the daily aggregates of multiple locations, the locations missing in the cache
+// are requested from the api with a single request. They are cached like the forecasts, including the
+// fallback to the last known aggregates.
+func (r *cachedOpenMeteoRepo) GetDailyWeather(ctx context.Context, coordinates []Coordinates, days int) ([]*DailyWeatherResponse, error) {
+	keys := make([]string, len(coordinates))
+	for i, c := range coordinates {
+		keys[i] = fmt.Sprintf("daily:%s:%d", roundCoordinates(c, forecastKeyPrecision), days)
+	}
+
+	expiresAt := r.now().Truncate(r.opts.ForecastUpdateInterval).Add(r.opts.ForecastUpdateInterval)
+	load := func(ctx context.Context, indexes []int) ([]*DailyWeatherResponse, error) {
+		return r.next.GetDailyWeather(ctx, pickCoordinates(coordinates, indexes), days)
+	}
+	markStale := func(daily *DailyWeatherResponse) *DailyWeatherResponse {
+		stale := *daily
+		stale.Stale = true
+		return &stale
+	}
+
+	return loadCached(ctx, r, "daily", keys, expiresAt, load, markStale)
+}
+
+// GetHistoricalWeather retrieves the historical weather of multiple locations, the locations missing in the cache
+// are requested from the api. The cached weather is kept for the HistoricalTTL, if the api fails the last
+// known weather is returned.
+func (r *cachedOpenMeteoRepo) GetHistoricalWeather(ctx context.Context, coordinates []Coordinates, opts HistoricalOptions) ([]*HistoricalWeatherResponse, error) {
+	keys := make([]string, len(coordinates))
+	for i, c := range coordinates {
+		keys[i] = fmt.Sprintf("historical:%s:%s:%s:%s", roundCoordinates(c, forecastKeyPrecision),
+			opts.From.Format(time.DateOnly), opts.To.Format(time.DateOnly), opts.Resolution)
+	}
+
+	expiresAt := r.now().Add(r.opts.HistoricalTTL)
+	load := func(ctx context.Context, indexes []int) ([]*HistoricalWeatherResponse, error) {
+		return r.next.GetHistoricalWeather(ctx, pickCoordinates(coordinates, indexes), opts)
+	}
+	// historical weather doesn't go stale the way forecasts do, the last known weather is returned as is
+	markStale := func(historical *HistoricalWeatherResponse) *HistoricalWeatherResponse {
+		return historical
+	}
+
+	return loadCached(ctx, r, "historical", keys, expiresAt, load, markStale)
+}
+
+// loadCached returns the values of the keys from the cache and loads the missing values with a single call of load,
+// load gets the indexes of the missing keys. Keys that are already loaded by a concurrent caller are not loaded
+// again. The loaded values are cached until expiresAt. If the load of a key fails and the key has an expired value
+// in the cache, that value is returned marked by markStale.
+func loadCached[T any](ctx context.Context, r *cachedOpenMeteoRepo, kind string, keys []string, expiresAt time.Time,
+	load func(ctx context.Context, indexes []int) ([]T, error), markStale func(T) T) ([]T, error) {
+	values := make([]T, len(keys))
+	now := r.now()
+
+	// the same key can be requested multiple times, e.g. for close locations
+	missing := map[string][]int{}
+	stale := map[string]T{}
+	var missingKeys []string
+	var missingIndexes []int
+	for i, key := range keys {
+		entry, tier, ok := lookup[T](ctx, r, key)
+		if ok && now.Before(entry.expiresAt) {
+			cacheMetrics.Add(kind+"_"+tier+"_hits", 1)
+			values[i] = entry.value.(T)
+			continue
+		}
+		if ok {
+			stale[key] = entry.value.(T)
+		}
+		if _, ok := missing[key]; !ok {
+			missingKeys = append(missingKeys, key)
+			missingIndexes = append(missingIndexes, i)
+		}
+		missing[key] = append(missing[key], i)
+	}
+
+	if len(missingKeys) == 0 {
+		return values, nil
+	}
+	cacheMetrics.Add(kind+"_misses", int64(len(missingKeys)))
+
+	loads := r.startLoads(missingKeys)
+	var ownKeys []string
+	var ownIndexes []int
+	var own []*cacheLoad
+	for j, l := range loads {
+		if l.owned {
+			ownKeys = append(ownKeys, missingKeys[j])
+			ownIndexes = append(ownIndexes, missingIndexes[j])
+			own = append(own, l.cacheLoad)
+		}
+	}
+	if shared := len(missingKeys) - len(ownKeys); shared > 0 {
+		slog.Debug("Shared Open-Meteo request with a concurrent caller", "kind", kind, "locations", shared)
+	}
+	if len(own) > 0 {
+		go loadMissing(ctx, r, ownKeys, ownIndexes, own, expiresAt, load)
+	}
+
+	var staleKeys int
+	var loadErr error
+	for j, key := range missingKeys {
+		l := loads[j].cacheLoad
+		select {
+		case <-l.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		var value T
+		switch cached, ok := stale[key]; {
+		case l.err == nil:
+			value = l.value.(T)
+		case ok:
+			value = markStale(cached)
+			staleKeys++
+			loadErr = l.err
+		default:
+			return nil, l.err
+		}
+		for _, i := range missing[key] {
+			values[i] = value
+		}
+	}
+
+	if staleKeys > 0 {
+		slog.Warn("Can't load Open-Meteo responses, using the last known responses", "error", loadErr, "kind", kind, "locations", staleKeys)
+		cacheMetrics.Add(kind+"_stale_hits", int64(staleKeys))
+	}
+
+	return values, nil
+}
+
+// ownedLoad is the load of a key and whether the caller has to load the key.
+type ownedLoad struct {
+	*cacheLoad
+	owned bool
+}
+
+// startLoads returns the loads of the keys, the loads in flight are shared and the others are owned by the caller.
+func (r *cachedOpenMeteoRepo) startLoads(keys []string) []ownedLoad {
+	r.loadsMu.Lock()
+	defer r.loadsMu.Unlock()
+
+	loads := make([]ownedLoad, len(keys))
+	for j, key := range keys {
+		if l, ok := r.loads[key]; ok {
+			loads[j] = ownedLoad{cacheLoad: l}
+			continue
+		}
+		l := &cacheLoad{done: make(chan struct{})}
+		r.loads[key] = l
+		loads[j] = ownedLoad{cacheLoad: l, owned: true}
+	}
+	return loads
+}
+
+// loadMissing loads the keys with a single call of load, caches the values and completes the loads.
+// The load is detached from the cancellation of ctx, as concurrent callers wait for it.
+func loadMissing[T any](ctx context.Context, r *cachedOpenMeteoRepo, keys []string, indexes []int, loads []*cacheLoad,
+	expiresAt time.Time, load func(ctx context.Context, indexes []int) ([]T, error)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.opts.LoadTimeout)
+	defer cancel()
+
+	loaded, err := load(ctx, indexes)
+	if err == nil && len(loaded) != len(keys) {
+		err = fmt.Errorf("got %d Open-Meteo responses for %d locations", len(loaded), len(keys))
+	}
+	for j, key := range keys {
+		if err != nil {
+			loads[j].err = err
+			continue
+		}
+		loads[j].value = loaded[j]
+		r.setCached(ctx, key, loaded[j], expiresAt)
+	}
+
+	r.loadsMu.Lock()
+	for _, key := range keys {
+		delete(r.loads, key)
+	}
+	r.loadsMu.Unlock()
+	for _, l := range loads {
+		close(l.done)
+	}
+}
+
+// lookup returns the cache entry of the key, also if it is expired, and the tier of the cache it was found in.
+// Entries found in the store are added to the memory.
+func lookup[T any](ctx context.Context, r *cachedOpenMeteoRepo, key string) (cacheEntry, string, bool) {
+	if entry, ok := r.memory.Get(key); ok {
+		return entry, "memory", true
+	}
+
+	if r.opts.Store == nil {
+		return cacheEntry{}, "", false
+	}
+
+	item, err := r.opts.Store.Get(ctx, key)
+	if err != nil {
+		cacheMetrics.Add("store_errors", 1)
+		slog.Warn("Can't get Open-Meteo response from the cache store", "error", err, "key", key)
+		return cacheEntry{}, "", false
+	}
+	if item == nil {
+		return cacheEntry{}, "", false
+	}
+
+	var value T
+	if err := json.Unmarshal(item.Value, &value); err != nil {
+		slog.Warn("Can't decode Open-Meteo response from the cache store", "error", err, "key", key)
+		return cacheEntry{}, "", false
+	}
+
+	entry := cacheEntry{value: value, expiresAt: item.ExpiresAt}
+	r.memory.Add(key, entry)
+	return entry, "store", true
+}
+
+// setCached stores the value in memory and, if configured, in the store.
+func (r *cachedOpenMeteoRepo) setCached(ctx context.Context, key string, value any, expiresAt time.Time) {
+	r.memory.Add(key, cacheEntry{value: value, expiresAt: expiresAt})
+
+	if r.opts.Store == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		slog.Warn("Can't encode Open-Meteo response for the cache store", "error", err, "key", key)
+		return
+	}
+	if err := r.opts.Store.Set(ctx, key, CacheItem{Value: encoded, ExpiresAt: expiresAt}); err != nil {
+		cacheMetrics.Add("store_errors", 1)
+		slog.Warn("Can't store Open-Meteo response in the cache store", "error", err, "key", key)
+	}
+}
+
+// forecastKey returns the cache key of the forecast of a location, the options that are not set
+// are left out so the keys of the base forecast do not change.
+func forecastKey(c Coordinates, opts ForecastOptions) string {
+	key := fmt.Sprintf("forecast:%s:%d:%d", roundCoordinates(c, forecastKeyPrecision), opts.ForecastDays, opts.PastDays)
+	if opts.Model != "" {
+		key += ":" + opts.Model
+	}
+	if opts.Variables != 0 {
+		key += fmt.Sprintf(":v%x", uint32(opts.Variables))
+	}
+	if opts.Variables.Has(GlobalTiltedIrradiance) {
+		key += fmt.Sprintf(":%g:%g", opts.Tilt, opts.Azimuth)
+	}
+	if units := opts.Units.WithDefaults(); !opts.Units.IsDefault() {
+		key += fmt.Sprintf(":%s:%s:%s", units.Temperature, units.WindSpeed, units.Precipitation)
+	}
+	return key
+}
+
+// roundCoordinates formats the coordinates rounded to the given number of decimals.
+func roundCoordinates(c Coordinates, decimals int) string {
+	return fmt.Sprintf("%.*f,%.*f", decimals, c.Latitude, decimals, c.Longitude)
+}
+
+// pickCoordinates returns the coordinates at the given indexes.
+func pickCoordinates(coordinates []Coordinates, indexes []int) []Coordinates {
+	picked := make([]Coordinates, 0, len(indexes))
+	for _, i := range indexes {
+		picked = append(picked, coordinates[i])
+	}
+	return picked
+}
@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedElevations(t *testing.T) {
+	t.Run("request only the locations missing in the cache", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+
+		_, err := repo.GetElevations(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}})
+		assert.NoError(t, err)
+
+		elevations, err := repo.GetElevations(context.Background(), []Coordinates{{Latitude: 1.00001, Longitude: 2}, {Latitude: 3, Longitude: 4}})
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{10, 30}, elevations)
+		assert.Equal(t, [][]Coordinates{{{Latitude: 1, Longitude: 2}}, {{Latitude: 3, Longitude: 4}}}, api.elevationRequests)
+	})
+
+	t.Run("request close locations only once", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+
+		elevations, err := repo.GetElevations(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 1.00001, Longitude: 2}})
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{10, 10}, elevations)
+		assert.Len(t, api.elevationRequests, 1)
+	})
+
+	t.Run("do not cache errors", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+		api.err = assert.AnError
+
+		_, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.ErrorIs(t, err, assert.AnError)
+
+		api.err = nil
+		elevation, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 10.0, elevation)
+	})
+}
+
+func TestCachedWeatherForecasts(t *testing.T) {
+	week := ForecastOptions{ForecastDays: 7}
+
+	t.Run("expire at the next forecast update", func(t *testing.T) {
+		repo, api, now := setupCacheTests(t, nil)
+
+		_, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+
+		*now = now.Add(50 * time.Minute) // 10:50
+		_, err = repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), api.forecastCalls.Load())
+
+		*now = now.Add(10 * time.Minute) // 11:00
+		_, err = repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), api.forecastCalls.Load())
+	})
+
+	t.Run("cache forecasts per options", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+
+		_, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		_, err = repo.GetWeatherForecast(context.Background(), 1, 2, ForecastOptions{ForecastDays: 1})
+		assert.NoError(t, err)
+
+		assert.Equal(t, int32(2), api.forecastCalls.Load())
+	})
+
+	t.Run("cache forecasts per model", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+
+		_, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		_, err = repo.GetWeatherForecast(context.Background(), 1, 2, ForecastOptions{ForecastDays: 7, Model: "gfs_seamless"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, int32(2), api.forecastCalls.Load())
+	})
+
+	t.Run("cache forecasts per variables and panel orientation", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+		gti := ForecastOptions{ForecastDays: 7, Variables: NewVariables(GlobalTiltedIrradiance), Tilt: 30}
+
+		for _, opts := range []ForecastOptions{week, gti, gti, {ForecastDays: 7, Variables: gti.Variables, Tilt: 45}} {
+			_, err := repo.GetWeatherForecast(context.Background(), 1, 2, opts)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(3), api.forecastCalls.Load())
+	})
+
+	t.Run("cache forecasts per units", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+		metric := ForecastOptions{ForecastDays: 7, Units: Units{Temperature: Celsius, WindSpeed: KilometersPerHour, Precipitation: Millimeters}}
+		knots := ForecastOptions{ForecastDays: 7, Units: Units{WindSpeed: Knots}}
+
+		for _, opts := range []ForecastOptions{week, metric, knots, knots} {
+			_, err := repo.GetWeatherForecast(context.Background(), 1, 2, opts)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(2), api.forecastCalls.Load())
+	})
+
+	t.Run("share concurrent identical requests", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+		api.delay = 20 * time.Millisecond
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				forecast, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+				assert.NoError(t, err)
+				assert.Equal(t, "Asia/Tokyo", forecast.Timezone)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), api.forecastCalls.Load())
+	})
+
+	t.Run("share the locations of overlapping concurrent requests", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+		api.delay = 20 * time.Millisecond
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+			assert.NoError(t, err)
+		}()
+		time.Sleep(5 * time.Millisecond)
+
+		forecasts, err := repo.GetWeatherForecasts(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}, week)
+		assert.NoError(t, err)
+		assert.Len(t, forecasts, 2)
+		wg.Wait()
+
+		assert.Equal(t, int32(2), api.forecastCalls.Load())
+		assert.Equal(t, int32(2), api.forecastLocations.Load())
+	})
+
+	t.Run("do not cancel a shared request with the caller that started it", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+		api.delay = 20 * time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.GetWeatherForecast(ctx, 1, 2, week)
+			assert.ErrorIs(t, err, context.Canceled)
+		}()
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+
+		forecast, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Tokyo", forecast.Timezone)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), api.forecastCalls.Load())
+	})
+
+	t.Run("use the store when the memory is empty", func(t *testing.T) {
+		store := &fakeCacheStore{items: map[string]CacheItem{}}
+		repo, api, _ := setupCacheTests(t, store)
+
+		_, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.Contains(t, store.items, "forecast:1.00,2.00:7:0")
+
+		// a second instance of the app only has the store
+		other, _, _ := setupCacheTests(t, store)
+		other.(*cachedOpenMeteoRepo).next = api
+		forecast, err := other.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Tokyo", forecast.Timezone)
+		assert.Equal(t, int32(1), api.forecastCalls.Load())
+	})
+
+	t.Run("return stale forecasts if the api fails", func(t *testing.T) {
+		repo, api, now := setupCacheTests(t, nil)
+
+		fresh, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.False(t, fresh.Stale)
+
+		*now = now.Add(2 * time.Hour)
+		api.err = ErrCircuitOpen
+		forecast, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.True(t, forecast.Stale)
+		assert.Equal(t, "Asia/Tokyo", forecast.Timezone)
+		assert.False(t, fresh.Stale, "the cached forecast must not be modified")
+
+		api.err = nil
+		forecast, err = repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+		assert.False(t, forecast.Stale)
+	})
+
+	t.Run("return stale forecasts only for the locations that failed", func(t *testing.T) {
+		repo, api, now := setupCacheTests(t, nil)
+
+		_, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+
+		*now = now.Add(2 * time.Hour)
+		api.delay = 20 * time.Millisecond
+		api.failLatitudes = map[float64]bool{1: true}
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			forecast, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+			assert.NoError(t, err)
+			assert.True(t, forecast.Stale)
+		}()
+		time.Sleep(5 * time.Millisecond)
+
+		// the first location is shared with the failing request, the second one is requested separately
+		forecasts, err := repo.GetWeatherForecasts(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}, week)
+		assert.NoError(t, err)
+		assert.True(t, forecasts[0].Stale)
+		assert.False(t, forecasts[1].Stale)
+		wg.Wait()
+	})
+
+	t.Run("fail if not all locations have stale forecasts", func(t *testing.T) {
+		repo, api, now := setupCacheTests(t, nil)
+
+		_, err := repo.GetWeatherForecast(context.Background(), 1, 2, week)
+		assert.NoError(t, err)
+
+		*now = now.Add(2 * time.Hour)
+		api.err = ErrCircuitOpen
+		_, err = repo.GetWeatherForecasts(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}, week)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+}
+
+func TestCachedDailyWeather(t *testing.T) {
+	t.Run("cache the daily weather per number of days", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+
+		for _, days := range []int{7, 7, 3} {
+			_, err := repo.GetDailyWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}}, days)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(2), api.dailyCalls.Load())
+	})
+
+	t.Run("return the last known daily weather marked as stale", func(t *testing.T) {
+		repo, api, now := setupCacheTests(t, nil)
+
+		_, err := repo.GetDailyWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}}, 7)
+		assert.NoError(t, err)
+
+		*now = now.Add(2 * time.Hour)
+		api.err = ErrCircuitOpen
+		daily, err := repo.GetDailyWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}}, 7)
+		assert.NoError(t, err)
+		assert.True(t, daily[0].Stale)
+	})
+}
+
+func TestCachedHistoricalWeather(t *testing.T) {
+	january := HistoricalOptions{
+		From:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+		Resolution: ResolutionHourly,
+	}
+
+	t.Run("cache the historical weather per range and resolution", func(t *testing.T) {
+		repo, api, _ := setupCacheTests(t, nil)
+
+		daily := january
+		daily.Resolution = ResolutionDaily
+		for _, opts := range []HistoricalOptions{january, january, daily} {
+			_, err := repo.GetHistoricalWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}}, opts)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(2), api.historicalCalls.Load())
+	})
+
+	t.Run("request the historical weather again after the ttl", func(t *testing.T) {
+		repo, api, now := setupCacheTests(t, nil)
+
+		_, err := repo.GetHistoricalWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}}, january)
+		assert.NoError(t, err)
+
+		*now = now.Add(DefaultCacheOptions.HistoricalTTL)
+		_, err = repo.GetHistoricalWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}}, january)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), api.historicalCalls.Load())
+	})
+}
+
+type fakeOpenMeteoAPI struct {
+	OpenMeteoRepository
+	err               error
+	delay             time.Duration
+	elevationRequests [][]Coordinates
+	failLatitudes     map[float64]bool
+	forecastCalls     atomic.Int32
+	forecastLocations atomic.Int32
+	dailyCalls        atomic.Int32
+	historicalCalls   atomic.Int32
+}
+
+func (f *fakeOpenMeteoAPI) GetElevations(_ context.Context, coordinates []Coordinates) ([]float64, error) {
+	f.elevationRequests = append(f.elevationRequests, coordinates)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	elevations := make([]float64, len(coordinates))
+	for i, c := range coordinates {
+		elevations[i] = float64(int(c.Latitude * 10))
+	}
+	return elevations, nil
+}
+
+func (f *fakeOpenMeteoAPI) GetWeatherForecasts(ctx context.Context, coordinates []Coordinates, _ ForecastOptions) ([]*WeatherForecastResponse, error) {
+	f.forecastCalls.Add(1)
+	f.forecastLocations.Add(int32(len(coordinates)))
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, c := range coordinates {
+		if f.failLatitudes[c.Latitude] {
+			return nil, ErrCircuitOpen
+		}
+	}
+
+	forecasts := make([]*WeatherForecastResponse, len(coordinates))
+	for i := range coordinates {
+		forecasts[i] = &WeatherForecastResponse{Timezone: "Asia/Tokyo", UTCOffsetSeconds: 32400}
+	}
+	return forecasts, nil
+}
+
+func (f *fakeOpenMeteoAPI) GetDailyWeather(_ context.Context, coordinates []Coordinates, _ int) ([]*DailyWeatherResponse, error) {
+	f.dailyCalls.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	daily := make([]*DailyWeatherResponse, len(coordinates))
+	for i := range coordinates {
+		daily[i] = &DailyWeatherResponse{Timezone: "Asia/Tokyo", UTCOffsetSeconds: 32400}
+	}
+	return daily, nil
+}
+
+func (f *fakeOpenMeteoAPI) GetHistoricalWeather(_ context.Context, coordinates []Coordinates, _ HistoricalOptions) ([]*HistoricalWeatherResponse, error) {
+	f.historicalCalls.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	historical := make([]*HistoricalWeatherResponse, len(coordinates))
+	for i := range coordinates {
+		historical[i] = &HistoricalWeatherResponse{Timezone: "Asia/Tokyo", UTCOffsetSeconds: 32400}
+	}
+	return historical, nil
+}
+
+type fakeCacheStore struct {
+	mu    sync.Mutex
+	items map[string]CacheItem
+}
+
+func (f *fakeCacheStore) Get(_ context.Context, key string) (*CacheItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[key]
+	if !ok {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+func (f *fakeCacheStore) Set(_ context.Context, key string, item CacheItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.items[key] = item
+	return nil
+}
+
+func setupCacheTests(t *testing.T, store CacheStore) (OpenMeteoRepository, *fakeOpenMeteoAPI, *time.Time) {
+	api := &fakeOpenMeteoAPI{}
+	repo, err := NewCachedOpenMeteoRepository(api, CacheOptions{Size: 10, Store: store})
+	assert.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	repo.(*cachedOpenMeteoRepo).now = func() time.Time { return now }
+
+	return repo, api, &now
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetElevations(t *testing.T) {
+	t.Run("success with multiple locations", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/elevation", r.URL.Path)
+			assert.Equal(t, "37.451300,40.203900", r.URL.Query().Get("latitude"))
+			assert.Equal(t, "141.033400,140.024500", r.URL.Query().Get("longitude"))
+			fmt.Fprint(w, `{"elevation":[34.0,12.0]}`)
+		})
+
+		elevations, err := repo.GetElevations(context.Background(), []Coordinates{
+			{Latitude: 37.4513, Longitude: 141.0334},
+			{Latitude: 40.2039, Longitude: 140.0245},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{34, 12}, elevations)
+	})
+
+	t.Run("fail due to missing locations", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"elevation":[34.0]}`)
+		})
+
+		_, err := repo.GetElevations(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}})
+		var requestErr *RequestError
+		assert.ErrorAs(t, err, &requestErr)
+	})
+
+	t.Run("fail due to non-OK response", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		_, err := repo.GetElevation(context.Background(), 1, 2)
+		var requestErr *RequestError
+		assert.ErrorAs(t, err, &requestErr)
+	})
+}
+
+func TestGetWeatherForecasts(t *testing.T) {
+	opts := ForecastOptions{ForecastDays: 3, PastDays: 1}
+
+	t.Run("success with a single location", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/forecast", r.URL.Path)
+			assert.Equal(t, "3", r.URL.Query().Get("forecast_days"))
+			assert.Equal(t, "1", r.URL.Query().Get("past_days"))
+			assert.Equal(t, "auto", r.URL.Query().Get("timezone"))
+			assert.False(t, r.URL.Query().Has("models"))
+			fmt.Fprint(w, `{"timezone":"Asia/Tokyo","utc_offset_seconds":32400,"hourly":{"time":["2024-01-01T00:00"],"precipitation":[0.5]}}`)
+		})
+
+		forecast, err := repo.GetWeatherForecast(context.Background(), 37.4513, 141.0334, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Tokyo", forecast.Timezone)
+		assert.Equal(t, 0.5, *forecast.Hourly.Precipitation[0])
+	})
+
+	t.Run("success with multiple locations", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "37.451300,40.203900", r.URL.Query().Get("latitude"))
+			fmt.Fprint(w, `[{"timezone":"Asia/Tokyo","hourly":{"time":["2024-01-01T00:00"]}},{"timezone":"Asia/Seoul","hourly":{"time":["2024-01-01T00:00"]}}]`)
+		})
+
+		forecasts, err := repo.GetWeatherForecasts(context.Background(), []Coordinates{
+			{Latitude: 37.4513, Longitude: 141.0334},
+			{Latitude: 40.2039, Longitude: 140.0245},
+		}, opts)
+		assert.NoError(t, err)
+		assert.Len(t, forecasts, 2)
+		assert.Equal(t, "Asia/Seoul", forecasts[1].Timezone)
+	})
+
+	t.Run("success with a weather model", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "icon_seamless", r.URL.Query().Get("models"))
+			fmt.Fprint(w, `{"timezone":"Europe/Berlin","hourly":{"time":["2024-01-01T00:00"]}}`)
+		})
+
+		_, err := repo.GetWeatherForecast(context.Background(), 52.52, 13.41, ForecastOptions{ForecastDays: 1, Model: "icon_seamless"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("success with optional variables", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			assert.Equal(t, "temperature_2m,precipitation,wind_speed_10m,wind_direction_10m,wind_speed_120m,global_tilted_irradiance", query.Get("hourly"))
+			assert.Equal(t, "35", query.Get("tilt"))
+			assert.Equal(t, "-45", query.Get("azimuth"))
+			fmt.Fprint(w, `{"timezone":"Europe/Berlin","hourly":{"time":["2024-01-01T00:00","2024-01-01T01:00"],"wind_speed_120m":[21.5,null],"global_tilted_irradiance":[0,0]}}`)
+		})
+
+		opts := ForecastOptions{ForecastDays: 1, Variables: NewVariables(GlobalTiltedIrradiance, WindSpeed120m), Tilt: 35, Azimuth: -45}
+		forecast, err := repo.GetWeatherForecast(context.Background(), 52.52, 13.41, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 21.5, *forecast.Hourly.Values(WindSpeed120m)[0])
+		assert.Nil(t, forecast.Hourly.Values(WindSpeed120m)[1])
+		assert.Nil(t, forecast.Hourly.Values(SnowDepth))
+	})
+
+	t.Run("success with units", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			assert.Empty(t, query.Get("temperature_unit"))
+			assert.Equal(t, "kn", query.Get("wind_speed_unit"))
+			assert.Equal(t, "inch", query.Get("precipitation_unit"))
+			fmt.Fprint(w, `{"timezone":"Europe/Berlin","hourly":{"time":["2024-01-01T00:00"],"wind_speed_10m":[12.5]}}`)
+		})
+
+		opts := ForecastOptions{ForecastDays: 1, Units: Units{Temperature: Celsius, WindSpeed: Knots, Precipitation: Inches}}
+		forecast, err := repo.GetWeatherForecast(context.Background(), 52.52, 13.41, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 12.5, *forecast.Hourly.WindSpeed10m[0])
+	})
+
+	t.Run("fail due to invalid response", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"timezone":"Asia/Tokyo"}`)
+		})
+
+		_, err := repo.GetWeatherForecasts(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}, opts)
+		var requestErr *RequestError
+		assert.ErrorAs(t, err, &requestErr)
+	})
+}
+
+func TestGetDailyWeather(t *testing.T) {
+	t.Run("success with a single location", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/forecast", r.URL.Path)
+			assert.Equal(t, dailyVariables, r.URL.Query().Get("daily"))
+			assert.Equal(t, "3", r.URL.Query().Get("forecast_days"))
+			assert.Equal(t, "auto", r.URL.Query().Get("timezone"))
+			fmt.Fprint(w, `{"timezone":"Asia/Tokyo","daily":{"time":["2024-01-01"],"temperature_2m_max":[9.5],"wind_gusts_10m_max":[null],"sunrise":["2024-01-01T06:51"]}}`)
+		})
+
+		daily, err := repo.GetDailyWeather(context.Background(), []Coordinates{{Latitude: 37.4513, Longitude: 141.0334}}, 3)
+		assert.NoError(t, err)
+		assert.Len(t, daily, 1)
+		assert.Equal(t, "Asia/Tokyo", daily[0].Location().String())
+		assert.Equal(t, 9.5, *daily[0].Daily.Temperature2mMax[0])
+		assert.Nil(t, daily[0].Daily.WindGusts10mMax[0])
+		assert.Equal(t, []string{"2024-01-01T06:51"}, daily[0].Daily.Sunrise)
+		assert.False(t, daily[0].FetchedAt.IsZero())
+	})
+
+	t.Run("fail due to missing locations", func(t *testing.T) {
+		repo := setupFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"timezone":"Asia/Tokyo","daily":{"time":["2024-01-01"]}}]`)
+		})
+
+		_, err := repo.GetDailyWeather(context.Background(), []Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}, 7)
+		assert.Error(t, err)
+	})
+}
+
+func TestOpenMeteoConfig(t *testing.T) {
+	t.Run("send the api key to every endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "secret", r.URL.Query().Get("apikey"))
+			if r.URL.Path == "/v1/elevation" {
+				w.Write([]byte(`{"elevation":[34]}`))
+				return
+			}
+			w.Write([]byte(`{"timezone":"Asia/Tokyo"}`))
+		}))
+		t.Cleanup(server.Close)
+		repo := NewOpenMeteoRepository(OpenMeteoConfig{APIKey: "secret", BaseURL: server.URL + "/", HTTPClient: server.Client()})
+
+		_, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.NoError(t, err)
+		_, err = repo.GetWeatherForecast(context.Background(), 1, 2, ForecastOptions{ForecastDays: 1})
+		assert.NoError(t, err)
+	})
+
+	t.Run("use the commercial api with an api key", func(t *testing.T) {
+		repo := NewOpenMeteoRepository(OpenMeteoConfig{APIKey: "secret"}).(*openMeteoRepo)
+		assert.Equal(t, openMeteoCustomerBaseURL, repo.baseURL)
+		assert.Equal(t, openMeteoCustomerArchiveBaseURL, repo.archiveBaseURL)
+		assert.Equal(t, DefaultOpenMeteoConfig.Timeout, repo.client.Timeout)
+	})
+
+	t.Run("use the free api without an api key", func(t *testing.T) {
+		repo := NewOpenMeteoRepository(OpenMeteoConfig{}).(*openMeteoRepo)
+		assert.Equal(t, openMeteoBaseURL, repo.baseURL)
+		assert.Equal(t, openMeteoArchiveBaseURL, repo.archiveBaseURL)
+		assert.Equal(t, DefaultOpenMeteoConfig.MaxRetries, repo.maxRetries)
+	})
+
+	t.Run("use the base url for the archive of a self-hosted api", func(t *testing.T) {
+		repo := NewOpenMeteoRepository(OpenMeteoConfig{BaseURL: "http://localhost:8080/"}).(*openMeteoRepo)
+		assert.Equal(t, "http://localhost:8080", repo.archiveBaseURL)
+	})
+}
+
+func TestRetries(t *testing.T) {
+	t.Run("retry 429 and 5xx with exponential backoff", func(t *testing.T) {
+		statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+		repo, delays := setupRetryServer(t, func(w http.ResponseWriter, attempt int) {
+			w.WriteHeader(statuses[attempt])
+			w.Write([]byte(`{"elevation":[34]}`))
+		})
+
+		elevation, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 34.0, elevation)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *delays)
+	})
+
+	t.Run("honor Retry-After", func(t *testing.T) {
+		repo, delays := setupRetryServer(t, func(w http.ResponseWriter, attempt int) {
+			if attempt == 0 {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"elevation":[34]}`))
+		})
+
+		_, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []time.Duration{2 * time.Second}, *delays)
+	})
+
+	t.Run("fail if Retry-After is longer than the maximum delay", func(t *testing.T) {
+		repo, delays := setupRetryServer(t, func(w http.ResponseWriter, attempt int) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		_, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.ErrorContains(t, err, "429")
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("give up after the maximum retries", func(t *testing.T) {
+		repo, delays := setupRetryServer(t, func(w http.ResponseWriter, attempt int) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		_, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.ErrorContains(t, err, "500")
+		assert.Len(t, *delays, 3)
+		var requestErr *RequestError
+		assert.False(t, errors.As(err, &requestErr), "Expected a failure of the API not to be a request error")
+	})
+
+	t.Run("do not retry client errors", func(t *testing.T) {
+		repo, delays := setupRetryServer(t, func(w http.ResponseWriter, attempt int) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		_, err := repo.GetElevation(context.Background(), 1, 2)
+		assert.ErrorContains(t, err, "400")
+		assert.Empty(t, *delays)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "not set", value: "", expected: 0},
+		{name: "seconds", value: "3", expected: 3 * time.Second},
+		{name: "http date", value: "Mon, 01 Jan 2024 10:00:30 GMT", expected: 30 * time.Second},
+		{name: "date in the past", value: "Mon, 01 Jan 2024 09:00:00 GMT", expected: 0},
+		{name: "invalid", value: "soon", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, parseRetryAfter(tc.value, now), "Failed test: "+tc.name)
+	}
+}
+
+func setupFakeServer(t *testing.T, handler http.HandlerFunc) OpenMeteoRepository {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewOpenMeteoRepository(OpenMeteoConfig{BaseURL: server.URL, HTTPClient: server.Client(), MaxRetries: -1})
+}
+
+// setupRetryServer creates a client with 3 retries of a fake server, the delays between the retries are recorded.
+func setupRetryServer(t *testing.T, handler func(w http.ResponseWriter, attempt int)) (OpenMeteoRepository, *[]time.Duration) {
+	attempt := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, attempt)
+		attempt++
+	}))
+	t.Cleanup(server.Close)
+
+	repo := NewOpenMeteoRepository(OpenMeteoConfig{
+		BaseURL:       server.URL,
+		HTTPClient:    server.Client(),
+		MaxRetries:    3,
+		RetryDelay:    100 * time.Millisecond,
+		MaxRetryDelay: 10 * time.Second,
+	}).(*openMeteoRepo)
+
+	var delays []time.Duration
+	repo.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	return repo, &delays
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+)
+
+// openMeteoTimeLayout is the layout of the local times returned by Open-Meteo, e.g. "2024-01-01T08:17".
+const openMeteoTimeLayout = "2006-01-02T15:04"
+
+// Times parses the local hourly times in the timezone of the location.
+func (r *WeatherForecastResponse) Times() ([]time.Time, error) {
+	return parseHourlyTimes(r.Hourly.Time, r.Location())
+}
+
+// Dates parses the local calendar days, they are returned at midnight UTC.
+func (r *DailyWeatherResponse) Dates() ([]time.Time, error) {
+	dates := make([]time.Time, 0, len(r.Daily.Time))
+	for _, s := range r.Daily.Time {
+		date, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse date %q: %w", s, err)
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// Sunrise returns the sunrise of the i-th day, nil if Open-Meteo has no valid time for it,
+// e.g. during the polar night.
+func (r *DailyWeatherResponse) Sunrise(i int) *time.Time {
+	return localTimeAt(r.Daily.Sunrise, i, r.Location())
+}
+
+// Sunset returns the sunset of the i-th day, nil if Open-Meteo has no valid time for it.
+func (r *DailyWeatherResponse) Sunset(i int) *time.Time {
+	return localTimeAt(r.Daily.Sunset, i, r.Location())
+}
+
+// parseHourlyTimes parses consecutive local hours. The local times are ambiguous when the clocks are
+// set back and the hour is repeated, each time is resolved to the first instant after the previous hour
+// with the same local time.
+func parseHourlyTimes(times []string, loc *time.Location) ([]time.Time, error) {
+	parsed := make([]time.Time, 0, len(times))
+	for i, s := range times {
+		t, err := time.ParseInLocation(openMeteoTimeLayout, s, loc)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse time %q: %w", s, err)
+		}
+
+		for _, candidate := range []time.Time{t.Add(-time.Hour), t, t.Add(time.Hour)} {
+			if candidate.In(loc).Format(openMeteoTimeLayout) != s {
+				continue
+			}
+			if i == 0 || candidate.After(parsed[i-1]) {
+				t = candidate
+				break
+			}
+		}
+		parsed = append(parsed, t)
+	}
+	return parsed, nil
+}
+
+// localTimeAt parses the local time at index i, nil if there is no valid time.
+func localTimeAt(times []string, i int, loc *time.Location) *time.Time {
+	if i >= len(times) {
+		return nil
+	}
+
+	t, err := time.ParseInLocation(openMeteoTimeLayout, times[i], loc)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimes(t *testing.T) {
+	t.Run("parse the local times of the location", func(t *testing.T) {
+		response := &WeatherForecastResponse{Timezone: "Asia/Tokyo", Hourly: Hourly{Time: []string{"2024-01-01T00:00", "2024-01-01T01:00"}}}
+
+		times, err := response.Times()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 12, 31, 15, 0, 0, 0, time.UTC), times[0].UTC())
+		assert.Equal(t, time.Date(2023, 12, 31, 16, 0, 0, 0, time.UTC), times[1].UTC())
+	})
+
+	t.Run("resolve the repeated hour when the clocks are set back", func(t *testing.T) {
+		response := &WeatherForecastResponse{Timezone: "Europe/Berlin", Hourly: Hourly{
+			Time: []string{"2024-10-27T02:00", "2024-10-27T02:00", "2024-10-27T03:00"},
+		}}
+
+		times, err := response.Times()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC), times[0].UTC())
+		assert.Equal(t, time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC), times[1].UTC())
+		assert.Equal(t, time.Date(2024, 10, 27, 2, 0, 0, 0, time.UTC), times[2].UTC())
+	})
+
+	t.Run("skip the missing hour when the clocks are set forward", func(t *testing.T) {
+		response := &WeatherForecastResponse{Timezone: "Europe/Berlin", Hourly: Hourly{
+			Time: []string{"2024-03-31T01:00", "2024-03-31T03:00"},
+		}}
+
+		times, err := response.Times()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Hour, times[1].Sub(times[0]))
+	})
+
+	t.Run("fail due to an invalid time", func(t *testing.T) {
+		response := &WeatherForecastResponse{Hourly: Hourly{Time: []string{"today"}}}
+
+		_, err := response.Times()
+		assert.Error(t, err)
+	})
+}
+
+func TestDailyTimes(t *testing.T) {
+	response := &DailyWeatherResponse{Timezone: "Asia/Tokyo", Daily: Daily{
+		Time:    []string{"2024-01-01", "2024-01-02"},
+		Sunrise: []string{"2024-01-01T06:51", ""},
+	}}
+
+	dates, err := response.Dates()
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, dates)
+	assert.Equal(t, time.Date(2023, 12, 31, 21, 51, 0, 0, time.UTC), response.Sunrise(0).UTC())
+	assert.Nil(t, response.Sunrise(1))
+	assert.Nil(t, response.Sunset(0))
+
+	response.Daily.Time = []string{"2024-01-01T00:00"}
+	_, err = response.Dates()
+	assert.Error(t, err)
+}
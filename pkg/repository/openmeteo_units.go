@@ -0,0 +1,105 @@
+package repository
+
+import "fmt"
+
+// TemperatureUnit is the unit of temperatures, the values are the names of the Open-Meteo temperature_unit parameter.
+type TemperatureUnit string
+
+const (
+	Celsius    TemperatureUnit = "celsius"
+	Fahrenheit TemperatureUnit = "fahrenheit"
+)
+
+// WindSpeedUnit is the unit of wind speeds, the values are the names of the Open-Meteo wind_speed_unit parameter.
+type WindSpeedUnit string
+
+const (
+	KilometersPerHour WindSpeedUnit = "kmh"
+	MetersPerSecond   WindSpeedUnit = "ms"
+	Knots             WindSpeedUnit = "kn"
+	MilesPerHour      WindSpeedUnit = "mph"
+)
+
+// PrecipitationUnit is the unit of precipitation, the values are the names of the Open-Meteo precipitation_unit parameter.
+type PrecipitationUnit string
+
+const (
+	Millimeters PrecipitationUnit = "mm"
+	Inches      PrecipitationUnit = "inch"
+)
+
+// Units are the units of the weather values. Empty units are the metric defaults of Open-Meteo,
+// celsius, km/h and millimeters.
+type Units struct {
+	Temperature   TemperatureUnit
+	WindSpeed     WindSpeedUnit
+	Precipitation PrecipitationUnit
+}
+
+// Validate checks that the units are supported by Open-Meteo.
+func (u Units) Validate() error {
+	switch u.Temperature {
+	case "", Celsius, Fahrenheit:
+	default:
+		return fmt.Errorf("unknown temperature unit %q", u.Temperature)
+	}
+	switch u.WindSpeed {
+	case "", KilometersPerHour, MetersPerSecond, Knots, MilesPerHour:
+	default:
+		return fmt.Errorf("unknown wind speed unit %q", u.WindSpeed)
+	}
+	switch u.Precipitation {
+	case "", Millimeters, Inches:
+	default:
+		return fmt.Errorf("unknown precipitation unit %q", u.Precipitation)
+	}
+	return nil
+}
+
+// WithDefaults returns the units with the metric defaults for the units that are not set.
+func (u Units) WithDefaults() Units {
+	if u.Temperature == "" {
+		u.Temperature = Celsius
+	}
+	if u.WindSpeed == "" {
+		u.WindSpeed = KilometersPerHour
+	}
+	if u.Precipitation == "" {
+		u.Precipitation = Millimeters
+	}
+	return u
+}
+
+// IsDefault reports whether the units are the metric defaults of Open-Meteo.
+func (u Units) IsDefault() bool {
+	return u.WithDefaults() == Units{}.WithDefaults()
+}
+
+// FromCelsius converts a temperature from celsius.
+func (t TemperatureUnit) FromCelsius(celsius float64) float64 {
+	if t == Fahrenheit {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+// FromKmh converts a wind speed from km/h.
+func (w WindSpeedUnit) FromKmh(kmh float64) float64 {
+	switch w {
+	case MetersPerSecond:
+		return kmh / 3.6
+	case Knots:
+		return kmh / 1.852
+	case MilesPerHour:
+		return kmh / 1.609344
+	}
+	return kmh
+}
+
+// FromMillimeters converts precipitation from millimeters.
+func (p PrecipitationUnit) FromMillimeters(mm float64) float64 {
+	if p == Inches {
+		return mm / 25.4
+	}
+	return mm
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnits(t *testing.T) {
+	t.Run("validate the units", func(t *testing.T) {
+		assert.NoError(t, Units{}.Validate())
+		assert.NoError(t, Units{Temperature: Fahrenheit, WindSpeed: Knots, Precipitation: Inches}.Validate())
+		assert.Error(t, Units{Temperature: "kelvin"}.Validate())
+		assert.Error(t, Units{WindSpeed: "beaufort"}.Validate())
+		assert.Error(t, Units{Precipitation: "cm"}.Validate())
+	})
+
+	t.Run("explicit metric units are the defaults", func(t *testing.T) {
+		assert.True(t, Units{}.IsDefault())
+		assert.True(t, Units{Temperature: Celsius, WindSpeed: KilometersPerHour, Precipitation: Millimeters}.IsDefault())
+		assert.False(t, Units{WindSpeed: MetersPerSecond}.IsDefault())
+	})
+
+	t.Run("convert metric values", func(t *testing.T) {
+		assert.Equal(t, 20.0, TemperatureUnit("").FromCelsius(20))
+		assert.Equal(t, 68.0, Fahrenheit.FromCelsius(20))
+		assert.Equal(t, 36.0, KilometersPerHour.FromKmh(36))
+		assert.Equal(t, 10.0, MetersPerSecond.FromKmh(36))
+		assert.InDelta(t, 10.0, Knots.FromKmh(18.52), 1e-9)
+		assert.InDelta(t, 10.0, MilesPerHour.FromKmh(16.09344), 1e-9)
+		assert.Equal(t, 1.0, Inches.FromMillimeters(25.4))
+		assert.Equal(t, 2.5, Millimeters.FromMillimeters(2.5))
+	})
+}
@@ -0,0 +1,145 @@
+package repository
+
+import "strings"
+
+// Variable is an optional hourly variable of the weather forecast. The temperature, precipitation and
+// wind at 10 m are always requested, the optional variables only if they are needed.
+type Variable uint
+
+// The optional hourly variables, see https://open-meteo.com/en/docs for their units.
+const (
+	WindSpeed80m Variable = iota
+	WindSpeed120m
+	WindSpeed180m
+	WindDirection80m
+	WindDirection120m
+	WindDirection180m
+	WindGusts10m
+	CloudCoverLow
+	CloudCoverMid
+	CloudCoverHigh
+	ShortwaveRadiation
+	DirectRadiation
+	DiffuseRadiation
+	GlobalTiltedIrradiance
+	SurfacePressure
+	RelativeHumidity2m
+	Snowfall
+	SnowDepth
+	numVariables
+)
+
+// variableNames are the names of the variables in the hourly parameter of the Open-Meteo API.
+var variableNames = [numVariables]string{
+	WindSpeed80m:           "wind_speed_80m",
+	WindSpeed120m:          "wind_speed_120m",
+	WindSpeed180m:          "wind_speed_180m",
+	WindDirection80m:       "wind_direction_80m",
+	WindDirection120m:      "wind_direction_120m",
+	WindDirection180m:      "wind_direction_180m",
+	WindGusts10m:           "wind_gusts_10m",
+	CloudCoverLow:          "cloud_cover_low",
+	CloudCoverMid:          "cloud_cover_mid",
+	CloudCoverHigh:         "cloud_cover_high",
+	ShortwaveRadiation:     "shortwave_radiation",
+	DirectRadiation:        "direct_radiation",
+	DiffuseRadiation:       "diffuse_radiation",
+	GlobalTiltedIrradiance: "global_tilted_irradiance",
+	SurfacePressure:        "surface_pressure",
+	RelativeHumidity2m:     "relative_humidity_2m",
+	Snowfall:               "snowfall",
+	SnowDepth:              "snow_depth",
+}
+
+// String returns the Open-Meteo name of the variable, e.g. "wind_speed_80m".
+func (v Variable) String() string {
+	return variableNames[v]
+}
+
+// IsDirection reports whether the variable is a direction in degrees, it has to be averaged on the circle.
+func (v Variable) IsDirection() bool {
+	return v == WindDirection80m || v == WindDirection120m || v == WindDirection180m
+}
+
+// Variables is a set of optional hourly variables, the zero value is the empty set.
+type Variables uint32
+
+// AllVariables is the set of all optional variables.
+const AllVariables = Variables(1<<numVariables - 1)
+
+// NewVariables returns the set of the given variables.
+func NewVariables(variables ...Variable) Variables {
+	var set Variables
+	for _, v := range variables {
+		set |= 1 << v
+	}
+	return set
+}
+
+// Has reports whether the variable is in the set.
+func (s Variables) Has(v Variable) bool {
+	return s&(1<<v) != 0
+}
+
+// List returns the variables of the set in the order of their constants.
+func (s Variables) List() []Variable {
+	var variables []Variable
+	for v := Variable(0); v < numVariables; v++ {
+		if s.Has(v) {
+			variables = append(variables, v)
+		}
+	}
+	return variables
+}
+
+// String returns the comma separated Open-Meteo names of the variables.
+func (s Variables) String() string {
+	names := make([]string, 0, numVariables)
+	for _, v := range s.List() {
+		names = append(names, v.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Values returns the hourly values of the variable, nil if they were not requested.
+func (h Hourly) Values(v Variable) []*float64 {
+	switch v {
+	case WindSpeed80m:
+		return h.WindSpeed80m
+	case WindSpeed120m:
+		return h.WindSpeed120m
+	case WindSpeed180m:
+		return h.WindSpeed180m
+	case WindDirection80m:
+		return h.WindDirection80m
+	case WindDirection120m:
+		return h.WindDirection120m
+	case WindDirection180m:
+		return h.WindDirection180m
+	case WindGusts10m:
+		return h.WindGusts10m
+	case CloudCoverLow:
+		return h.CloudCoverLow
+	case CloudCoverMid:
+		return h.CloudCoverMid
+	case CloudCoverHigh:
+		return h.CloudCoverHigh
+	case ShortwaveRadiation:
+		return h.ShortwaveRadiation
+	case DirectRadiation:
+		return h.DirectRadiation
+	case DiffuseRadiation:
+		return h.DiffuseRadiation
+	case GlobalTiltedIrradiance:
+		return h.GlobalTiltedIrradiance
+	case SurfacePressure:
+		return h.SurfacePressure
+	case RelativeHumidity2m:
+		return h.RelativeHumidity2m
+	case Snowfall:
+		return h.Snowfall
+	case SnowDepth:
+		return h.SnowDepth
+	}
+	return nil
+}
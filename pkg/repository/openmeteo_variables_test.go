@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariables(t *testing.T) {
+	variables := NewVariables(SnowDepth, WindSpeed80m, WindDirection80m)
+
+	assert.True(t, variables.Has(WindDirection80m))
+	assert.False(t, variables.Has(WindSpeed120m))
+	assert.Equal(t, []Variable{WindSpeed80m, WindDirection80m, SnowDepth}, variables.List())
+	assert.Equal(t, "wind_speed_80m,wind_direction_80m,snow_depth", variables.String())
+	assert.Empty(t, Variables(0).String())
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// The errors returned by the service, callers can check for them with errors.Is.
+var (
+	// ErrNotFound is returned when the requested power plant does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrValidation is returned when the input of an operation is invalid.
+	ErrValidation = errors.New("validation failed")
+	// ErrUpstreamUnavailable is returned when an external api can't provide the requested data.
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	// ErrConflict is returned when the operation conflicts with the current state of the power plant.
+	ErrConflict = errors.New("conflict")
+)
+
+// ErrDateOutOfRange is returned when the requested date is not covered by the forecast.
+var ErrDateOutOfRange = fmt.Errorf("%w: date is outside of the forecast range", ErrValidation)
+
+// powerPlantError translates a repository error for the power plant with the given ID into a service error.
+func powerPlantError(id string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return fmt.Errorf("%w: power plant %s", ErrNotFound, id)
+	case errors.Is(err, repository.ErrConflict):
+		return fmt.Errorf("%w: power plant %s is already in the requested state", ErrConflict, id)
+	default:
+		return err
+	}
+}
+
+// upstreamError marks an error of an external api as ErrUpstreamUnavailable.
+func upstreamError(msg string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrUpstreamUnavailable, msg, err)
+}
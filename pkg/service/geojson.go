@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/geojson"
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// plantProperties are the properties of a power plant feature, the location of the power plant is the
+// Point geometry of the feature.
+type plantProperties struct {
+	Name              string             `json:"name"                      validate:"required,min=2,max=100"`
+	WeatherProvider   *string            `json:"weatherProvider,omitempty" validate:"omitempty,max=100"`
+	PlantType         *model.PlantType   `json:"plantType,omitempty"`
+	CapacityMw        *float64           `json:"capacityMw,omitempty"`
+	CommissioningDate *model.Date        `json:"commissioningDate,omitempty"`
+	Status            *model.PlantStatus `json:"status,omitempty"`
+	Operator          *string            `json:"operator,omitempty"        validate:"omitempty,max=255"`
+
+	// The stored fields are exported, the import ignores them
+	Elevation *float64   `json:"elevation,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
+	// present are the names of the imported properties, also of the properties that are null
+	present map[string]json.RawMessage
+}
+
+// ExportPowerPlants retrieves all power plants matching the filter as Point features ordered by ID,
+// the ID of a feature is the ID of the power plant.
+func (s *powerPlantService) ExportPowerPlants(ctx context.Context, filter repository.PowerPlantFilter) (*geojson.FeatureCollection, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	slog.Debug("Exporting power plants", "filter", filter)
+	features := []geojson.Feature{}
+	page := repository.Page{Limit: repository.MaxPageLimit}
+	for {
+		result, err := s.dbRepo.ListPage(ctx, filter, repository.PowerPlantSort{}, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, plant := range result.PowerPlants {
+			feature, err := plantFeature(plant)
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, feature)
+		}
+
+		if !result.HasMore || len(result.Cursors) == 0 {
+			break
+		}
+		page.Cursor = &result.Cursors[len(result.Cursors)-1]
+	}
+
+	collection := geojson.NewFeatureCollection(features)
+	return &collection, nil
+}
+
+// ImportPowerPlants creates and updates power plants from the features of the collection. A feature with an ID
+// updates the power plant with the ID, a feature without an ID creates a new power plant. Only the IDs of exported
+// power plants are accepted, the IDs of other sources, e.g. "way/123" of OpenStreetMap, have to be removed to create
+// the power plants. Features that are invalid or refer to an unknown power plant are reported in the result and
+// skipped. The import stops at the first other error, the result of the features imported before is returned
+// with the error.
+func (s *powerPlantService) ImportPowerPlants(ctx context.Context, collection geojson.FeatureCollection) (*model.ImportResult, error) {
+	if err := collection.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	slog.Debug("Importing power plants", "features", len(collection.Features))
+	result := &model.ImportResult{Errors: []*model.FeatureError{}}
+	for i, feature := range collection.Features {
+		created, err := s.importFeature(ctx, feature)
+		switch {
+		case err == nil && created:
+			result.Created++
+		case err == nil:
+			result.Updated++
+		case errors.Is(err, ErrValidation) || errors.Is(err, ErrNotFound):
+			featureError := &model.FeatureError{Index: i, Message: err.Error()}
+			if id := feature.FeatureID(); id != "" {
+				featureError.ID = &id
+			}
+			result.Errors = append(result.Errors, featureError)
+		default:
+			slog.Warn("Import of power plants stopped", "index", i, "created", result.Created, "updated", result.Updated, "error", err)
+			return result, fmt.Errorf("could not import feature %d, the features before it were imported: %w", i, err)
+		}
+	}
+
+	slog.Info("Imported power plants", "created", result.Created, "updated", result.Updated, "errors", len(result.Errors))
+	return result, nil
+}
+
+// importFeature creates or updates the power plant of the feature and reports whether it was created.
+func (s *powerPlantService) importFeature(ctx context.Context, feature geojson.Feature) (bool, error) {
+	position, props, err := decodeFeature(feature)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	id := feature.FeatureID()
+	if id == "" {
+		_, err := s.CreatePowerPlant(ctx, props.plant(position))
+		return true, err
+	}
+	if !isPowerPlantID(id) {
+		return false, fmt.Errorf("%w: id %q is not the ID of a power plant, remove it to create a new power plant", ErrValidation, id)
+	}
+	_, err = s.UpdatePowerPlant(ctx, props.patch(id, position))
+	if errors.Is(err, ErrNotFound) {
+		return false, fmt.Errorf("%w, only the IDs of exported power plants can be imported", err)
+	}
+	return false, err
+}
+
+// isPowerPlantID reports whether the ID of a feature can be the ID of a power plant, a positive serial number.
+func isPowerPlantID(id string) bool {
+	n, err := strconv.ParseInt(id, 10, 32)
+	return err == nil && n > 0
+}
+
+// plantFeature translates a power plant into a Point feature.
+func plantFeature(plant model.PowerPlant) (geojson.Feature, error) {
+	status := plant.Status
+	createdAt := plant.CreatedAt.UTC()
+	props := plantProperties{
+		Name:              plant.Name,
+		WeatherProvider:   plant.WeatherProvider,
+		PlantType:         plant.PlantType,
+		CapacityMw:        plant.CapacityMw,
+		CommissioningDate: plant.CommissioningDate,
+		Status:            &status,
+		Operator:          plant.Operator,
+		Elevation:         plant.Elevation,
+		CreatedAt:         &createdAt,
+		DeletedAt:         utcOrNil(plant.DeletedAt),
+	}
+	properties, err := json.Marshal(props)
+	if err != nil {
+		return geojson.Feature{}, fmt.Errorf("error encoding power plant %s: %w", plant.ID, err)
+	}
+
+	geometry := geojson.NewPoint(geojson.Position{plant.Longitude, plant.Latitude})
+	return geojson.Feature{Type: geojson.TypeFeature, ID: plant.ID, Geometry: &geometry, Properties: properties}, nil
+}
+
+// decodeFeature decodes and validates the position and the properties of a power plant feature.
+func decodeFeature(feature geojson.Feature) (geojson.Position, plantProperties, error) {
+	var props plantProperties
+	if feature.Geometry == nil {
+		return geojson.Position{}, props, fmt.Errorf("feature has no geometry")
+	}
+	position, err := feature.Geometry.Point()
+	if err != nil {
+		return geojson.Position{}, props, err
+	}
+
+	if len(feature.Properties) == 0 || string(feature.Properties) == "null" {
+		return geojson.Position{}, props, fmt.Errorf("feature has no properties")
+	}
+	if err := json.Unmarshal(feature.Properties, &props); err != nil {
+		return geojson.Position{}, props, fmt.Errorf("invalid properties: %w", err)
+	}
+	if err := json.Unmarshal(feature.Properties, &props.present); err != nil {
+		return geojson.Position{}, props, fmt.Errorf("invalid properties: %w", err)
+	}
+	if err := validator.New().Struct(props); err != nil {
+		return geojson.Position{}, props, err
+	}
+	if props.PlantType != nil && !props.PlantType.IsValid() {
+		return geojson.Position{}, props, fmt.Errorf("unknown plant type %q", *props.PlantType)
+	}
+	if props.Status != nil && !props.Status.IsValid() {
+		return geojson.Position{}, props, fmt.Errorf("unknown status %q", *props.Status)
+	}
+	return position, props, nil
+}
+
+// plant returns a new power plant at the position, the status defaults to operating.
+func (p plantProperties) plant(position geojson.Position) *model.PowerPlant {
+	status := model.PlantStatusOperating
+	if p.Status != nil {
+		status = *p.Status
+	}
+	return &model.PowerPlant{
+		Name:              p.Name,
+		Latitude:          position.Latitude(),
+		Longitude:         position.Longitude(),
+		WeatherProvider:   p.WeatherProvider,
+		PlantType:         p.PlantType,
+		CapacityMw:        p.CapacityMw,
+		CommissioningDate: p.CommissioningDate,
+		Status:            status,
+		Operator:          p.Operator,
+	}
+}
+
+// patch returns the update of the power plant with the ID, properties that are not set are left unchanged.
+// The plant type, capacity and commissioning date are removed if they are null.
+func (p plantProperties) patch(id string, position geojson.Position) *model.PowerPlantPatch {
+	latitude, longitude := position.Latitude(), position.Longitude()
+	return &model.PowerPlantPatch{
+		ID:                id,
+		Name:              &p.Name,
+		Latitude:          &latitude,
+		Longitude:         &longitude,
+		WeatherProvider:   p.WeatherProvider,
+		PlantType:         clearable(p.PlantType, p.has("plantType")),
+		CapacityMw:        clearable(p.CapacityMw, p.has("capacityMw")),
+		CommissioningDate: clearable(p.CommissioningDate, p.has("commissioningDate")),
+		Status:            p.Status,
+		Operator:          p.Operator,
+	}
+}
+
+// has reports whether the property is in the imported properties, also if it is null.
+func (p plantProperties) has(name string) bool {
+	_, ok := p.present[name]
+	return ok
+}
+
+// clearable returns the update of a property that is removed with null, it is omitted if the property is not set.
+func clearable[T any](value *T, present bool) graphql.Omittable[*T] {
+	if !present {
+		return graphql.Omittable[*T]{}
+	}
+	return graphql.OmittableOf(value)
+}
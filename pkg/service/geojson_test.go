@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/geojson"
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestExportPowerPlants(t *testing.T) {
+	t.Run("export all pages as point features", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		wind := model.PlantTypeWind
+		commissioned := model.NewDate(time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC))
+		first := repository.Page{Limit: repository.MaxPageLimit}
+		mockDB.On("ListPage", mock.Anything, repository.PowerPlantFilter{}, repository.PowerPlantSort{}, first).Return(&repository.PowerPlantPage{
+			PowerPlants: []model.PowerPlant{{
+				ID: "2", Name: "Noshiro Wind Farm", Latitude: 40.2039, Longitude: 140.0245, Elevation: floatPointer(12),
+				PlantType: &wind, CapacityMw: floatPointer(88), CommissioningDate: &commissioned, Status: model.PlantStatusOperating,
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			}},
+			Cursors: []repository.Cursor{{Field: repository.SortByID, ID: "2"}},
+			HasMore: true,
+		}, nil).Once()
+		second := repository.Page{Limit: repository.MaxPageLimit, Cursor: &repository.Cursor{Field: repository.SortByID, ID: "2"}}
+		mockDB.On("ListPage", mock.Anything, repository.PowerPlantFilter{}, repository.PowerPlantSort{}, second).Return(&repository.PowerPlantPage{
+			PowerPlants: []model.PowerPlant{{ID: "3", Name: "Osaka Geothermal Plant", Latitude: 34.7054, Longitude: 135.4903, Status: model.PlantStatusPlanned}},
+			Cursors:     []repository.Cursor{{Field: repository.SortByID, ID: "3"}},
+		}, nil).Once()
+
+		collection, err := service.ExportPowerPlants(context.Background(), repository.PowerPlantFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, geojson.TypeFeatureCollection, collection.Type)
+		assert.Len(t, collection.Features, 2)
+
+		data, err := json.Marshal(collection.Features[0])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"type": "Feature", "id": "2",
+			"geometry": {"type": "Point", "coordinates": [140.0245, 40.2039]},
+			"properties": {"name": "Noshiro Wind Farm", "plantType": "WIND", "capacityMw": 88, "commissioningDate": "2015-04-01",
+				"status": "OPERATING", "elevation": 12, "createdAt": "2024-01-01T00:00:00Z"}
+		}`, string(data))
+		assert.Equal(t, "3", collection.Features[1].FeatureID())
+	})
+
+	t.Run("fail due to an invalid filter", func(t *testing.T) {
+		service, _, _ := setupTests(t)
+
+		_, err := service.ExportPowerPlants(context.Background(), repository.PowerPlantFilter{MinCapacityMw: floatPointer(10), MaxCapacityMw: floatPointer(1)})
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
+
+func TestImportPowerPlants(t *testing.T) {
+	decode := func(t *testing.T, s string) geojson.FeatureCollection {
+		var collection geojson.FeatureCollection
+		assert.NoError(t, json.Unmarshal([]byte(s), &collection))
+		return collection
+	}
+
+	t.Run("create and update power plants and report invalid features", func(t *testing.T) {
+		service, mockDB, mockOpenMeteo := setupTests(t)
+
+		collection := decode(t, `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "Nagoya Wind Farm", "plantType": "WIND", "capacityMw": 50}},
+			{"type": "Feature", "id": "7", "geometry": {"type": "Point", "coordinates": [139.638, 35.4437]}, "properties": {"name": "Yokohama Solar", "status": "MAINTENANCE"}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "X"}},
+			{"type": "Feature", "id": 999, "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "Unknown Plant"}},
+			{"type": "Feature", "id": "way/123", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "OpenStreetMap Plant"}},
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": []}, "properties": {"name": "Region"}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "Fusion Plant", "plantType": "FUSION"}}
+		]}`)
+
+		wind := model.PlantTypeWind
+		mockOpenMeteo.On("GetElevation", mock.Anything, 35.18, 136.9).Return(10.0, nil).Once()
+		mockDB.On("Create", mock.Anything, mock.MatchedBy(func(plant *model.PowerPlant) bool {
+			return plant.Name == "Nagoya Wind Farm" && *plant.PlantType == wind && *plant.CapacityMw == 50 && plant.Status == model.PlantStatusOperating
+		})).Return(&model.PowerPlant{ID: "31"}, nil).Once()
+
+		current := &model.PowerPlant{ID: "7", Name: "Yokohama Urban Solar Array", Latitude: 35.4437, Longitude: 139.638, Elevation: floatPointer(3)}
+		mockDB.On("GetByID", mock.Anything, "7", false).Return(current, nil)
+		maintenance := model.PlantStatusMaintenance
+		mockDB.On("Update", mock.Anything, mock.MatchedBy(func(patch *model.PowerPlantPatch) bool {
+			return patch.ID == "7" && *patch.Name == "Yokohama Solar" && *patch.Status == maintenance && *patch.Elevation == 3
+		})).Return(nil).Once()
+
+		mockDB.On("GetByID", mock.Anything, "999", false).Return(nil, repository.ErrNotFound).Once()
+
+		result, err := service.ImportPowerPlants(context.Background(), collection)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 1, result.Updated)
+		assert.Len(t, result.Errors, 5)
+
+		assert.Equal(t, 2, result.Errors[0].Index)
+		assert.Nil(t, result.Errors[0].ID)
+		assert.Contains(t, result.Errors[0].Message, "Name")
+		assert.Equal(t, 3, result.Errors[1].Index)
+		assert.Equal(t, "999", *result.Errors[1].ID)
+		assert.Contains(t, result.Errors[1].Message, "not found")
+		assert.Contains(t, result.Errors[1].Message, "only the IDs of exported power plants can be imported")
+		assert.Equal(t, 4, result.Errors[2].Index)
+		assert.Equal(t, "way/123", *result.Errors[2].ID)
+		assert.Contains(t, result.Errors[2].Message, `id "way/123" is not the ID of a power plant`)
+		assert.Contains(t, result.Errors[3].Message, "must be a Point")
+		assert.Contains(t, result.Errors[4].Message, `unknown plant type "FUSION"`)
+	})
+
+	t.Run("clear the details that are null", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		collection := decode(t, `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "id": "7", "geometry": {"type": "Point", "coordinates": [139.638, 35.4437]}, "properties": {"name": "Yokohama Solar", "capacityMw": null}}
+		]}`)
+
+		solar := model.PlantTypeSolar
+		current := &model.PowerPlant{ID: "7", Name: "Yokohama Urban Solar Array", Latitude: 35.4437, Longitude: 139.638, PlantType: &solar, CapacityMw: floatPointer(20)}
+		mockDB.On("GetByID", mock.Anything, "7", false).Return(current, nil)
+		mockDB.On("Update", mock.Anything, mock.MatchedBy(func(patch *model.PowerPlantPatch) bool {
+			capacity, ok := patch.CapacityMw.ValueOK()
+			return ok && capacity == nil && !patch.PlantType.IsSet() && !patch.CommissioningDate.IsSet()
+		})).Return(nil).Once()
+
+		result, err := service.ImportPowerPlants(context.Background(), collection)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Updated)
+		assert.Empty(t, result.Errors)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("stop at a database error and return the features imported before", func(t *testing.T) {
+		service, mockDB, mockOpenMeteo := setupTests(t)
+
+		collection := decode(t, `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "Nagoya Wind Farm"}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "X"}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "Nagoya Solar"}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [136.9, 35.18]}, "properties": {"name": "Nagoya Hydro"}}
+		]}`)
+		mockOpenMeteo.On("GetElevation", mock.Anything, 35.18, 136.9).Return(10.0, nil).Twice()
+		mockDB.On("Create", mock.Anything, mock.Anything).Return(&model.PowerPlant{ID: "31"}, nil).Once()
+		mockDB.On("Create", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+
+		result, err := service.ImportPowerPlants(context.Background(), collection)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "feature 2")
+		assert.Equal(t, 1, result.Created)
+		assert.Len(t, result.Errors, 1)
+	})
+
+	t.Run("fail due to a feature instead of a collection", func(t *testing.T) {
+		service, _, _ := setupTests(t)
+
+		_, err := service.ImportPowerPlants(context.Background(), geojson.FeatureCollection{Type: geojson.TypeFeature})
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/glower/kaze/graph/model"
+	"github.com/glower/kaze/pkg/dataloader"
+	"github.com/glower/kaze/pkg/geojson"
 	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/weather"
 )
 
 // PowerPlantService defines the interface for operations on power plants.
@@ -14,88 +18,230 @@ import (
 //go:generate go run github.com/vektra/mockery/v2@v2 --name=PowerPlantService --filename=power_plant_service.go --output=../../mocks/
 type PowerPlantService interface {
 	CreatePowerPlant(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error)
-	UpdatePowerPlant(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error)
-	GetPowerPlant(ctx context.Context, id string, withElevation, withWeatherForecasts bool) (*model.PowerPlant, error)
-	ListPowerPlants(ctx context.Context, page, pageSize int, withElevation, withWeatherForecasts bool) (*model.PowerPlantList, error)
+	UpdatePowerPlant(ctx context.Context, patch *model.PowerPlantPatch) (*model.PowerPlant, error)
+	GetPowerPlant(ctx context.Context, id string, includeDeleted bool) (*model.PowerPlant, error)
+	ListPowerPlants(ctx context.Context, page, pageSize int, filter repository.PowerPlantFilter, sort repository.PowerPlantSort) (*model.PowerPlantList, error)
+	PagePowerPlants(ctx context.Context, filter repository.PowerPlantFilter, sort repository.PowerPlantSort, page repository.Page) (*model.PowerPlantConnection, error)
+	PlantsNear(ctx context.Context, opts repository.NearOptions) ([]*model.PowerPlantDistance, error)
+	PlantsWithin(ctx context.Context, area geojson.Geometry, limit int) ([]*model.PowerPlant, error)
+	ExportPowerPlants(ctx context.Context, filter repository.PowerPlantFilter) (*geojson.FeatureCollection, error)
+	ImportPowerPlants(ctx context.Context, collection geojson.FeatureCollection) (*model.ImportResult, error)
+	DeletePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error)
+	RestorePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error)
+	GetElevation(ctx context.Context, plant *model.PowerPlant) (float64, error)
+	GetWeatherForecasts(ctx context.Context, plant *model.PowerPlant, forecastOpts repository.ForecastOptions) ([]*model.WeatherForecast, error)
+	WeatherProviders(ctx context.Context) []*model.WeatherProviderInfo
+	GetDailyWeather(ctx context.Context, plant *model.PowerPlant, days int) ([]*model.DailyWeather, error)
+	GetHistoricalWeather(ctx context.Context, plant *model.PowerPlant, historicalOpts repository.HistoricalOptions) (*model.HistoricalWeather, error)
+	HasPrecipitation(ctx context.Context, plant *model.PowerPlant, date string) (bool, error)
+	BackfillElevation(ctx context.Context, batchSize int) (int, error)
 }
 
+// DateLayout is the layout of calendar dates accepted by the service.
+const DateLayout = "2006-01-02"
+
 // powerPlantService provides services related to power plants.
 type powerPlantService struct {
-	dbRepo        repository.PowerPlantRepository
-	openMeteoRepo repository.OpenMeteoRepository
+	dbRepo           repository.PowerPlantRepository
+	openMeteoRepo    repository.OpenMeteoRepository
+	weatherProviders *weather.Providers
+	now              func() time.Time
 }
 
-// NewPowerPlantService creates a new instance of PowerPlantService.
-func NewPowerPlantService(dbRepo repository.PowerPlantRepository, openMeteoRepo repository.OpenMeteoRepository) PowerPlantService {
+// NewPowerPlantService creates a new instance of PowerPlantService. The elevations are fetched from Open-Meteo,
+// the weather forecasts from the weather provider of the power plant or the default provider.
+func NewPowerPlantService(dbRepo repository.PowerPlantRepository, openMeteoRepo repository.OpenMeteoRepository, weatherProviders *weather.Providers) PowerPlantService {
 	return &powerPlantService{
-		dbRepo:        dbRepo,
-		openMeteoRepo: openMeteoRepo,
+		dbRepo:           dbRepo,
+		openMeteoRepo:    openMeteoRepo,
+		weatherProviders: weatherProviders,
+		now:              time.Now,
 	}
 }
 
 // CreatePowerPlant handles the creation of a new power plant.
 func (s *powerPlantService) CreatePowerPlant(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
 	slog.Debug("Creating a new power plant", "name", plant.Name)
+	if err := s.validateWeatherProvider(plant.WeatherProvider); err != nil {
+		return nil, err
+	}
+	if err := plant.Validate(s.now()); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	s.setElevation(ctx, plant)
 	return s.dbRepo.Create(ctx, plant)
 }
 
-// UpdatePowerPlant handles updating an existing power plant.
-func (s *powerPlantService) UpdatePowerPlant(ctx context.Context, plant *model.PowerPlant) (*model.PowerPlant, error) {
-	slog.Debug("Updating power plant", "id", plant.ID)
-	_, err := s.dbRepo.Update(ctx, plant)
-	if err != nil {
-		return nil, fmt.Errorf("could not update power plant: %w", err)
+// UpdatePowerPlant handles updating an existing power plant with the fields set in the patch.
+// The elevation is fetched again if the coordinates of the power plant change, the type, capacity,
+// commissioning date and status are validated together with the stored fields.
+func (s *powerPlantService) UpdatePowerPlant(ctx context.Context, patch *model.PowerPlantPatch) (*model.PowerPlant, error) {
+	slog.Debug("Updating power plant", "id", patch.ID)
+	if err := s.validateWeatherProvider(patch.WeatherProvider); err != nil {
+		return nil, err
+	}
+
+	moves := patch.Latitude != nil || patch.Longitude != nil
+	if moves || patch.ChangesDetails() {
+		current, err := s.dbRepo.GetByID(ctx, patch.ID, false)
+		if err != nil {
+			return nil, fmt.Errorf("could not update power plant: %w", powerPlantError(patch.ID, err))
+		}
+
+		updated := patch.Apply(*current)
+		if err := updated.Validate(s.now()); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+
+		if moves {
+			if updated.Latitude != current.Latitude || updated.Longitude != current.Longitude {
+				s.setElevation(ctx, &updated)
+			}
+			patch.Elevation = updated.Elevation
+		}
+	}
+
+	if err := s.dbRepo.Update(ctx, patch); err != nil {
+		return nil, fmt.Errorf("could not update power plant: %w", powerPlantError(patch.ID, err))
 	}
 	// Retrieve and return the updated plant
-	return s.dbRepo.GetByID(ctx, plant.ID)
+	return s.getPowerPlant(ctx, patch.ID, false)
 }
 
 // GetPowerPlant retrieves a specific power plant by its ID.
-func (s *powerPlantService) GetPowerPlant(ctx context.Context, id string, withElevation, withWeatherForecasts bool) (*model.PowerPlant, error) {
-	slog.Debug("Retrieving power plant", "id", id, "withElevation", withElevation, "withWeatherForecasts", withWeatherForecasts)
-	plant, err := s.dbRepo.GetByID(ctx, id)
+// Deleted power plants are only returned if includeDeleted is set.
+func (s *powerPlantService) GetPowerPlant(ctx context.Context, id string, includeDeleted bool) (*model.PowerPlant, error) {
+	slog.Debug("Retrieving power plant", "id", id, "includeDeleted", includeDeleted)
+	return s.getPowerPlant(ctx, id, includeDeleted)
+}
+
+// PagePowerPlants retrieves a page of the power plants matching the filter in the order of the sort with keyset
+// pagination. The total count of the connection is only counted if it is requested.
+func (s *powerPlantService) PagePowerPlants(ctx context.Context, filter repository.PowerPlantFilter, sort repository.PowerPlantSort, page repository.Page) (*model.PowerPlantConnection, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	if err := sort.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	if err := page.Validate(sort); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	slog.Debug("Paging power plants", "filter", filter, "sort", sort, "page", page)
+	result, err := s.dbRepo.ListPage(ctx, filter, sort, page)
 	if err != nil {
 		return nil, err
 	}
 
-	// Fetch additional data based on request
-	if withElevation {
-		if err := s.fetchElevation(ctx, plant); err != nil {
-			return nil, err
-		}
+	edges := make([]*model.PowerPlantEdge, 0, len(result.PowerPlants))
+	for i := range result.PowerPlants {
+		edges = append(edges, &model.PowerPlantEdge{Cursor: result.Cursors[i].String(), Node: &result.PowerPlants[i]})
 	}
 
-	if withWeatherForecasts {
-		if err := s.fetchWeatherForecasts(ctx, plant); err != nil {
-			return nil, err
-		}
+	// the cursor of the request is a power plant in the other direction
+	pageInfo := &model.PageInfo{
+		HasNextPage:     result.HasMore,
+		HasPreviousPage: page.Cursor != nil,
+	}
+	if page.Backward {
+		pageInfo.HasNextPage, pageInfo.HasPreviousPage = pageInfo.HasPreviousPage, pageInfo.HasNextPage
+	}
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
 	}
 
-	return plant, nil
+	return &model.PowerPlantConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+		Count: func(ctx context.Context) (int, error) {
+			return s.dbRepo.Count(ctx, filter)
+		},
+	}, nil
+}
+
+// PlantsNear retrieves the power plants within the radius around the center of the options, the nearest first.
+func (s *powerPlantService) PlantsNear(ctx context.Context, opts repository.NearOptions) ([]*model.PowerPlantDistance, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	slog.Debug("Retrieving power plants near a point", "opts", opts)
+	plants, err := s.dbRepo.ListNear(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	distances := make([]*model.PowerPlantDistance, 0, len(plants))
+	for i := range plants {
+		distances = append(distances, &model.PowerPlantDistance{PowerPlant: &plants[i].PowerPlant, DistanceKm: plants[i].DistanceKm})
+	}
+	return distances, nil
+}
+
+// PlantsWithin retrieves up to limit power plants inside a Polygon or MultiPolygon geometry, ordered by ID.
+func (s *powerPlantService) PlantsWithin(ctx context.Context, area geojson.Geometry, limit int) ([]*model.PowerPlant, error) {
+	polygons, err := area.Polygons()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	opts := repository.WithinOptions{Polygons: polygons, Limit: limit}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	slog.Debug("Retrieving power plants within an area", "type", area.Type, "polygons", len(polygons), "limit", limit)
+	plants, err := s.dbRepo.ListWithin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plantPointers := make([]*model.PowerPlant, 0, len(plants))
+	for i := range plants {
+		plantPointers = append(plantPointers, &plants[i])
+	}
+	return plantPointers, nil
+}
+
+// DeletePowerPlant soft deletes a power plant and returns it with its deletion time.
+func (s *powerPlantService) DeletePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
+	slog.Debug("Deleting power plant", "id", id)
+	if err := s.dbRepo.Delete(ctx, id); err != nil {
+		return nil, fmt.Errorf("could not delete power plant: %w", powerPlantError(id, err))
+	}
+	return s.getPowerPlant(ctx, id, true)
 }
 
-// ListPowerPlants retrieves a list of power plants with optional elevation and weather forecast data.
-func (s *powerPlantService) ListPowerPlants(ctx context.Context, page, pageSize int, withElevation, withWeatherForecasts bool) (*model.PowerPlantList, error) {
+// RestorePowerPlant restores a soft deleted power plant.
+func (s *powerPlantService) RestorePowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
+	slog.Debug("Restoring power plant", "id", id)
+	if err := s.dbRepo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("could not restore power plant: %w", powerPlantError(id, err))
+	}
+	return s.getPowerPlant(ctx, id, false)
+}
+
+// ListPowerPlants retrieves a page of the power plants matching the filter in the order of the sort.
+// Deleted power plants are only listed if the filter includes them.
+func (s *powerPlantService) ListPowerPlants(ctx context.Context, page, pageSize int, filter repository.PowerPlantFilter, sort repository.PowerPlantSort) (*model.PowerPlantList, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	if err := sort.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
 	offset := (page - 1) * pageSize
-	slog.Debug("Listing power plants", "page", page, "pageSize", pageSize)
-	plants, total, err := s.dbRepo.List(ctx, offset, pageSize)
+	slog.Debug("Listing power plants", "page", page, "pageSize", pageSize, "filter", filter, "sort", sort)
+	plants, total, err := s.dbRepo.List(ctx, offset, pageSize, filter, sort)
 	if err != nil {
 		return nil, err
 	}
 
-	var plantPointers []*model.PowerPlant
-	for _, plant := range plants {
-		if withElevation {
-			if err := s.fetchElevation(ctx, &plant); err != nil {
-				return nil, err
-			}
-		}
-		if withWeatherForecasts {
-			if err := s.fetchWeatherForecasts(ctx, &plant); err != nil {
-				return nil, err
-			}
-		}
-		plantPointers = append(plantPointers, &plant)
+	plantPointers := make([]*model.PowerPlant, 0, len(plants))
+	for i := range plants {
+		plantPointers = append(plantPointers, &plants[i])
 	}
 
 	return &model.PowerPlantList{
@@ -104,48 +250,450 @@ func (s *powerPlantService) ListPowerPlants(ctx context.Context, page, pageSize
 	}, nil
 }
 
-func (s *powerPlantService) fetchElevation(ctx context.Context, plant *model.PowerPlant) error {
-	elevation, err := s.openMeteoRepo.GetElevation(ctx, plant.Latitude, plant.Longitude)
+// GetElevation returns the stored elevation of the power plant, or retrieves it from the api if
+// it was not stored yet.
+func (s *powerPlantService) GetElevation(ctx context.Context, plant *model.PowerPlant) (float64, error) {
+	if plant.Elevation != nil {
+		return *plant.Elevation, nil
+	}
+
+	slog.Debug("Retrieving elevation", "id", plant.ID)
+	elevation, err := s.loadElevation(ctx, plant)
 	if err != nil {
-		return fmt.Errorf("can't get elevation data from the api: %w", err)
+		return 0, upstreamError("can't get elevation data from the api", err)
+	}
+	return elevation, nil
+}
+
+// GetWeatherForecasts retrieves the hourly weather forecasts for the power plant from the api.
+func (s *powerPlantService) GetWeatherForecasts(ctx context.Context, plant *model.PowerPlant, forecastOpts repository.ForecastOptions) ([]*model.WeatherForecast, error) {
+	slog.Debug("Retrieving weather forecasts", "id", plant.ID, "forecastOpts", forecastOpts)
+	forecast, err := s.loadWeatherForecast(ctx, plant, forecastOpts)
+	if err != nil {
+		return nil, upstreamError("can't get weather forecast data from the api", err)
+	}
+
+	return mapHourlyWeatherDataToForecasts(forecast, forecastOpts.Units), nil
+}
+
+// WeatherProviders returns the weather providers of the deployment with the range of their forecasts.
+func (s *powerPlantService) WeatherProviders(ctx context.Context) []*model.WeatherProviderInfo {
+	names := s.weatherProviders.Names()
+	providers := make([]*model.WeatherProviderInfo, 0, len(names))
+	for _, name := range names {
+		provider, err := s.weatherProviders.Get(name)
+		if err != nil {
+			continue
+		}
+		coverage := provider.Coverage()
+		providers = append(providers, &model.WeatherProviderInfo{
+			Name:          name,
+			Default:       name == s.weatherProviders.Default(),
+			ForecastHours: coverage.ForecastHours,
+			PastDays:      coverage.PastDays,
+		})
+	}
+	return providers
+}
+
+// GetDailyWeather retrieves the daily weather aggregates of the next days for the power plant from Open-Meteo.
+func (s *powerPlantService) GetDailyWeather(ctx context.Context, plant *model.PowerPlant, days int) ([]*model.DailyWeather, error) {
+	slog.Debug("Retrieving daily weather", "id", plant.ID, "days", days)
+	daily, err := s.loadDailyWeather(ctx, plant, days)
+	if err != nil {
+		return nil, upstreamError("can't get daily weather data from the api", err)
+	}
+
+	aggregates, err := mapDailyWeatherData(daily)
+	if err != nil {
+		return nil, upstreamError("invalid daily weather data from the api", err)
+	}
+	return aggregates, nil
+}
+
+// GetHistoricalWeather retrieves the hourly or daily historical weather for the power plant from the Open-Meteo archive.
+func (s *powerPlantService) GetHistoricalWeather(ctx context.Context, plant *model.PowerPlant, historicalOpts repository.HistoricalOptions) (*model.HistoricalWeather, error) {
+	slog.Debug("Retrieving historical weather", "id", plant.ID, "historicalOpts", historicalOpts)
+	if err := historicalOpts.Validate(s.now()); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	historical, err := s.loadHistoricalWeather(ctx, plant, historicalOpts)
+	if err != nil {
+		return nil, upstreamError("can't get historical weather data from the api", err)
+	}
+
+	if historicalOpts.Resolution == repository.ResolutionDaily {
+		days, err := mapDailyWeatherData(historical.DailyWeather())
+		if err != nil {
+			return nil, upstreamError("invalid historical weather data from the api", err)
+		}
+		return &model.HistoricalWeather{Daily: days}, nil
+	}
+
+	hours, err := mapHistoricalHours(historical)
+	if err != nil {
+		return nil, upstreamError("invalid historical weather data from the api", err)
+	}
+	return &model.HistoricalWeather{Hourly: hours}, nil
+}
+
+// HasPrecipitation reports whether there is precipitation at the power plant on the given
+// local calendar day (YYYY-MM-DD). If date is empty, the current day at the power plant is used.
+func (s *powerPlantService) HasPrecipitation(ctx context.Context, plant *model.PowerPlant, date string) (bool, error) {
+	slog.Debug("Checking precipitation", "id", plant.ID, "date", date)
+	now := s.now()
+
+	forecastOpts, err := precipitationForecastOptions(date, now)
+	if err != nil {
+		return false, err
+	}
+
+	forecast, err := s.loadWeatherForecast(ctx, plant, forecastOpts)
+	if err != nil {
+		return false, upstreamError("can't get weather forecast data from the api", err)
+	}
+
+	return hasPrecipitationOn(forecast, date, now)
+}
+
+// BackfillElevation fetches and stores the elevation of all power plants without a stored elevation,
+// batchSize power plants at a time. It returns the number of updated power plants.
+func (s *powerPlantService) BackfillElevation(ctx context.Context, batchSize int) (int, error) {
+	updated := 0
+	for {
+		plants, err := s.dbRepo.ListWithoutElevation(ctx, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(plants) == 0 {
+			return updated, nil
+		}
+
+		coordinates := make([]repository.Coordinates, 0, len(plants))
+		for _, plant := range plants {
+			coordinates = append(coordinates, repository.Coordinates{Latitude: plant.Latitude, Longitude: plant.Longitude})
+		}
+
+		elevations, err := s.openMeteoRepo.GetElevations(ctx, coordinates)
+		if err != nil {
+			return updated, upstreamError("can't get elevation data from the api", err)
+		}
+
+		for i, plant := range plants {
+			if err := s.dbRepo.UpdateElevation(ctx, plant.ID, elevations[i]); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+		slog.Info("Backfilled elevation of power plants", "count", updated)
+
+		if len(plants) < batchSize {
+			return updated, nil
+		}
 	}
-	plant.Elevation = elevation
-	return nil
 }
 
-// Helper functions for fetching additional data (elevation and weather forecasts) are defined below...
+// getPowerPlant retrieves a power plant from the database and translates a missing plant into ErrNotFound.
+func (s *powerPlantService) getPowerPlant(ctx context.Context, id string, includeDeleted bool) (*model.PowerPlant, error) {
+	plant, err := s.dbRepo.GetByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, powerPlantError(id, err)
+	}
+	return plant, nil
+}
 
-func (s *powerPlantService) fetchWeatherForecasts(ctx context.Context, plant *model.PowerPlant) error {
-	forecast, err := s.openMeteoRepo.GetWeatherForecast(ctx, plant.Latitude, plant.Longitude)
+// setElevation fetches the elevation of the power plant before it is stored. The stored elevation
+// is optional, so errors are only logged and the elevation is filled later by BackfillElevation.
+func (s *powerPlantService) setElevation(ctx context.Context, plant *model.PowerPlant) {
+	elevation, err := s.loadElevation(ctx, plant)
 	if err != nil {
-		return fmt.Errorf("can't get weather forecast data from the api: %w", err)
+		slog.Warn("Can't get elevation data from the api, it has to be backfilled", "error", err, "name", plant.Name)
+		plant.Elevation = nil
+		return
 	}
+	plant.Elevation = &elevation
+}
 
-	mappedForecast, hasPrecipitationToday := mapHourlyWeatherDataToForecasts(forecast)
-	plant.WeatherForecasts = mappedForecast
-	plant.HasPrecipitationToday = hasPrecipitationToday
+// loadElevation fetches the elevation through the dataloader of the request, so the elevations
+// of all power plants in one GraphQL operation are fetched with a single api call.
+func (s *powerPlantService) loadElevation(ctx context.Context, plant *model.PowerPlant) (float64, error) {
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.ElevationLoader.Load(ctx, repository.Coordinates{Latitude: plant.Latitude, Longitude: plant.Longitude})
+	}
+	return s.openMeteoRepo.GetElevation(ctx, plant.Latitude, plant.Longitude)
+}
 
+// validateWeatherProvider checks that the weather provider is configured in the deployment,
+// the providers and their names depend on the configuration, e.g. "openmeteo:icon_seamless".
+func (s *powerPlantService) validateWeatherProvider(name *string) error {
+	if name == nil || *name == "" {
+		return nil
+	}
+	if _, err := s.weatherProviders.Get(*name); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
 	return nil
 }
 
-func mapHourlyWeatherDataToForecasts(response *repository.WeatherForecastResponse) ([]*model.WeatherForecast, bool) {
-	var forecasts []*model.WeatherForecast
-	hasPrecipitationToday := false
+// loadWeatherForecast fetches the weather forecast from the weather provider of the power plant through the
+// dataloader of the request, so the forecasts of all power plants in one GraphQL operation are fetched
+// with as few api calls as possible.
+func (s *powerPlantService) loadWeatherForecast(ctx context.Context, plant *model.PowerPlant, forecastOpts repository.ForecastOptions) (*weather.Forecast, error) {
+	coordinates := repository.Coordinates{Latitude: plant.Latitude, Longitude: plant.Longitude}
+	providerName := ""
+	if plant.WeatherProvider != nil {
+		providerName = *plant.WeatherProvider
+	}
+
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.WeatherForecastLoader.Load(ctx, dataloader.ForecastKey{
+			Provider:    providerName,
+			Coordinates: coordinates,
+			Options:     forecastOpts,
+		})
+	}
+
+	provider, err := s.weatherProviders.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	forecasts, err := provider.GetForecasts(ctx, []repository.Coordinates{coordinates}, forecastOpts)
+	if err != nil {
+		return nil, err
+	}
+	return forecasts[0], nil
+}
+
+// loadDailyWeather fetches the daily weather from Open-Meteo through the dataloader of the request, if there is one.
+func (s *powerPlantService) loadDailyWeather(ctx context.Context, plant *model.PowerPlant, days int) (*repository.DailyWeatherResponse, error) {
+	coordinates := repository.Coordinates{Latitude: plant.Latitude, Longitude: plant.Longitude}
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.DailyWeatherLoader.Load(ctx, dataloader.DailyWeatherKey{Coordinates: coordinates, Days: days})
+	}
+
+	daily, err := s.openMeteoRepo.GetDailyWeather(ctx, []repository.Coordinates{coordinates}, days)
+	if err != nil {
+		return nil, err
+	}
+	return daily[0], nil
+}
+
+// loadHistoricalWeather fetches the historical weather from Open-Meteo through the dataloader of the request, if there is one.
+func (s *powerPlantService) loadHistoricalWeather(ctx context.Context, plant *model.PowerPlant, historicalOpts repository.HistoricalOptions) (*repository.HistoricalWeatherResponse, error) {
+	coordinates := repository.Coordinates{Latitude: plant.Latitude, Longitude: plant.Longitude}
+	if loaders := dataloader.For(ctx); loaders != nil {
+		return loaders.HistoricalLoader.Load(ctx, dataloader.HistoricalWeatherKey{Coordinates: coordinates, Options: historicalOpts})
+	}
+
+	historical, err := s.openMeteoRepo.GetHistoricalWeather(ctx, []repository.Coordinates{coordinates}, historicalOpts)
+	if err != nil {
+		return nil, err
+	}
+	return historical[0], nil
+}
+
+// precipitationForecastOptions returns forecast options covering the given local calendar day.
+// The local day of the power plant is unknown before the forecast is fetched and may differ
+// by one day from the UTC day, so the range is extended by one day in both directions.
+func precipitationForecastOptions(date string, now time.Time) (repository.ForecastOptions, error) {
+	if date == "" {
+		return repository.ForecastOptions{ForecastDays: 1}, nil
+	}
+
+	day, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return repository.ForecastOptions{}, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	diff := int(day.Sub(today).Hours() / 24)
+
+	forecastOpts := repository.ForecastOptions{
+		ForecastDays: max(1, diff+2),
+		PastDays:     max(0, 1-diff),
+	}
+	if err := forecastOpts.Validate(); err != nil {
+		return forecastOpts, fmt.Errorf("%w: %s", ErrDateOutOfRange, date)
+	}
+
+	return forecastOpts, nil
+}
+
+// mapHourlyWeatherDataToForecasts converts the hourly forecast in the given units into GraphQL forecasts.
+func mapHourlyWeatherDataToForecasts(forecast *weather.Forecast, units repository.Units) []*model.WeatherForecast {
+	weatherUnits := mapUnits(units)
+	fetchedAt := timeOrNil(forecast.FetchedAt)
+
+	forecasts := make([]*model.WeatherForecast, 0, len(forecast.Hourly))
+	for _, hour := range forecast.Hourly {
+		forecasts = append(forecasts, &model.WeatherForecast{
+			Time:          hour.Time.UTC(),
+			Temperature:   hour.Temperature,
+			WindSpeed:     hour.WindSpeed,
+			Precipitation: hour.Precipitation,
+			WindDirection: hour.WindDirection,
+			Stale:         forecast.Stale,
+			FetchedAt:     fetchedAt,
+			Source:        hour.Source,
+			Units:         weatherUnits,
+
+			WindSpeed80m:           hour.Value(repository.WindSpeed80m),
+			WindSpeed120m:          hour.Value(repository.WindSpeed120m),
+			WindSpeed180m:          hour.Value(repository.WindSpeed180m),
+			WindDirection80m:       hour.Value(repository.WindDirection80m),
+			WindDirection120m:      hour.Value(repository.WindDirection120m),
+			WindDirection180m:      hour.Value(repository.WindDirection180m),
+			WindGusts10m:           hour.Value(repository.WindGusts10m),
+			CloudCoverLow:          hour.Value(repository.CloudCoverLow),
+			CloudCoverMid:          hour.Value(repository.CloudCoverMid),
+			CloudCoverHigh:         hour.Value(repository.CloudCoverHigh),
+			ShortwaveRadiation:     hour.Value(repository.ShortwaveRadiation),
+			DirectRadiation:        hour.Value(repository.DirectRadiation),
+			DiffuseRadiation:       hour.Value(repository.DiffuseRadiation),
+			GlobalTiltedIrradiance: hour.Value(repository.GlobalTiltedIrradiance),
+			SurfacePressure:        hour.Value(repository.SurfacePressure),
+			RelativeHumidity2m:     hour.Value(repository.RelativeHumidity2m),
+			Snowfall:               hour.Value(repository.Snowfall),
+			SnowDepth:              hour.Value(repository.SnowDepth),
+		})
+	}
+
+	return forecasts
+}
+
+var (
+	temperatureUnits = map[repository.TemperatureUnit]model.TemperatureUnit{
+		repository.Celsius:    model.TemperatureUnitCelsius,
+		repository.Fahrenheit: model.TemperatureUnitFahrenheit,
+	}
+	windSpeedUnits = map[repository.WindSpeedUnit]model.WindSpeedUnit{
+		repository.KilometersPerHour: model.WindSpeedUnitKmh,
+		repository.MetersPerSecond:   model.WindSpeedUnitMs,
+		repository.Knots:             model.WindSpeedUnitKn,
+		repository.MilesPerHour:      model.WindSpeedUnitMph,
+	}
+	precipitationUnits = map[repository.PrecipitationUnit]model.PrecipitationUnit{
+		repository.Millimeters: model.PrecipitationUnitMillimeter,
+		repository.Inches:      model.PrecipitationUnitInch,
+	}
+)
 
-	for i, timeStr := range response.Hourly.Time {
-		if response.Hourly.Precipitation[i] > 0 {
-			hasPrecipitationToday = true
+// mapUnits converts the units of a forecast into GraphQL units, units that are not set are the metric defaults.
+func mapUnits(units repository.Units) *model.WeatherUnits {
+	units = units.WithDefaults()
+	return &model.WeatherUnits{
+		Temperature:   temperatureUnits[units.Temperature],
+		WindSpeed:     windSpeedUnits[units.WindSpeed],
+		Precipitation: precipitationUnits[units.Precipitation],
+	}
+}
+
+// mapDailyWeatherData converts the daily Open-Meteo aggregates into GraphQL daily weather.
+func mapDailyWeatherData(response *repository.DailyWeatherResponse) ([]*model.DailyWeather, error) {
+	dates, err := response.Dates()
+	if err != nil {
+		return nil, err
+	}
+
+	fetchedAt := timeOrNil(response.FetchedAt)
+	daily := response.Daily
+	days := make([]*model.DailyWeather, 0, len(dates))
+	for i, date := range dates {
+		days = append(days, &model.DailyWeather{
+			Date:                  model.NewDate(date),
+			TemperatureMin:        valueAt(daily.Temperature2mMin, i),
+			TemperatureMax:        valueAt(daily.Temperature2mMax, i),
+			PrecipitationSum:      valueAt(daily.PrecipitationSum, i),
+			WindSpeedMax:          valueAt(daily.WindSpeed10mMax, i),
+			WindGustsMax:          valueAt(daily.WindGusts10mMax, i),
+			WindDirectionDominant: valueAt(daily.WindDirection10mDominant, i),
+			Sunrise:               utcOrNil(response.Sunrise(i)),
+			Sunset:                utcOrNil(response.Sunset(i)),
+			SunshineDuration:      valueAt(daily.SunshineDuration, i),
+			Stale:                 response.Stale,
+			FetchedAt:             fetchedAt,
+		})
+	}
+
+	return days, nil
+}
+
+// mapHistoricalHours translates the hourly historical weather, values the reanalysis has no data for are nil.
+func mapHistoricalHours(response *repository.HistoricalWeatherResponse) ([]*model.HistoricalHour, error) {
+	times, err := response.Times()
+	if err != nil {
+		return nil, err
+	}
+
+	hourly := response.Hourly
+	hours := make([]*model.HistoricalHour, 0, len(times))
+	for i, localTime := range times {
+		hours = append(hours, &model.HistoricalHour{
+			Time:          localTime.UTC(),
+			Temperature:   valueAt(hourly.Temperature2m, i),
+			Precipitation: valueAt(hourly.Precipitation, i),
+			WindSpeed:     valueAt(hourly.WindSpeed10m, i),
+			WindDirection: valueAt(hourly.WindDirection10m, i),
+		})
+	}
+
+	return hours, nil
+}
+
+// valueAt returns the i-th value, nil if Open-Meteo has no value for it.
+func valueAt(values []*float64, i int) *float64 {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
+
+// timeOrNil returns the time in UTC, nil for the zero time, e.g. the fetch time of a forecast that was not
+// fetched from an api.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return utcOrNil(&t)
+}
+
+// utcOrNil returns the time in UTC, nil if there is no time.
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// hasPrecipitationOn reports whether the hourly forecast has precipitation on the given local calendar day
+// of the forecast location. If day is empty, the current local day at the forecast location is used.
+func hasPrecipitationOn(forecast *weather.Forecast, day string, now time.Time) (bool, error) {
+	loc := forecast.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if day == "" {
+		day = now.In(loc).Format(DateLayout)
+	}
+
+	dayFound := false
+	for _, hour := range forecast.Hourly {
+		if hour.Time.In(loc).Format(DateLayout) != day {
+			continue
 		}
 
-		forecast := &model.WeatherForecast{
-			Time:          timeStr,
-			Temperature:   response.Hourly.Temperature2m[i],
-			WindSpeed:     response.Hourly.WindSpeed10m[i],
-			Precipitation: response.Hourly.Precipitation[i],
-			WindDirection: response.Hourly.WindDirection10m[i],
+		dayFound = true
+		if hour.Precipitation > 0 {
+			return true, nil
 		}
-		forecasts = append(forecasts, forecast)
 	}
 
-	return forecasts, hasPrecipitationToday
+	if !dayFound {
+		return false, fmt.Errorf("%w: %s", ErrDateOutOfRange, day)
+	}
+
+	return false, nil
 }
@@ -2,21 +2,27 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/glower/kaze/graph/model"
 	"github.com/glower/kaze/mocks"
+	"github.com/glower/kaze/pkg/geojson"
 	"github.com/glower/kaze/pkg/repository"
+	"github.com/glower/kaze/pkg/weather"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestCreatePowerPlant(t *testing.T) {
 	t.Run("failed due to database error", func(t *testing.T) {
-		service, mockDB, _ := setupTests(t)
+		service, mockDB, mockOpenMeteo := setupTests(t)
 		validPlant := &model.PowerPlant{Name: "Valid Plant", Latitude: 10.0, Longitude: 20.0}
 
+		mockOpenMeteo.On("GetElevation", mock.Anything, 10.0, 20.0).Return(100.0, nil)
 		mockDB.On("Create", mock.Anything, validPlant).Return(nil, assert.AnError)
 
 		_, err := service.CreatePowerPlant(context.Background(), validPlant)
@@ -26,71 +32,287 @@ func TestCreatePowerPlant(t *testing.T) {
 	})
 
 	t.Run("success", func(t *testing.T) {
-		service, mockDB, _ := setupTests(t)
+		service, mockDB, mockOpenMeteo := setupTests(t)
 		validPlant := &model.PowerPlant{Name: "Valid Plant", Latitude: 10.0, Longitude: 20.0}
 
+		mockOpenMeteo.On("GetElevation", mock.Anything, 10.0, 20.0).Return(100.0, nil)
 		mockDB.On("Create", mock.Anything, validPlant).Return(validPlant, nil)
 
 		result, err := service.CreatePowerPlant(context.Background(), validPlant)
 		assert.NoError(t, err, "Expected no error on successful creation")
 		assert.Equal(t, validPlant, result, "Expected created plant to match input")
+		assert.Equal(t, 100.0, *result.Elevation, "Expected elevation to be stored")
+
+		mockDB.AssertExpectations(t)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+
+	t.Run("success without elevation", func(t *testing.T) {
+		service, mockDB, mockOpenMeteo := setupTests(t)
+		validPlant := &model.PowerPlant{Name: "Valid Plant", Latitude: 10.0, Longitude: 20.0}
+
+		mockOpenMeteo.On("GetElevation", mock.Anything, 10.0, 20.0).Return(0.0, assert.AnError)
+		mockDB.On("Create", mock.Anything, validPlant).Return(validPlant, nil)
+
+		result, err := service.CreatePowerPlant(context.Background(), validPlant)
+		assert.NoError(t, err, "Expected the api error to be ignored")
+		assert.Nil(t, result.Elevation)
 
 		mockDB.AssertExpectations(t)
 	})
+
+	t.Run("fail for a capacity above the limit of the plant type", func(t *testing.T) {
+		service, mockDB, mockOpenMeteo := setupTests(t)
+		geothermal := model.PlantTypeGeothermal
+		plant := &model.PowerPlant{Name: "Valid Plant", Latitude: 10.0, Longitude: 20.0, PlantType: &geothermal, CapacityMw: floatPointer(5000)}
+
+		_, err := service.CreatePowerPlant(context.Background(), plant)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockDB.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockOpenMeteo.AssertNotCalled(t, "GetElevation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fail for a weather provider that is not configured", func(t *testing.T) {
+		service, _ := setupWeatherTests(t)
+		plant := &model.PowerPlant{Name: "Valid Plant", Latitude: 10.0, Longitude: 20.0, WeatherProvider: stringPointer(weather.DWD)}
+
+		_, err := service.CreatePowerPlant(context.Background(), plant)
+		assert.ErrorIs(t, err, ErrValidation)
+		assert.ErrorIs(t, err, weather.ErrUnknownProvider)
+	})
 }
 
-func TestGetPowerPlant(t *testing.T) {
-	t.Run("failed to retrieve non-existent plant", func(t *testing.T) {
+func TestUpdatePowerPlant(t *testing.T) {
+	stored := &model.PowerPlant{ID: "1", Name: "Valid Plant", Latitude: 10.0, Longitude: 20.0, Elevation: floatPointer(100)}
+
+	t.Run("keep elevation when the name changes", func(t *testing.T) {
 		service, mockDB, _ := setupTests(t)
+		patch := &model.PowerPlantPatch{ID: "1", Name: stringPointer("Renamed Plant")}
 
-		mockDB.On("GetByID", mock.Anything, "non-existent-id").Return(nil, fmt.Errorf("not found"))
+		mockDB.On("Update", mock.Anything, patch).Return(nil)
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(stored, nil)
 
-		_, err := service.GetPowerPlant(context.Background(), "non-existent-id", false, false)
-		assert.Error(t, err)
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.NoError(t, err)
+		assert.Nil(t, patch.Elevation, "Expected elevation not to be updated")
 
 		mockDB.AssertExpectations(t)
 	})
 
-	t.Run("failed to fetch elevation data", func(t *testing.T) {
+	t.Run("fetch elevation when the coordinates change", func(t *testing.T) {
 		service, mockDB, mockOpenMeteo := setupTests(t)
+		patch := &model.PowerPlantPatch{ID: "1", Latitude: floatPointer(11.0)}
 
-		validPlant := &model.PowerPlant{ID: "1", Name: "Valid Plant"}
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(stored, nil)
+		mockOpenMeteo.On("GetElevation", mock.Anything, 11.0, 20.0).Return(250.0, nil)
+		mockDB.On("Update", mock.Anything, patch).Return(nil)
 
-		mockDB.On("GetByID", mock.Anything, "1").Return(validPlant, nil)
-		mockOpenMeteo.On("GetElevation", mock.Anything, validPlant.Latitude, validPlant.Longitude).Return(0.0, fmt.Errorf("elevation error"))
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.NoError(t, err)
+		assert.Equal(t, 250.0, *patch.Elevation)
 
-		_, err := service.GetPowerPlant(context.Background(), "1", true, false)
-		assert.Error(t, err)
+		mockDB.AssertExpectations(t)
+		mockOpenMeteo.AssertExpectations(t)
+	})
+
+	t.Run("move the plant onto the equator and prime meridian", func(t *testing.T) {
+		service, mockDB, mockOpenMeteo := setupTests(t)
+		patch := &model.PowerPlantPatch{ID: "1", Latitude: floatPointer(0), Longitude: floatPointer(0)}
+
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(stored, nil)
+		mockOpenMeteo.On("GetElevation", mock.Anything, 0.0, 0.0).Return(0.0, nil)
+		mockDB.On("Update", mock.Anything, patch).Return(nil)
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, *patch.Latitude)
+		assert.Equal(t, 0.0, *patch.Longitude)
+		assert.Equal(t, 0.0, *patch.Elevation)
 
 		mockDB.AssertExpectations(t)
 		mockOpenMeteo.AssertExpectations(t)
 	})
 
-	t.Run("success with elevation and weather forecasts", func(t *testing.T) {
+	t.Run("fail for unknown power plant", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+		patch := &model.PowerPlantPatch{ID: "42", Name: stringPointer("Renamed Plant")}
+
+		mockDB.On("Update", mock.Anything, patch).Return(repository.ErrNotFound)
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		mockDB.AssertExpectations(t)
+		mockDB.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fail for a weather provider that is not configured", func(t *testing.T) {
+		service, _ := setupWeatherTests(t)
+		patch := &model.PowerPlantPatch{ID: "1", WeatherProvider: stringPointer("openmeteo:gfs_seamless")}
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("validate the capacity with the stored plant type", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+		tidal := model.PlantTypeTidal
+		tidalPlant := *stored
+		tidalPlant.PlantType = &tidal
+		patch := &model.PowerPlantPatch{ID: "1", CapacityMw: graphql.OmittableOf(floatPointer(1500))}
+
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(&tidalPlant, nil)
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockDB.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("clear the capacity of a tidal plant", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+		tidal := model.PlantTypeTidal
+		tidalPlant := *stored
+		tidalPlant.PlantType, tidalPlant.CapacityMw = &tidal, floatPointer(240)
+		patch := &model.PowerPlantPatch{ID: "1", CapacityMw: graphql.OmittableOf[*float64](nil)}
+
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(&tidalPlant, nil)
+		mockDB.On("Update", mock.Anything, patch).Return(nil)
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.NoError(t, err)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("fail to commission an operating plant in the future", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+		commissioning := model.NewDate(testNow.AddDate(1, 0, 0))
+		patch := &model.PowerPlantPatch{ID: "1", CommissioningDate: graphql.OmittableOf(&commissioning)}
+
+		operating := *stored
+		operating.Status = model.PlantStatusOperating
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(&operating, nil)
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("plan a plant with a commissioning date in the future", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+		commissioning := model.NewDate(testNow.AddDate(1, 0, 0))
+		planned := model.PlantStatusPlanned
+		patch := &model.PowerPlantPatch{ID: "1", CommissioningDate: graphql.OmittableOf(&commissioning), Status: &planned}
+
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(stored, nil)
+		mockDB.On("Update", mock.Anything, patch).Return(nil)
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.NoError(t, err)
+		assert.Nil(t, patch.Elevation, "Expected elevation not to be updated")
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("keep elevation when the coordinates do not change", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+		patch := &model.PowerPlantPatch{ID: "1", Latitude: floatPointer(10.0)}
+
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(stored, nil)
+		mockDB.On("Update", mock.Anything, patch).Return(nil)
+
+		_, err := service.UpdatePowerPlant(context.Background(), patch)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, *patch.Elevation)
+
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestGetPowerPlant(t *testing.T) {
+	t.Run("failed to retrieve non-existent plant", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		mockDB.On("GetByID", mock.Anything, "non-existent-id", false).Return(nil, repository.ErrNotFound)
+
+		_, err := service.GetPowerPlant(context.Background(), "non-existent-id", false)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("success without calling the api", func(t *testing.T) {
 		service, mockDB, mockOpenMeteo := setupTests(t)
 
 		validPlant := &model.PowerPlant{ID: "1", Name: "Valid Plant"}
 
-		mockDB.On("GetByID", mock.Anything, "1").Return(validPlant, nil)
-		mockOpenMeteo.On("GetElevation", mock.Anything, validPlant.Latitude, validPlant.Longitude).Return(100.0, nil)
-		mockOpenMeteo.On("GetWeatherForecast", mock.Anything, validPlant.Latitude, validPlant.Longitude).
-			Return(&repository.WeatherForecastResponse{
-				Hourly: repository.Hourly{
-					Time:             []string{"12"},
-					Precipitation:    []float64{100.1}, // to test HasPrecipitationToday
-					WindSpeed10m:     []float64{10},
-					Temperature2m:    []float64{10},
-					WindDirection10m: []float64{10},
-				},
-			}, nil)
-
-		result, err := service.GetPowerPlant(context.Background(), "1", true, true)
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(validPlant, nil)
+
+		result, err := service.GetPowerPlant(context.Background(), "1", false)
 		assert.NoError(t, err)
-		assert.NotNil(t, result)
-		assert.True(t, result.HasPrecipitationToday)
+		assert.Equal(t, validPlant, result)
+
+		mockDB.AssertExpectations(t)
+		mockOpenMeteo.AssertNotCalled(t, "GetElevation", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeletePowerPlant(t *testing.T) {
+	t.Run("failed to delete non-existent plant", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		mockDB.On("Delete", mock.Anything, "non-existent-id").Return(repository.ErrNotFound)
+
+		_, err := service.DeletePowerPlant(context.Background(), "non-existent-id")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		mockDB.AssertExpectations(t)
+		mockDB.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("success returns the deleted plant", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		deletedAt := testNow
+		deletedPlant := &model.PowerPlant{ID: "1", Name: "Deleted Plant", DeletedAt: &deletedAt}
+
+		mockDB.On("Delete", mock.Anything, "1").Return(nil)
+		mockDB.On("GetByID", mock.Anything, "1", true).Return(deletedPlant, nil)
+
+		result, err := service.DeletePowerPlant(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, deletedPlant, result)
+
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestRestorePowerPlant(t *testing.T) {
+	t.Run("failed to restore plant that is not deleted", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		mockDB.On("Restore", mock.Anything, "1").Return(repository.ErrConflict)
+
+		_, err := service.RestorePowerPlant(context.Background(), "1")
+		assert.ErrorIs(t, err, ErrConflict)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		restoredPlant := &model.PowerPlant{ID: "1", Name: "Restored Plant"}
+
+		mockDB.On("Restore", mock.Anything, "1").Return(nil)
+		mockDB.On("GetByID", mock.Anything, "1", false).Return(restoredPlant, nil)
+
+		result, err := service.RestorePowerPlant(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, restoredPlant, result)
 
 		mockDB.AssertExpectations(t)
-		mockOpenMeteo.AssertExpectations(t)
 	})
 }
 
@@ -99,40 +321,674 @@ func TestListPowerPlants(t *testing.T) {
 	t.Run("failed due to database error", func(t *testing.T) {
 		service, mockDB, _ := setupTests(t)
 
-		mockDB.On("List", mock.Anything, 0, 10).Return(nil, 0, fmt.Errorf("database error"))
-		
-		_, err := service.ListPowerPlants(context.Background(), 1, 10, false, false)
+		mockDB.On("List", mock.Anything, 0, 10, repository.PowerPlantFilter{}, repository.PowerPlantSort{}).Return(nil, 0, fmt.Errorf("database error"))
+
+		_, err := service.ListPowerPlants(context.Background(), 1, 10, repository.PowerPlantFilter{}, repository.PowerPlantSort{})
 		assert.Error(t, err)
-		
+
 		mockDB.AssertExpectations(t)
 	})
 
-	t.Run("success with elevation and weather forecasts", func(t *testing.T) {
-		service, mockDB, mockOpenMeteo := setupTests(t)
+	t.Run("success", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
 
 		plants := []model.PowerPlant{{ID: "1"}, {ID: "2"}}
-		
-		mockDB.On("List", mock.Anything, 0, 10).Return(plants, len(plants), nil)
-		mockOpenMeteo.On("GetElevation", mock.Anything, mock.AnythingOfType("float64"), mock.AnythingOfType("float64")).Return(100.0, nil).Twice()
-		mockOpenMeteo.On("GetWeatherForecast", mock.Anything, mock.AnythingOfType("float64"), mock.AnythingOfType("float64")).
-			Return(&repository.WeatherForecastResponse{}, nil).Twice()
 
-		result, err := service.ListPowerPlants(context.Background(), 1, 10, true, true)
+		mockDB.On("List", mock.Anything, 0, 10, repository.PowerPlantFilter{}, repository.PowerPlantSort{}).Return(plants, len(plants), nil)
+
+		result, err := service.ListPowerPlants(context.Background(), 1, 10, repository.PowerPlantFilter{}, repository.PowerPlantSort{})
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, 2, result.TotalCount)
 		assert.Len(t, result.PowerPlants, 2)
+		assert.Equal(t, "1", result.PowerPlants[0].ID)
+		assert.Equal(t, "2", result.PowerPlants[1].ID)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("success including deleted plants", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		plants := []model.PowerPlant{{ID: "1"}, {ID: "2", DeletedAt: &testNow}}
+
+		mockDB.On("List", mock.Anything, 10, 10, repository.PowerPlantFilter{IncludeDeleted: true}, repository.PowerPlantSort{}).Return(plants, 12, nil)
+
+		result, err := service.ListPowerPlants(context.Background(), 2, 10, repository.PowerPlantFilter{IncludeDeleted: true}, repository.PowerPlantSort{})
+		assert.NoError(t, err)
+		assert.Equal(t, 12, result.TotalCount)
+		assert.NotNil(t, result.PowerPlants[1].DeletedAt)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("success with filter and sort", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		filter := repository.PowerPlantFilter{PlantTypes: []model.PlantType{model.PlantTypeWind}, MinCapacityMw: floatPointer(100)}
+		sort := repository.PowerPlantSort{Field: repository.SortByDistance, From: &repository.Coordinates{Latitude: 35.68, Longitude: 139.69}}
+		mockDB.On("List", mock.Anything, 0, 10, filter, sort).Return([]model.PowerPlant{{ID: "2"}}, 1, nil)
+
+		result, err := service.ListPowerPlants(context.Background(), 1, 10, filter, sort)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.TotalCount)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("fail due to an empty capacity range", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		filter := repository.PowerPlantFilter{MinCapacityMw: floatPointer(100), MaxCapacityMw: floatPointer(50)}
+		_, err := service.ListPowerPlants(context.Background(), 1, 10, filter, repository.PowerPlantSort{})
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockDB.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fail due to sorting by distance without a point", func(t *testing.T) {
+		service, _, _ := setupTests(t)
+
+		_, err := service.ListPowerPlants(context.Background(), 1, 10, repository.PowerPlantFilter{}, repository.PowerPlantSort{Field: repository.SortByDistance})
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
+
+func TestPagePowerPlants(t *testing.T) {
+	sort := repository.PowerPlantSort{Field: repository.SortByName}
+	cursors := []repository.Cursor{
+		{Field: repository.SortByName, Value: "Akita Wind Turbines", ID: "25"},
+		{Field: repository.SortByName, Value: "Fukuoka Renewable Energy Plant", ID: "8"},
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		page := repository.Page{Limit: 2}
+		mockDB.On("ListPage", mock.Anything, repository.PowerPlantFilter{}, sort, page).Return(&repository.PowerPlantPage{
+			PowerPlants: []model.PowerPlant{{ID: "25"}, {ID: "8"}},
+			Cursors:     cursors,
+			HasMore:     true,
+		}, nil)
+
+		connection, err := service.PagePowerPlants(context.Background(), repository.PowerPlantFilter{}, sort, page)
+		assert.NoError(t, err)
+		assert.Len(t, connection.Edges, 2)
+		assert.Equal(t, "8", connection.Edges[1].Node.ID)
+		assert.Equal(t, cursors[1].String(), connection.Edges[1].Cursor)
+		assert.Equal(t, &model.PageInfo{
+			HasNextPage:     true,
+			HasPreviousPage: false,
+			StartCursor:     &connection.Edges[0].Cursor,
+			EndCursor:       &connection.Edges[1].Cursor,
+		}, connection.PageInfo)
+
+		mockDB.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+	})
+
+	t.Run("last page before a cursor", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		page := repository.Page{Limit: 2, Cursor: &cursors[1], Backward: true}
+		mockDB.On("ListPage", mock.Anything, repository.PowerPlantFilter{}, sort, page).Return(&repository.PowerPlantPage{
+			PowerPlants: []model.PowerPlant{{ID: "25"}},
+			Cursors:     cursors[:1],
+		}, nil)
+
+		connection, err := service.PagePowerPlants(context.Background(), repository.PowerPlantFilter{}, sort, page)
+		assert.NoError(t, err)
+		assert.Len(t, connection.Edges, 1)
+		assert.True(t, connection.PageInfo.HasNextPage, "Expected the power plant of the cursor on the next page")
+		assert.False(t, connection.PageInfo.HasPreviousPage)
+	})
+
+	t.Run("count the power plants of the filter", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		filter := repository.PowerPlantFilter{NameContains: "wind"}
+		page := repository.Page{Limit: 10}
+		mockDB.On("ListPage", mock.Anything, filter, repository.PowerPlantSort{}, page).Return(&repository.PowerPlantPage{}, nil)
+		mockDB.On("Count", mock.Anything, filter).Return(6, nil).Once()
+
+		connection, err := service.PagePowerPlants(context.Background(), filter, repository.PowerPlantSort{}, page)
+		assert.NoError(t, err)
+		assert.Empty(t, connection.Edges)
+		assert.Nil(t, connection.PageInfo.StartCursor)
+
+		total, err := connection.TotalCount(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 6, total)
+	})
+
+	t.Run("fail due to a cursor of another sort", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		page := repository.Page{Limit: 2, Cursor: &cursors[0]}
+		_, err := service.PagePowerPlants(context.Background(), repository.PowerPlantFilter{}, repository.PowerPlantSort{}, page)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockDB.AssertNotCalled(t, "ListPage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fail due to database error", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		page := repository.Page{Limit: 2}
+		mockDB.On("ListPage", mock.Anything, repository.PowerPlantFilter{}, sort, page).Return(nil, assert.AnError)
+
+		_, err := service.PagePowerPlants(context.Background(), repository.PowerPlantFilter{}, sort, page)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestPlantsNear(t *testing.T) {
+	opts := repository.NearOptions{Center: repository.Coordinates{Latitude: 35.68, Longitude: 139.69}, RadiusKm: 50, Limit: 10}
+
+	t.Run("successfully list power plants with their distances", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		mockDB.On("ListNear", mock.Anything, opts).Return([]repository.PowerPlantDistance{
+			{PowerPlant: model.PowerPlant{ID: "19"}, DistanceKm: 22.5},
+			{PowerPlant: model.PowerPlant{ID: "5"}, DistanceKm: 41.2},
+		}, nil)
+
+		plants, err := service.PlantsNear(context.Background(), opts)
+		assert.NoError(t, err)
+		assert.Len(t, plants, 2)
+		assert.Equal(t, "19", plants[0].PowerPlant.ID)
+		assert.Equal(t, 41.2, plants[1].DistanceKm)
+	})
+
+	t.Run("fail due to an invalid radius", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		invalid := opts
+		invalid.RadiusKm = -1
+		_, err := service.PlantsNear(context.Background(), invalid)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockDB.AssertNotCalled(t, "ListNear", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fail due to database error", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		mockDB.On("ListNear", mock.Anything, opts).Return(nil, assert.AnError)
+
+		_, err := service.PlantsNear(context.Background(), opts)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestPlantsWithin(t *testing.T) {
+	area := geojson.Geometry{Type: geojson.TypePolygon, Coordinates: json.RawMessage(`[[[139.5, 35.2], [140.2, 35.2], [139.8, 35.8], [139.5, 35.2]]]`)}
+	polygons := []geojson.Polygon{{{{139.5, 35.2}, {140.2, 35.2}, {139.8, 35.8}, {139.5, 35.2}}}}
+
+	t.Run("successfully list power plants inside a polygon", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		opts := repository.WithinOptions{Polygons: polygons, Limit: 100}
+		mockDB.On("ListWithin", mock.Anything, opts).Return([]model.PowerPlant{{ID: "5"}, {ID: "19"}}, nil)
+
+		plants, err := service.PlantsWithin(context.Background(), area, 100)
+		assert.NoError(t, err)
+		assert.Len(t, plants, 2)
+		assert.Equal(t, "19", plants[1].ID)
+	})
+
+	t.Run("fail due to a point geometry", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		_, err := service.PlantsWithin(context.Background(), geojson.Geometry{Type: "Point", Coordinates: json.RawMessage(`[139.5, 35.2]`)}, 100)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockDB.AssertNotCalled(t, "ListWithin", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fail due to a limit above the maximum", func(t *testing.T) {
+		service, mockDB, _ := setupTests(t)
+
+		_, err := service.PlantsWithin(context.Background(), area, repository.MaxWithinLimit+1)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockDB.AssertNotCalled(t, "ListWithin", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetElevation(t *testing.T) {
+	plant := &model.PowerPlant{ID: "1", Name: "Valid Plant", Latitude: 37.4513, Longitude: 141.0334}
+
+	t.Run("failed to fetch elevation data", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		mockOpenMeteo.On("GetElevation", mock.Anything, plant.Latitude, plant.Longitude).Return(0.0, fmt.Errorf("elevation error"))
+
+		_, err := service.GetElevation(context.Background(), plant)
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+
+		mockOpenMeteo.AssertExpectations(t)
+	})
+
+	t.Run("success from the api", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		mockOpenMeteo.On("GetElevation", mock.Anything, plant.Latitude, plant.Longitude).Return(100.0, nil)
+
+		elevation, err := service.GetElevation(context.Background(), plant)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, elevation)
+
+		mockOpenMeteo.AssertExpectations(t)
+	})
+
+	t.Run("success from the database", func(t *testing.T) {
+		service, _, _ := setupTests(t)
+		stored := &model.PowerPlant{ID: "1", Latitude: 37.4513, Longitude: 141.0334, Elevation: floatPointer(34)}
+
+		elevation, err := service.GetElevation(context.Background(), stored)
+		assert.NoError(t, err)
+		assert.Equal(t, 34.0, elevation)
+	})
+}
+
+func TestBackfillElevation(t *testing.T) {
+	t.Run("backfill in batches", func(t *testing.T) {
+		service, mockDB, mockOpenMeteo := setupTests(t)
+
+		firstBatch := []model.PowerPlant{{ID: "1", Latitude: 1, Longitude: 2}, {ID: "2", Latitude: 3, Longitude: 4}}
+		secondBatch := []model.PowerPlant{{ID: "3", Latitude: 5, Longitude: 6}}
+
+		mockDB.On("ListWithoutElevation", mock.Anything, 2).Return(firstBatch, nil).Once()
+		mockDB.On("ListWithoutElevation", mock.Anything, 2).Return(secondBatch, nil).Once()
+		mockOpenMeteo.On("GetElevations", mock.Anything, []repository.Coordinates{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}).Return([]float64{10, 30}, nil).Once()
+		mockOpenMeteo.On("GetElevations", mock.Anything, []repository.Coordinates{{Latitude: 5, Longitude: 6}}).Return([]float64{50}, nil).Once()
+		mockDB.On("UpdateElevation", mock.Anything, "1", 10.0).Return(nil).Once()
+		mockDB.On("UpdateElevation", mock.Anything, "2", 30.0).Return(nil).Once()
+		mockDB.On("UpdateElevation", mock.Anything, "3", 50.0).Return(nil).Once()
+
+		updated, err := service.BackfillElevation(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, updated)
 
 		mockDB.AssertExpectations(t)
 		mockOpenMeteo.AssertExpectations(t)
 	})
+
+	t.Run("failed to fetch elevation data", func(t *testing.T) {
+		service, mockDB, mockOpenMeteo := setupTests(t)
+
+		mockDB.On("ListWithoutElevation", mock.Anything, 100).Return([]model.PowerPlant{{ID: "1"}}, nil).Once()
+		mockOpenMeteo.On("GetElevations", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+
+		updated, err := service.BackfillElevation(context.Background(), 100)
+		assert.Error(t, err)
+		assert.Equal(t, 0, updated)
+
+		mockDB.AssertNotCalled(t, "UpdateElevation", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetWeatherForecasts(t *testing.T) {
+	plant := &model.PowerPlant{ID: "1", Name: "Valid Plant", Latitude: 37.4513, Longitude: 141.0334}
+	coordinates := []repository.Coordinates{{Latitude: plant.Latitude, Longitude: plant.Longitude}}
+	forecastOpts := repository.ForecastOptions{ForecastDays: 2, PastDays: 1}
+
+	t.Run("failed to fetch weather forecast data", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+
+		mockProvider.On("GetForecasts", mock.Anything, coordinates, forecastOpts).Return(nil, fmt.Errorf("forecast error"))
+
+		_, err := service.GetWeatherForecasts(context.Background(), plant, forecastOpts)
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("success with forecast options", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+
+		mockProvider.On("GetForecasts", mock.Anything, coordinates, forecastOpts).Return([]*weather.Forecast{tokyoForecast}, nil)
+
+		forecasts, err := service.GetWeatherForecasts(context.Background(), plant, forecastOpts)
+		assert.NoError(t, err)
+		assert.Len(t, forecasts, 3)
+		assert.Equal(t, time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC), forecasts[1].Time, "Expected forecast time in UTC")
+		assert.Equal(t, 11.0, forecasts[1].WindSpeed)
+		assert.Equal(t, "openmeteo", forecasts[1].Source)
+		assert.False(t, forecasts[1].Stale)
+		assert.Nil(t, forecasts[1].FetchedAt)
+		assert.Equal(t, &model.WeatherUnits{
+			Temperature:   model.TemperatureUnitCelsius,
+			WindSpeed:     model.WindSpeedUnitKmh,
+			Precipitation: model.PrecipitationUnitMillimeter,
+		}, forecasts[1].Units, "Expected the metric default units")
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("success with units", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+		unitsOpts := forecastOpts
+		unitsOpts.Units = repository.Units{WindSpeed: repository.Knots}
+
+		mockProvider.On("GetForecasts", mock.Anything, coordinates, unitsOpts).Return([]*weather.Forecast{tokyoForecast}, nil)
+
+		forecasts, err := service.GetWeatherForecasts(context.Background(), plant, unitsOpts)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.WeatherUnits{
+			Temperature:   model.TemperatureUnitCelsius,
+			WindSpeed:     model.WindSpeedUnitKn,
+			Precipitation: model.PrecipitationUnitMillimeter,
+		}, forecasts[0].Units)
+	})
+
+	t.Run("success with optional variables", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+		variablesOpts := forecastOpts
+		variablesOpts.Variables = repository.NewVariables(repository.CloudCoverLow, repository.SnowDepth)
+
+		withValues := *tokyoForecast
+		withValues.Hourly = []weather.HourlyForecast{tokyoForecast.Hourly[0]}
+		withValues.Hourly[0].Values = map[repository.Variable]float64{repository.CloudCoverLow: 80}
+		mockProvider.On("GetForecasts", mock.Anything, coordinates, variablesOpts).Return([]*weather.Forecast{&withValues}, nil)
+
+		forecasts, err := service.GetWeatherForecasts(context.Background(), plant, variablesOpts)
+		assert.NoError(t, err)
+		assert.Equal(t, 80.0, *forecasts[0].CloudCoverLow)
+		assert.Nil(t, forecasts[0].SnowDepth, "Expected null for a variable without data")
+		assert.Nil(t, forecasts[0].WindGusts10m)
+	})
+
+	t.Run("stale forecast with fetch time", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+
+		stale := *tokyoForecast
+		stale.Stale = true
+		stale.FetchedAt = time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+		mockProvider.On("GetForecasts", mock.Anything, coordinates, forecastOpts).Return([]*weather.Forecast{&stale}, nil)
+
+		forecasts, err := service.GetWeatherForecasts(context.Background(), plant, forecastOpts)
+		assert.NoError(t, err)
+		assert.True(t, forecasts[0].Stale)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *forecasts[0].FetchedAt)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("use the weather provider of the power plant", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+		mockMETNorway := mocks.NewWeatherProvider(t)
+		mockMETNorway.On("Name").Return(weather.METNorway)
+		providers, err := weather.NewProviders(weather.OpenMeteo, mockProvider, mockMETNorway)
+		assert.NoError(t, err)
+		service.(*powerPlantService).weatherProviders = providers
+
+		metNoPlant := *plant
+		metNoPlant.WeatherProvider = stringPointer(weather.METNorway)
+		mockMETNorway.On("GetForecasts", mock.Anything, coordinates, forecastOpts).Return([]*weather.Forecast{tokyoForecast}, nil).Once()
+
+		forecasts, err := service.GetWeatherForecasts(context.Background(), &metNoPlant, forecastOpts)
+		assert.NoError(t, err)
+		assert.Len(t, forecasts, 3)
+
+		mockMETNorway.AssertExpectations(t)
+		mockProvider.AssertNotCalled(t, "GetForecasts", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fail for an unknown weather provider", func(t *testing.T) {
+		service, _ := setupWeatherTests(t)
+
+		unknownPlant := *plant
+		unknownPlant.WeatherProvider = stringPointer("unknown")
+
+		_, err := service.GetWeatherForecasts(context.Background(), &unknownPlant, forecastOpts)
+		assert.ErrorIs(t, err, weather.ErrUnknownProvider)
+	})
+}
+
+func TestWeatherProviders(t *testing.T) {
+	service, mockProvider := setupWeatherTests(t)
+	mockProvider.On("Coverage").Return(weather.Coverage{ForecastHours: 384, PastDays: 92})
+
+	providers := service.WeatherProviders(context.Background())
+	assert.Equal(t, []*model.WeatherProviderInfo{{Name: weather.OpenMeteo, Default: true, ForecastHours: 384, PastDays: 92}}, providers)
+}
+
+func TestGetDailyWeather(t *testing.T) {
+	plant := &model.PowerPlant{ID: "1", Latitude: 35.68, Longitude: 139.69}
+	coordinates := []repository.Coordinates{{Latitude: 35.68, Longitude: 139.69}}
+
+	t.Run("failed to fetch daily weather data", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		mockOpenMeteo.On("GetDailyWeather", mock.Anything, coordinates, 3).Return(nil, assert.AnError)
+
+		_, err := service.GetDailyWeather(context.Background(), plant, 3)
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	})
+
+	t.Run("success with sunrise and sunset in UTC", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		maxTemperature, sunshine := 9.5, 28800.0
+		mockOpenMeteo.On("GetDailyWeather", mock.Anything, coordinates, 3).Return([]*repository.DailyWeatherResponse{{
+			Timezone: "Asia/Tokyo",
+			Daily: repository.Daily{
+				Time:             []string{"2024-01-01", "2024-01-02"},
+				Temperature2mMax: []*float64{&maxTemperature, nil},
+				Sunrise:          []string{"2024-01-01T06:51", ""},
+				Sunset:           []string{"2024-01-01T16:38", "2024-01-02T16:39"},
+				SunshineDuration: []*float64{&sunshine},
+			},
+		}}, nil)
+
+		days, err := service.GetDailyWeather(context.Background(), plant, 3)
+		assert.NoError(t, err)
+		assert.Len(t, days, 2)
+		assert.Equal(t, model.NewDate(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), days[0].Date)
+		assert.Equal(t, 9.5, *days[0].TemperatureMax)
+		assert.Equal(t, time.Date(2023, 12, 31, 21, 51, 0, 0, time.UTC), *days[0].Sunrise)
+		assert.Equal(t, time.Date(2024, 1, 1, 7, 38, 0, 0, time.UTC), *days[0].Sunset)
+		assert.Equal(t, 28800.0, *days[0].SunshineDuration)
+		assert.Nil(t, days[1].TemperatureMax)
+		assert.Nil(t, days[1].Sunrise)
+		assert.Nil(t, days[1].SunshineDuration)
+		assert.False(t, days[1].Stale)
+	})
+}
+
+func TestGetHistoricalWeather(t *testing.T) {
+	plant := &model.PowerPlant{ID: "1", Latitude: 35.68, Longitude: 139.69}
+	coordinates := []repository.Coordinates{{Latitude: 35.68, Longitude: 139.69}}
+	lastWeek := repository.HistoricalOptions{
+		From:       time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2023, 12, 26, 0, 0, 0, 0, time.UTC),
+		Resolution: repository.ResolutionHourly,
+	}
+
+	t.Run("fail due to a range ending within the delay of the reanalysis", func(t *testing.T) {
+		service, _, _ := setupTests(t)
+
+		opts := lastWeek
+		opts.To = time.Date(2023, 12, 28, 0, 0, 0, 0, time.UTC)
+		_, err := service.GetHistoricalWeather(context.Background(), plant, opts)
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("failed to fetch historical weather data", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		mockOpenMeteo.On("GetHistoricalWeather", mock.Anything, coordinates, lastWeek).Return(nil, assert.AnError)
+
+		_, err := service.GetHistoricalWeather(context.Background(), plant, lastWeek)
+		assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	})
+
+	t.Run("success with hourly weather in UTC", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		mockOpenMeteo.On("GetHistoricalWeather", mock.Anything, coordinates, lastWeek).Return([]*repository.HistoricalWeatherResponse{{
+			Timezone: "Asia/Tokyo",
+			Hourly: repository.HistoricalHourly{
+				Time:             []string{"2023-12-20T00:00"},
+				Temperature2m:    []*float64{floatPointer(2.5)},
+				Precipitation:    []*float64{floatPointer(0.1)},
+				WindSpeed10m:     []*float64{floatPointer(3.1)},
+				WindDirection10m: []*float64{floatPointer(270)},
+			},
+		}}, nil)
+
+		historical, err := service.GetHistoricalWeather(context.Background(), plant, lastWeek)
+		assert.NoError(t, err)
+		assert.Nil(t, historical.Daily)
+		assert.Len(t, historical.Hourly, 1)
+		assert.Equal(t, time.Date(2023, 12, 19, 15, 0, 0, 0, time.UTC), historical.Hourly[0].Time)
+		assert.Equal(t, 2.5, *historical.Hourly[0].Temperature)
+		assert.Equal(t, 270.0, *historical.Hourly[0].WindDirection)
+	})
+
+	t.Run("success with hours the reanalysis has no data for", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		mockOpenMeteo.On("GetHistoricalWeather", mock.Anything, coordinates, lastWeek).Return([]*repository.HistoricalWeatherResponse{{
+			Timezone: "Asia/Tokyo",
+			Hourly: repository.HistoricalHourly{
+				Time:             []string{"2023-12-26T22:00", "2023-12-26T23:00"},
+				Temperature2m:    []*float64{floatPointer(2.5), nil},
+				Precipitation:    []*float64{floatPointer(0), nil},
+				WindSpeed10m:     []*float64{floatPointer(3.1), nil},
+				WindDirection10m: []*float64{floatPointer(270), nil},
+			},
+		}}, nil)
+
+		historical, err := service.GetHistoricalWeather(context.Background(), plant, lastWeek)
+		assert.NoError(t, err)
+		assert.Len(t, historical.Hourly, 2)
+		assert.Equal(t, 0.0, *historical.Hourly[0].Precipitation)
+		assert.Nil(t, historical.Hourly[1].Temperature)
+		assert.Nil(t, historical.Hourly[1].Precipitation)
+		assert.Nil(t, historical.Hourly[1].WindSpeed)
+		assert.Nil(t, historical.Hourly[1].WindDirection)
+	})
+
+	t.Run("success with daily weather", func(t *testing.T) {
+		service, _, mockOpenMeteo := setupTests(t)
+
+		opts := lastWeek
+		opts.Resolution = repository.ResolutionDaily
+		maxTemperature := 9.5
+		mockOpenMeteo.On("GetHistoricalWeather", mock.Anything, coordinates, opts).Return([]*repository.HistoricalWeatherResponse{{
+			Timezone: "Asia/Tokyo",
+			Daily: repository.Daily{
+				Time:             []string{"2023-12-20"},
+				Temperature2mMax: []*float64{&maxTemperature},
+			},
+		}}, nil)
+
+		historical, err := service.GetHistoricalWeather(context.Background(), plant, opts)
+		assert.NoError(t, err)
+		assert.Nil(t, historical.Hourly)
+		assert.Len(t, historical.Daily, 1)
+		assert.Equal(t, model.NewDate(time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC)), historical.Daily[0].Date)
+		assert.Equal(t, 9.5, *historical.Daily[0].TemperatureMax)
+	})
+}
+
+func TestHasPrecipitation(t *testing.T) {
+	plant := &model.PowerPlant{ID: "1", Name: "Valid Plant", Latitude: 37.4513, Longitude: 141.0334}
+	coordinates := []repository.Coordinates{{Latitude: plant.Latitude, Longitude: plant.Longitude}}
+
+	t.Run("no precipitation on the local day of the power plant", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+
+		mockProvider.On("GetForecasts", mock.Anything, coordinates, repository.ForecastOptions{ForecastDays: 1}).
+			Return([]*weather.Forecast{tokyoForecast}, nil)
+
+		hasPrecipitation, err := service.HasPrecipitation(context.Background(), plant, "")
+		assert.NoError(t, err)
+		assert.False(t, hasPrecipitation)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("precipitation on the requested date", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+
+		mockProvider.On("GetForecasts", mock.Anything, coordinates, repository.ForecastOptions{ForecastDays: 2, PastDays: 1}).
+			Return([]*weather.Forecast{tokyoForecast}, nil)
+
+		hasPrecipitation, err := service.HasPrecipitation(context.Background(), plant, "2024-01-01")
+		assert.NoError(t, err)
+		assert.True(t, hasPrecipitation)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("requested date outside of the forecast range", func(t *testing.T) {
+		service, mockProvider := setupWeatherTests(t)
+
+		_, err := service.HasPrecipitation(context.Background(), plant, "2024-02-01")
+		assert.ErrorIs(t, err, ErrDateOutOfRange)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		mockProvider.AssertNotCalled(t, "GetForecasts", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHasPrecipitationOn(t *testing.T) {
+	t.Run("requested date outside of the forecast", func(t *testing.T) {
+		_, err := hasPrecipitationOn(tokyoForecast, "2024-01-05", testNow)
+		assert.ErrorIs(t, err, ErrDateOutOfRange)
+	})
+
+	t.Run("fall back to utc without a location", func(t *testing.T) {
+		forecast := &weather.Forecast{Hourly: tokyoForecast.Hourly}
+		hasPrecipitation, err := hasPrecipitationOn(forecast, "", testNow)
+		assert.NoError(t, err)
+		assert.True(t, hasPrecipitation)
+	})
+}
+
+// testNow is 2024-01-01 20:00 in Berlin, which is already 2024-01-02 04:00 at a power plant in Japan.
+var testNow = time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC)
+
+// tokyoForecast has forecasts for 2024-01-01 12:00, 2024-01-02 00:00 and 2024-01-02 12:00 in Japan.
+var tokyoForecast = &weather.Forecast{
+	Location: time.FixedZone("JST", 9*60*60),
+	Hourly: []weather.HourlyForecast{
+		{Time: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC), Precipitation: 1.5, WindSpeed: 10, Temperature: 5, WindDirection: 180, Source: "openmeteo"},
+		{Time: time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC), Precipitation: 0, WindSpeed: 11, Temperature: 6, WindDirection: 190, Source: "openmeteo"},
+		{Time: time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC), Precipitation: 0, WindSpeed: 12, Temperature: 7, WindDirection: 200, Source: "openmeteo"},
+	},
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func floatPointer(f float64) *float64 {
+	return &f
 }
 
 func setupTests(t *testing.T) (PowerPlantService, *mocks.PowerPlantRepository, *mocks.OpenMeteoRepository) {
 	mockDB := mocks.NewPowerPlantRepository(t)
 	mockOpenMeteo := mocks.NewOpenMeteoRepository(t)
 
-	service := NewPowerPlantService(mockDB, mockOpenMeteo)
+	service := NewPowerPlantService(mockDB, mockOpenMeteo, nil)
+	service.(*powerPlantService).now = func() time.Time { return testNow }
 
 	return service, mockDB, mockOpenMeteo
 }
+
+// setupWeatherTests creates a service with a mocked default weather provider.
+func setupWeatherTests(t *testing.T) (PowerPlantService, *mocks.WeatherProvider) {
+	mockProvider := mocks.NewWeatherProvider(t)
+	mockProvider.On("Name").Return(weather.OpenMeteo)
+	providers, err := weather.NewProviders(weather.OpenMeteo, mockProvider)
+	assert.NoError(t, err)
+
+	service := NewPowerPlantService(mocks.NewPowerPlantRepository(t), mocks.NewOpenMeteoRepository(t), providers)
+	service.(*powerPlantService).now = func() time.Time { return testNow }
+
+	return service, mockProvider
+}
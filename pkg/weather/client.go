@@ -0,0 +1,76 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// response is the body of a response with its caching headers.
+type response struct {
+	body []byte
+	// expires is the time until the response can be reused without a request, zero if it is not set.
+	expires time.Time
+	// lastModified is the Last-Modified header, it is sent as If-Modified-Since to revalidate the response.
+	lastModified string
+	// notModified is set if the server confirmed the cached response with 304 Not Modified, the body is empty then.
+	notModified bool
+}
+
+// get performs a GET request and returns the response body, source names the API in errors.
+func get(ctx context.Context, client *http.Client, requestURL, userAgent, source string) ([]byte, error) {
+	resp, err := getIfModified(ctx, client, requestURL, userAgent, source, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.body, nil
+}
+
+// getIfModified performs a GET request that is answered with 304 Not Modified if the response was not
+// modified since lastModified, an empty lastModified always returns the body.
+func getIfModified(ctx context.Context, client *http.Client, requestURL, userAgent, source, lastModified string) (response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return response{}, fmt.Errorf("error creating request: %w", err)
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return response{}, fmt.Errorf("error making request to %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	result := response{lastModified: resp.Header.Get("Last-Modified")}
+	if expires, err := http.ParseTime(resp.Header.Get("Expires")); err == nil {
+		result.expires = expires
+	}
+
+	if resp.StatusCode == http.StatusNotModified && lastModified != "" {
+		result.notModified = true
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("received non-OK response from %s: %d", source, resp.StatusCode)
+		if resp.StatusCode == http.StatusBadRequest {
+			return response{}, &repository.RequestError{Err: err}
+		}
+		return response{}, err
+	}
+
+	result.body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	return result, nil
+}
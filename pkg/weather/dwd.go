@@ -0,0 +1,380 @@
+package weather
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// DWDConfig configures the client of the DWD MOSMIX forecast files.
+type DWDConfig struct {
+	// BaseURL of the MOSMIX_L single station files, empty uses the default.
+	BaseURL string
+	// StationsURL of the MOSMIX station catalog, empty uses the default.
+	StationsURL string
+	// MaxStationDistance is the maximum distance in km of the nearest station to a location, 0 uses the default.
+	MaxStationDistance float64
+	// HTTPClient is used for the requests, a client with the Timeout is created if it is nil.
+	HTTPClient *http.Client
+	// Timeout of a single request, 0 uses the default.
+	Timeout time.Duration
+	// TimeZones resolves the timezones of the locations, they are approximated by the longitude if it is nil.
+	TimeZones *TimeZones
+}
+
+// DefaultDWDConfig is used for the options that are not set.
+var DefaultDWDConfig = DWDConfig{
+	BaseURL:            "https://opendata.dwd.de/weather/local_forecasts/mos/MOSMIX_L/single_stations",
+	StationsURL:        "https://www.dwd.de/DE/leistungen/met_verfahren_mosmix/mosmix_stationskatalog.cfg?view=nasPublication",
+	MaxStationDistance: 50,
+	Timeout:            10 * time.Second,
+}
+
+// Names of the MOSMIX elements used for the forecasts.
+const (
+	// mosmixTemperature is the temperature (2 m) in kelvin.
+	mosmixTemperature = "TTT"
+	// mosmixPrecipitation is the precipitation sum of the preceding hour in kg/m².
+	mosmixPrecipitation = "RR1c"
+	// mosmixWindSpeed is the wind speed (10 m) in m/s.
+	mosmixWindSpeed = "FF"
+	// mosmixWindDirection is the wind direction (10 m) in degrees.
+	mosmixWindDirection = "DD"
+)
+
+// mosmixForecastHours is how far the hourly forecasts of MOSMIX_L reach.
+const mosmixForecastHours = 240
+
+// dwdStation is a station of the MOSMIX station catalog.
+type dwdStation struct {
+	ID        string
+	Latitude  float64
+	Longitude float64
+}
+
+// mosmixKML is the KML document of a MOSMIX file, the values of an element are separated by spaces
+// in the order of the time steps.
+type mosmixKML struct {
+	TimeSteps []string `xml:"Document>ExtendedData>ProductDefinition>ForecastTimeSteps>TimeStep"`
+	Forecasts []struct {
+		Element string `xml:"elementName,attr"`
+		Values  string `xml:"value"`
+	} `xml:"Document>Placemark>ExtendedData>Forecast"`
+}
+
+type dwdProvider struct {
+	baseURL            string
+	stationsURL        string
+	maxStationDistance float64
+	client             *http.Client
+	timeZones          *TimeZones
+	now                func() time.Time
+
+	mu       sync.Mutex
+	stations []dwdStation
+}
+
+// NewDWDProvider creates a WeatherProvider for the DWD MOSMIX forecast files. The forecast of the nearest
+// MOSMIX station is used for a location, the station catalog is loaded on first use. MOSMIX has no past days
+// and the local days of the forecasts are the days in the timezone of the location.
+func NewDWDProvider(cfg DWDConfig) WeatherProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultDWDConfig.BaseURL
+	}
+	if cfg.StationsURL == "" {
+		cfg.StationsURL = DefaultDWDConfig.StationsURL
+	}
+	if cfg.MaxStationDistance <= 0 {
+		cfg.MaxStationDistance = DefaultDWDConfig.MaxStationDistance
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultDWDConfig.Timeout
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	if cfg.TimeZones == nil {
+		cfg.TimeZones = NewTimeZones(nil)
+	}
+
+	return &dwdProvider{
+		baseURL:            strings.TrimSuffix(cfg.BaseURL, "/"),
+		stationsURL:        cfg.StationsURL,
+		maxStationDistance: cfg.MaxStationDistance,
+		client:             cfg.HTTPClient,
+		timeZones:          cfg.TimeZones,
+		now:                time.Now,
+	}
+}
+
+// Name returns the name of the provider.
+func (p *dwdProvider) Name() string {
+	return DWD
+}
+
+// Coverage returns the range of the MOSMIX forecasts, MOSMIX has no past days.
+func (p *dwdProvider) Coverage() Coverage {
+	return Coverage{ForecastHours: mosmixForecastHours}
+}
+
+// GetForecasts retrieves the forecasts of multiple locations, the file of a station is only fetched once.
+func (p *dwdProvider) GetForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*Forecast, error) {
+	stations, err := p.loadStations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	locations := p.timeZones.Locations(ctx, coordinates)
+	hourlyByStation := map[string][]HourlyForecast{}
+	forecasts := make([]*Forecast, 0, len(coordinates))
+	for i, c := range coordinates {
+		station, err := p.nearestStation(stations, c)
+		if err != nil {
+			return nil, err
+		}
+
+		hourly, ok := hourlyByStation[station.ID]
+		if !ok {
+			if hourly, err = p.getStationForecast(ctx, station.ID); err != nil {
+				return nil, err
+			}
+			hourlyByStation[station.ID] = hourly
+		}
+
+		loc := locations[i]
+		from, to := forecastRange(loc, now, opts)
+		forecasts = append(forecasts, &Forecast{
+			Location:  loc,
+			Hourly:    convertUnits(inRange(hourly, from, to, DWD), opts.Units),
+			FetchedAt: now.UTC(),
+		})
+	}
+
+	return forecasts, nil
+}
+
+// loadStations returns the station catalog, it is fetched on first use and again if that failed.
+func (p *dwdProvider) loadStations(ctx context.Context) ([]dwdStation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stations != nil {
+		return p.stations, nil
+	}
+
+	slog.Debug("Fetching DWD MOSMIX station catalog", "url", p.stationsURL)
+	body, err := get(ctx, p.client, p.stationsURL, "", "DWD")
+	if err != nil {
+		return nil, err
+	}
+
+	stations, err := parseStations(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	p.stations = stations
+	return stations, nil
+}
+
+// nearestStation returns the station closest to the location, if it is not too far away.
+func (p *dwdProvider) nearestStation(stations []dwdStation, c repository.Coordinates) (dwdStation, error) {
+	var nearest dwdStation
+	minDistance := math.Inf(1)
+	for _, station := range stations {
+		if d := distance(c, repository.Coordinates{Latitude: station.Latitude, Longitude: station.Longitude}); d < minDistance {
+			nearest, minDistance = station, d
+		}
+	}
+
+	if minDistance > p.maxStationDistance {
+		return dwdStation{}, &repository.RequestError{Err: fmt.Errorf("no DWD MOSMIX station within %.0f km of %.4f,%.4f", p.maxStationDistance, c.Latitude, c.Longitude)}
+	}
+	return nearest, nil
+}
+
+// getStationForecast fetches and parses the latest MOSMIX_L file of the station.
+func (p *dwdProvider) getStationForecast(ctx context.Context, stationID string) ([]HourlyForecast, error) {
+	requestURL := fmt.Sprintf("%s/%s/kml/MOSMIX_L_LATEST_%s.kmz", p.baseURL, stationID, stationID)
+	slog.Debug("Fetching DWD MOSMIX forecast", "station", stationID)
+
+	body, err := get(ctx, p.client, requestURL, "", "DWD")
+	if err != nil {
+		return nil, err
+	}
+
+	kml, err := unzipKML(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMOSMIX(kml)
+}
+
+// unzipKML returns the KML document of a KMZ file.
+func unzipKML(kmz []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(kmz), int64(len(kmz)))
+	if err != nil {
+		return nil, fmt.Errorf("error opening MOSMIX file: %w", err)
+	}
+
+	for _, file := range archive.File {
+		if !strings.HasSuffix(file.Name, ".kml") {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error opening MOSMIX file: %w", err)
+		}
+		defer f.Close()
+
+		return io.ReadAll(f)
+	}
+
+	return nil, fmt.Errorf("no KML document in MOSMIX file")
+}
+
+// parseMOSMIX converts the MOSMIX KML document into hourly forecasts, hours with missing values are skipped.
+func parseMOSMIX(document []byte) ([]HourlyForecast, error) {
+	var kml mosmixKML
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	decoder.CharsetReader = latin1Reader
+	if err := decoder.Decode(&kml); err != nil {
+		return nil, fmt.Errorf("error unmarshaling MOSMIX file: %w", err)
+	}
+
+	values := map[string][]string{}
+	for _, forecast := range kml.Forecasts {
+		values[forecast.Element] = strings.Fields(forecast.Values)
+	}
+	for _, element := range []string{mosmixTemperature, mosmixPrecipitation, mosmixWindSpeed, mosmixWindDirection} {
+		if len(values[element]) != len(kml.TimeSteps) {
+			return nil, fmt.Errorf("MOSMIX file has %d values of %s for %d time steps", len(values[element]), element, len(kml.TimeSteps))
+		}
+	}
+
+	hourly := make([]HourlyForecast, 0, len(kml.TimeSteps))
+	for i, timeStep := range kml.TimeSteps {
+		t, err := time.Parse(time.RFC3339, timeStep)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse MOSMIX time step %q: %w", timeStep, err)
+		}
+
+		temperature, ok1 := parseMOSMIXValue(values[mosmixTemperature][i])
+		precipitation, ok2 := parseMOSMIXValue(values[mosmixPrecipitation][i])
+		windSpeed, ok3 := parseMOSMIXValue(values[mosmixWindSpeed][i])
+		windDirection, ok4 := parseMOSMIXValue(values[mosmixWindDirection][i])
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			continue
+		}
+
+		hourly = append(hourly, HourlyForecast{
+			Time:          t.UTC(),
+			Temperature:   temperature - 273.15,
+			Precipitation: precipitation,
+			WindSpeed:     windSpeed * metersPerSecondToKmh,
+			WindDirection: windDirection,
+		})
+	}
+
+	return hourly, nil
+}
+
+// latin1Reader converts the ISO-8859-1 encoded MOSMIX files to UTF-8.
+func latin1Reader(charset string, input io.Reader) (io.Reader, error) {
+	if !strings.EqualFold(charset, "ISO-8859-1") {
+		return nil, fmt.Errorf("unsupported charset %q of MOSMIX file", charset)
+	}
+
+	latin1, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := make([]rune, len(latin1))
+	for i, b := range latin1 {
+		runes[i] = rune(b)
+	}
+	return strings.NewReader(string(runes)), nil
+}
+
+// parseMOSMIXValue parses a MOSMIX value, missing values are given as "-".
+func parseMOSMIXValue(value string) (float64, bool) {
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
+
+// parseStations parses the MOSMIX station catalog, a table with the columns ID, ICAO, NAME, LAT, LON and ELEV
+// after a separator line. The coordinates are given in degrees and minutes, e.g. 52.31 is 52°31'.
+func parseStations(r io.Reader) ([]dwdStation, error) {
+	var stations []dwdStation
+	header := true
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if header {
+			header = !strings.HasPrefix(line, "-----")
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		latitude, err1 := strconv.ParseFloat(fields[len(fields)-3], 64)
+		longitude, err2 := strconv.ParseFloat(fields[len(fields)-2], 64)
+		if err1 != nil || err2 != nil {
+			slog.Debug("Skipping invalid line of the DWD MOSMIX station catalog", "line", line)
+			continue
+		}
+
+		stations = append(stations, dwdStation{
+			ID:        fields[0],
+			Latitude:  fromDegreesMinutes(latitude),
+			Longitude: fromDegreesMinutes(longitude),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading DWD MOSMIX station catalog: %w", err)
+	}
+	if len(stations) == 0 {
+		return nil, fmt.Errorf("no stations in DWD MOSMIX station catalog")
+	}
+
+	return stations, nil
+}
+
+// fromDegreesMinutes converts degrees and minutes given as d.mm into decimal degrees.
+func fromDegreesMinutes(value float64) float64 {
+	degrees := math.Trunc(value)
+	minutes := math.Round((value - degrees) * 100)
+	return degrees + minutes/60
+}
+
+// distance returns the great-circle distance between two locations in km.
+func distance(a, b repository.Coordinates) float64 {
+	const earthRadius = 6371.0
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
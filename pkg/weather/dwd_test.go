@@ -0,0 +1,81 @@
+package weather
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestDWDProvider(t *testing.T) {
+	mosmixTime := time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC)
+
+	t.Run("use the recorded forecast of the nearest station", func(t *testing.T) {
+		provider, downloads := setupDWDTests(t)
+		provider.(*dwdProvider).now = func() time.Time { return mosmixTime }
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Equal(t, mosmixTime, forecast.FetchedAt)
+		// the last time step has no temperature
+		assert.Len(t, forecast.Hourly, 3)
+		assert.Equal(t, time.Date(2024, 1, 1, 4, 0, 0, 0, time.UTC), forecast.Hourly[0].Time)
+		assert.InDelta(t, 6.1, forecast.Hourly[0].Temperature, 0.001, "temperature in celsius")
+		assert.InDelta(t, 14.832, forecast.Hourly[0].WindSpeed, 0.001, "wind speed in km/h")
+		assert.Equal(t, 228.0, forecast.Hourly[0].WindDirection)
+		assert.Equal(t, 0.5, forecast.Hourly[2].Precipitation)
+		assert.Equal(t, int32(1), downloads.Load())
+	})
+
+	t.Run("fetch the file of a station only once", func(t *testing.T) {
+		provider, downloads := setupDWDTests(t)
+		provider.(*dwdProvider).now = func() time.Time { return mosmixTime }
+
+		forecasts, err := provider.GetForecasts(context.Background(), []repository.Coordinates{berlin[0], {Latitude: 52.47, Longitude: 13.40}},
+			repository.ForecastOptions{ForecastDays: 1})
+		assert.NoError(t, err)
+		assert.Len(t, forecasts, 2)
+		assert.Equal(t, forecasts[0].Hourly, forecasts[1].Hourly)
+		assert.Equal(t, int32(1), downloads.Load())
+	})
+
+	t.Run("fail without a station nearby", func(t *testing.T) {
+		provider, downloads := setupDWDTests(t)
+
+		_, err := provider.GetForecasts(context.Background(), []repository.Coordinates{{Latitude: 35.68, Longitude: 139.69}},
+			repository.ForecastOptions{ForecastDays: 1})
+		assert.ErrorContains(t, err, "no DWD MOSMIX station")
+		assert.Equal(t, int32(0), downloads.Load())
+	})
+}
+
+func TestParseStations(t *testing.T) {
+	stations, err := parseStations(bytes.NewReader(readFixture(t, "dwd_stations.cfg")))
+	assert.NoError(t, err)
+	assert.Len(t, stations, 4)
+	assert.Equal(t, "10384", stations[2].ID)
+	assert.InDelta(t, 52.4667, stations[2].Latitude, 0.0001, "52°28'")
+	assert.InDelta(t, 13.4, stations[2].Longitude, 0.0001, "13°24'")
+	assert.InDelta(t, -8.6667, stations[0].Longitude, 0.0001, "-8°40'")
+}
+
+func setupDWDTests(t *testing.T) (WeatherProvider, *atomic.Int32) {
+	var downloads atomic.Int32
+	mosmix := kmz(t, "MOSMIX_L_2024010103_10384.kml", readFixture(t, "dwd_mosmix_10384.kml"))
+
+	mux := http.NewServeMux()
+	mux.Handle("/stations.cfg", serveFixture(t, "dwd_stations.cfg"))
+	mux.HandleFunc("/mosmix/10384/kml/MOSMIX_L_LATEST_10384.kmz", func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		_, _ = w.Write(mosmix)
+	})
+	url := setupFakeServer(t, mux)
+
+	provider := NewDWDProvider(DWDConfig{BaseURL: url + "/mosmix", StationsURL: url + "/stations.cfg"})
+	return provider, &downloads
+}
@@ -0,0 +1,226 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// EnsembleMember is a provider of an ensemble and the weight of its forecasts.
+type EnsembleMember struct {
+	Provider WeatherProvider
+	Weight   float64
+}
+
+type ensembleProvider struct {
+	members []EnsembleMember
+}
+
+// NewEnsembleProvider creates a WeatherProvider that blends the hourly forecasts of the members with a
+// weighted average. Members that fail are left out as long as one of them has a forecast.
+func NewEnsembleProvider(members ...EnsembleMember) WeatherProvider {
+	return &ensembleProvider{members: members}
+}
+
+// ParseEnsemble parses the members of an ensemble from a comma separated list of provider names with
+// an optional weight, e.g. "openmeteo:icon_seamless=2,metno". The weight defaults to 1.
+func ParseEnsemble(spec string, providers []WeatherProvider) ([]EnsembleMember, error) {
+	var members []EnsembleMember
+	for _, part := range strings.Split(spec, ",") {
+		name, weightStr, hasWeight := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+
+		weight := 1.0
+		if hasWeight {
+			var err error
+			if weight, err = strconv.ParseFloat(weightStr, 64); err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid weight %q of ensemble member %q", weightStr, name)
+			}
+		}
+
+		found, err := FindProviders(providers, name)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, EnsembleMember{Provider: found[0], Weight: weight})
+	}
+
+	return members, nil
+}
+
+// Name returns the name of the provider.
+func (p *ensembleProvider) Name() string {
+	return Ensemble
+}
+
+// Coverage returns the widest range of the members, the hours beyond the range of a member are blended from the others.
+func (p *ensembleProvider) Coverage() Coverage {
+	var coverage Coverage
+	for _, member := range p.members {
+		c := member.Provider.Coverage()
+		coverage.ForecastHours = max(coverage.ForecastHours, c.ForecastHours)
+		coverage.PastDays = max(coverage.PastDays, c.PastDays)
+	}
+	return coverage
+}
+
+// GetForecasts retrieves the forecasts of all members concurrently and blends them.
+func (p *ensembleProvider) GetForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*Forecast, error) {
+	results := make([][]*Forecast, len(p.members))
+	errs := make([]error, len(p.members))
+
+	var wg sync.WaitGroup
+	for i, member := range p.members {
+		wg.Add(1)
+		go func(i int, member EnsembleMember) {
+			defer wg.Done()
+			forecasts, err := member.Provider.GetForecasts(ctx, coordinates, opts)
+			if err == nil && len(forecasts) != len(coordinates) {
+				err = fmt.Errorf("got %d forecasts for %d locations", len(forecasts), len(coordinates))
+			}
+			if err != nil {
+				slog.Warn("Weather provider of the ensemble failed", "provider", member.Provider.Name(), "error", err)
+				errs[i] = fmt.Errorf("%s: %w", member.Provider.Name(), err)
+				return
+			}
+			results[i] = forecasts
+		}(i, member)
+	}
+	wg.Wait()
+
+	var members []EnsembleMember
+	var memberResults [][]*Forecast
+	for i, forecasts := range results {
+		if forecasts != nil {
+			members = append(members, p.members[i])
+			memberResults = append(memberResults, forecasts)
+		}
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("all weather providers of the ensemble failed: %w", errors.Join(errs...))
+	}
+
+	blended := make([]*Forecast, len(coordinates))
+	for i := range coordinates {
+		forecasts := make([]*Forecast, len(members))
+		for m := range members {
+			forecasts[m] = memberResults[m][i]
+		}
+		blended[i] = blend(members, forecasts)
+	}
+
+	return blended, nil
+}
+
+// hourBlend collects the weighted values of the members for a single hour.
+type hourBlend struct {
+	weight, temperature, precipitation, windSpeed float64
+	windDirection                                 directionBlend
+	sources                                       []string
+	// values are the optional variables, they are averaged over the members that have them
+	values map[repository.Variable]*valueBlend
+}
+
+// valueBlend collects the weighted values of an optional variable.
+type valueBlend struct {
+	weight, sum float64
+	direction   directionBlend
+}
+
+// directionBlend collects the weighted unit vectors of a direction, the direction has to be
+// averaged on the circle, e.g. 350° and 10° average to 0° and not 180°.
+type directionBlend struct {
+	x, y float64
+}
+
+func (d *directionBlend) add(weight, degrees float64) {
+	d.x += weight * math.Cos(degrees*math.Pi/180)
+	d.y += weight * math.Sin(degrees*math.Pi/180)
+}
+
+func (d *directionBlend) mean() float64 {
+	return math.Mod(math.Atan2(d.y, d.x)*180/math.Pi+360, 360)
+}
+
+// blend combines the forecasts of the members of a location, an hour is averaged over the members
+// that have a forecast for it.
+func blend(members []EnsembleMember, forecasts []*Forecast) *Forecast {
+	result := &Forecast{}
+	hours := map[time.Time]*hourBlend{}
+	for m, forecast := range forecasts {
+		if result.Location == nil {
+			result.Location = forecast.Location
+		}
+		if result.FetchedAt.IsZero() || (!forecast.FetchedAt.IsZero() && forecast.FetchedAt.Before(result.FetchedAt)) {
+			result.FetchedAt = forecast.FetchedAt
+		}
+		result.Stale = result.Stale || forecast.Stale
+
+		w := members[m].Weight
+		for _, h := range forecast.Hourly {
+			b, ok := hours[h.Time]
+			if !ok {
+				b = &hourBlend{values: map[repository.Variable]*valueBlend{}}
+				hours[h.Time] = b
+			}
+
+			b.weight += w
+			b.temperature += w * h.Temperature
+			b.precipitation += w * h.Precipitation
+			b.windSpeed += w * h.WindSpeed
+			b.windDirection.add(w, h.WindDirection)
+			b.sources = append(b.sources, h.Source)
+
+			for v, value := range h.Values {
+				vb, ok := b.values[v]
+				if !ok {
+					vb = &valueBlend{}
+					b.values[v] = vb
+				}
+				vb.weight += w
+				vb.sum += w * value
+				vb.direction.add(w, value)
+			}
+		}
+	}
+
+	result.Hourly = make([]HourlyForecast, 0, len(hours))
+	for t, b := range hours {
+		var values map[repository.Variable]float64
+		if len(b.values) > 0 {
+			values = make(map[repository.Variable]float64, len(b.values))
+		}
+		for v, vb := range b.values {
+			values[v] = vb.sum / vb.weight
+			if v.IsDirection() {
+				values[v] = vb.direction.mean()
+			}
+		}
+
+		result.Hourly = append(result.Hourly, HourlyForecast{
+			Time:          t,
+			Temperature:   b.temperature / b.weight,
+			Precipitation: b.precipitation / b.weight,
+			WindSpeed:     b.windSpeed / b.weight,
+			WindDirection: b.windDirection.mean(),
+			Source:        Ensemble + "(" + strings.Join(b.sources, ",") + ")",
+			Values:        values,
+		})
+	}
+	sort.Slice(result.Hourly, func(i, j int) bool {
+		return result.Hourly[i].Time.Before(result.Hourly[j].Time)
+	})
+
+	return result
+}
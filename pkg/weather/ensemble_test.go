@@ -0,0 +1,98 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestEnsembleProvider(t *testing.T) {
+	opts := repository.ForecastOptions{ForecastDays: 1}
+
+	t.Run("average the hourly values by weight", func(t *testing.T) {
+		a := &fakeProvider{name: "a", forecast: hourlyForecast("a", 10, 20)}
+		b := &fakeProvider{name: "b", forecast: hourlyForecast("b", 4, 8, 12)}
+		b.forecast.FetchedAt = fixtureTime.Add(-time.Hour)
+		b.forecast.Stale = true
+
+		forecast := getBerlinForecast(t, NewEnsembleProvider(EnsembleMember{a, 2}, EnsembleMember{b, 1}), opts)
+		assert.Len(t, forecast.Hourly, 3)
+		assert.InDelta(t, 8, forecast.Hourly[0].Temperature, 1e-9)
+		assert.InDelta(t, 16, forecast.Hourly[1].Temperature, 1e-9)
+		assert.Equal(t, "ensemble(a,b)", forecast.Hourly[0].Source)
+		// only b has a forecast of the last hour
+		assert.InDelta(t, 12, forecast.Hourly[2].Temperature, 1e-9)
+		assert.Equal(t, "ensemble(b)", forecast.Hourly[2].Source)
+		assert.True(t, forecast.Stale)
+		assert.Equal(t, fixtureTime.Add(-time.Hour), forecast.FetchedAt)
+	})
+
+	t.Run("average the wind direction on the circle", func(t *testing.T) {
+		a := &fakeProvider{name: "a", forecast: hourlyForecast("a", 0)}
+		a.forecast.Hourly[0].WindDirection = 350
+		b := &fakeProvider{name: "b", forecast: hourlyForecast("b", 0)}
+		b.forecast.Hourly[0].WindDirection = 30
+
+		forecast := getBerlinForecast(t, NewEnsembleProvider(EnsembleMember{a, 1}, EnsembleMember{b, 1}), opts)
+		assert.InDelta(t, 10, forecast.Hourly[0].WindDirection, 1e-9)
+	})
+
+	t.Run("average the optional variables over the providers that have them", func(t *testing.T) {
+		a := &fakeProvider{name: "a", forecast: hourlyForecast("a", 0)}
+		a.forecast.Hourly[0].Values = map[repository.Variable]float64{repository.WindGusts10m: 30, repository.WindDirection80m: 340}
+		b := &fakeProvider{name: "b", forecast: hourlyForecast("b", 0)}
+		b.forecast.Hourly[0].Values = map[repository.Variable]float64{repository.WindDirection80m: 20}
+
+		forecast := getBerlinForecast(t, NewEnsembleProvider(EnsembleMember{a, 1}, EnsembleMember{b, 1}), opts)
+		assert.Equal(t, 30.0, *forecast.Hourly[0].Value(repository.WindGusts10m))
+		assert.InDelta(t, 0, *forecast.Hourly[0].Value(repository.WindDirection80m), 1e-9)
+		assert.Nil(t, forecast.Hourly[0].Value(repository.SnowDepth))
+	})
+
+	t.Run("leave out the failed providers", func(t *testing.T) {
+		a := &fakeProvider{name: "a", forecast: hourlyForecast("a", 10)}
+		b := &fakeProvider{name: "b", err: errors.New("unavailable")}
+
+		forecast := getBerlinForecast(t, NewEnsembleProvider(EnsembleMember{a, 1}, EnsembleMember{b, 1}), opts)
+		assert.Equal(t, 10.0, forecast.Hourly[0].Temperature)
+		assert.Equal(t, "ensemble(a)", forecast.Hourly[0].Source)
+	})
+
+	t.Run("fail if all providers fail", func(t *testing.T) {
+		unavailable := errors.New("unavailable")
+		a := &fakeProvider{name: "a", err: unavailable}
+
+		_, err := NewEnsembleProvider(EnsembleMember{a, 1}).GetForecasts(context.Background(), berlin, opts)
+		assert.ErrorIs(t, err, unavailable)
+	})
+}
+
+func TestParseEnsemble(t *testing.T) {
+	a := &fakeProvider{name: "openmeteo:icon_seamless"}
+	b := &fakeProvider{name: "metno"}
+	providers := []WeatherProvider{a, b}
+
+	t.Run("parse the members with optional weights", func(t *testing.T) {
+		members, err := ParseEnsemble("openmeteo:icon_seamless=2, metno", providers)
+		assert.NoError(t, err)
+		assert.Equal(t, []EnsembleMember{{a, 2}, {b, 1}}, members)
+	})
+
+	t.Run("fail for an unknown provider", func(t *testing.T) {
+		_, err := ParseEnsemble("dwd", providers)
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+
+	t.Run("fail for an invalid weight", func(t *testing.T) {
+		_, err := ParseEnsemble("metno=heavy", providers)
+		assert.Error(t, err)
+
+		_, err = ParseEnsemble("metno=0", providers)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,69 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+type failoverProvider struct {
+	providers []WeatherProvider
+}
+
+// NewFailoverProvider creates a WeatherProvider that tries the given providers in order, e.g. a primary
+// Open-Meteo model and a secondary one. A stale forecast is only returned if none of the providers has
+// a fresh one.
+func NewFailoverProvider(providers ...WeatherProvider) WeatherProvider {
+	return &failoverProvider{providers: providers}
+}
+
+// Name returns the name of the provider.
+func (p *failoverProvider) Name() string {
+	return Failover
+}
+
+// Coverage returns the range of the first provider, the others are only used if it fails.
+func (p *failoverProvider) Coverage() Coverage {
+	return p.providers[0].Coverage()
+}
+
+// GetForecasts retrieves the forecasts from the first provider that has fresh forecasts of all locations.
+func (p *failoverProvider) GetForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*Forecast, error) {
+	var stale []*Forecast
+	var errs []error
+	for _, provider := range p.providers {
+		forecasts, err := provider.GetForecasts(ctx, coordinates, opts)
+		if err != nil {
+			slog.Warn("Weather provider failed, trying the next one", "provider", provider.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		if !anyStale(forecasts) {
+			return forecasts, nil
+		}
+		if stale == nil {
+			stale = forecasts
+		}
+	}
+
+	if stale != nil {
+		return stale, nil
+	}
+	return nil, fmt.Errorf("all weather providers failed: %w", errors.Join(errs...))
+}
+
+func anyStale(forecasts []*Forecast) bool {
+	for _, forecast := range forecasts {
+		if forecast.Stale {
+			return true
+		}
+	}
+	return false
+}
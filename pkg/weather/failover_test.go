@@ -0,0 +1,63 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestFailoverProvider(t *testing.T) {
+	opts := repository.ForecastOptions{ForecastDays: 1}
+
+	t.Run("use the primary provider", func(t *testing.T) {
+		primary := &fakeProvider{name: "primary", forecast: hourlyForecast("primary", 1)}
+		secondary := &fakeProvider{name: "secondary", forecast: hourlyForecast("secondary", 2)}
+
+		forecast := getBerlinForecast(t, NewFailoverProvider(primary, secondary), opts)
+		assert.Equal(t, "primary", forecast.Hourly[0].Source)
+		assert.Equal(t, 0, secondary.calls)
+	})
+
+	t.Run("fall back to the secondary provider", func(t *testing.T) {
+		primary := &fakeProvider{name: "primary", err: errors.New("unavailable")}
+		secondary := &fakeProvider{name: "secondary", forecast: hourlyForecast("secondary", 2)}
+
+		forecast := getBerlinForecast(t, NewFailoverProvider(primary, secondary), opts)
+		assert.Equal(t, "secondary", forecast.Hourly[0].Source)
+	})
+
+	t.Run("prefer a fresh forecast over a stale one", func(t *testing.T) {
+		stale := hourlyForecast("primary", 1)
+		stale.Stale = true
+		primary := &fakeProvider{name: "primary", forecast: stale}
+		secondary := &fakeProvider{name: "secondary", forecast: hourlyForecast("secondary", 2)}
+
+		forecast := getBerlinForecast(t, NewFailoverProvider(primary, secondary), opts)
+		assert.False(t, forecast.Stale)
+		assert.Equal(t, "secondary", forecast.Hourly[0].Source)
+	})
+
+	t.Run("return the stale forecast if the other providers fail", func(t *testing.T) {
+		stale := hourlyForecast("primary", 1)
+		stale.Stale = true
+		primary := &fakeProvider{name: "primary", forecast: stale}
+		secondary := &fakeProvider{name: "secondary", err: errors.New("unavailable")}
+
+		forecast := getBerlinForecast(t, NewFailoverProvider(primary, secondary), opts)
+		assert.Same(t, stale, forecast)
+	})
+
+	t.Run("fail if all providers fail", func(t *testing.T) {
+		unavailable := errors.New("unavailable")
+		primary := &fakeProvider{name: "primary", err: unavailable}
+		secondary := &fakeProvider{name: "secondary", err: errors.New("timeout")}
+
+		_, err := NewFailoverProvider(primary, secondary).GetForecasts(context.Background(), berlin, opts)
+		assert.ErrorIs(t, err, unavailable)
+		assert.ErrorContains(t, err, "secondary: timeout")
+	})
+}
@@ -0,0 +1,266 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// METNorwayConfig configures the client of the MET Norway locationforecast API.
+type METNorwayConfig struct {
+	// UserAgent identifies the app, MET Norway rejects requests without it. Empty uses the default.
+	UserAgent string
+	// BaseURL of the API, empty uses the default.
+	BaseURL string
+	// HTTPClient is used for the requests, a client with the Timeout is created if it is nil.
+	HTTPClient *http.Client
+	// Timeout of a single request, 0 uses the default.
+	Timeout time.Duration
+	// Concurrency is the maximum number of concurrent requests of a batch of locations, 0 uses the default.
+	Concurrency int
+	// TimeZones resolves the timezones of the locations, they are approximated by the longitude if it is nil.
+	TimeZones *TimeZones
+}
+
+// DefaultMETNorwayConfig is used for the options that are not set.
+var DefaultMETNorwayConfig = METNorwayConfig{
+	UserAgent:   "kaze github.com/glower/kaze",
+	BaseURL:     "https://api.met.no/weatherapi",
+	Timeout:     10 * time.Second,
+	Concurrency: 4,
+}
+
+// metNoResponse is the GeoJSON response of the compact locationforecast.
+type metNoResponse struct {
+	Properties struct {
+		Timeseries []metNoTimestep `json:"timeseries"`
+	} `json:"properties"`
+}
+
+type metNoTimestep struct {
+	Time time.Time `json:"time"`
+	Data struct {
+		Instant struct {
+			Details struct {
+				AirTemperature    *float64 `json:"air_temperature"`
+				WindSpeed         *float64 `json:"wind_speed"`
+				WindFromDirection *float64 `json:"wind_from_direction"`
+			} `json:"details"`
+		} `json:"instant"`
+		Next1Hours *struct {
+			Details struct {
+				PrecipitationAmount float64 `json:"precipitation_amount"`
+			} `json:"details"`
+		} `json:"next_1_hours"`
+	} `json:"data"`
+}
+
+// metNoCacheEntry is the forecast of a location, the terms of MET Norway require to reuse it until it expires.
+type metNoCacheEntry struct {
+	timeseries   []metNoTimestep
+	expires      time.Time
+	lastModified string
+	fetchedAt    time.Time
+}
+
+// metNoForecastHours is how far the hourly forecasts of MET Norway reach, the later time steps are 6-hourly.
+const metNoForecastHours = 60
+
+type metNoProvider struct {
+	userAgent   string
+	baseURL     string
+	client      *http.Client
+	concurrency int
+	timeZones   *TimeZones
+	now         func() time.Time
+
+	// cache has an entry per requested location, it is kept after it expires to revalidate it with If-Modified-Since
+	mu    sync.Mutex
+	cache map[string]*metNoCacheEntry
+}
+
+// NewMETNorwayProvider creates a WeatherProvider for the MET Norway locationforecast API.
+// The API has hourly forecasts for the next ~2.5 days only and no past days, longer ranges
+// return fewer hours. The local days of the forecasts are the days in the timezone of the location. The forecasts
+// are cached until they expire and then revalidated with If-Modified-Since, as the terms of MET Norway require.
+func NewMETNorwayProvider(cfg METNorwayConfig) WeatherProvider {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultMETNorwayConfig.UserAgent
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultMETNorwayConfig.BaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultMETNorwayConfig.Timeout
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultMETNorwayConfig.Concurrency
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	if cfg.TimeZones == nil {
+		cfg.TimeZones = NewTimeZones(nil)
+	}
+
+	return &metNoProvider{
+		userAgent:   cfg.UserAgent,
+		baseURL:     strings.TrimSuffix(cfg.BaseURL, "/"),
+		client:      cfg.HTTPClient,
+		concurrency: cfg.Concurrency,
+		timeZones:   cfg.TimeZones,
+		now:         time.Now,
+		cache:       map[string]*metNoCacheEntry{},
+	}
+}
+
+// Name returns the name of the provider.
+func (p *metNoProvider) Name() string {
+	return METNorway
+}
+
+// Coverage returns the range of the hourly forecasts, MET Norway has no past days.
+func (p *metNoProvider) Coverage() Coverage {
+	return Coverage{ForecastHours: metNoForecastHours}
+}
+
+// GetForecasts retrieves the forecasts of multiple locations, the API only accepts one location per request,
+// so the locations are requested concurrently.
+func (p *metNoProvider) GetForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*Forecast, error) {
+	locations := p.timeZones.Locations(ctx, coordinates)
+	forecasts := make([]*Forecast, len(coordinates))
+	errs := make([]error, len(coordinates))
+	sem := make(chan struct{}, p.concurrency)
+
+	var wg sync.WaitGroup
+	for i, c := range coordinates {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, c repository.Coordinates) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			forecasts[i], errs[i] = p.getForecast(ctx, c, locations[i], opts)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return forecasts, nil
+}
+
+func (p *metNoProvider) getForecast(ctx context.Context, c repository.Coordinates, loc *time.Location, opts repository.ForecastOptions) (*Forecast, error) {
+	entry, stale, err := p.getTimeseries(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := forecastRange(loc, p.now(), opts)
+
+	return &Forecast{
+		Location:  loc,
+		Hourly:    convertUnits(inRange(fromMETNorway(entry.timeseries), from, to, METNorway), opts.Units),
+		FetchedAt: entry.fetchedAt,
+		Stale:     stale,
+	}, nil
+}
+
+// getTimeseries returns the cached forecast of the location until it expires, then it is revalidated.
+// If the API fails, the expired forecast is returned as stale.
+func (p *metNoProvider) getTimeseries(ctx context.Context, c repository.Coordinates) (*metNoCacheEntry, bool, error) {
+	// MET Norway asks for at most 4 decimals, more only prevent caching on their side
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(c.Latitude, 'f', 4, 64))
+	query.Set("lon", strconv.FormatFloat(c.Longitude, 'f', 4, 64))
+	key := query.Encode()
+
+	p.mu.Lock()
+	cached := p.cache[key]
+	p.mu.Unlock()
+
+	now := p.now()
+	if cached != nil && now.Before(cached.expires) {
+		return cached, false, nil
+	}
+
+	var lastModified string
+	if cached != nil {
+		lastModified = cached.lastModified
+	}
+	slog.Debug("Fetching MET Norway weather forecast", "query", key, "revalidate", lastModified != "")
+
+	resp, err := getIfModified(ctx, p.client, p.baseURL+"/locationforecast/2.0/compact?"+key, p.userAgent, "MET Norway API", lastModified)
+	if err != nil {
+		var requestErr *repository.RequestError
+		if cached == nil || errors.As(err, &requestErr) || ctx.Err() != nil {
+			return nil, false, err
+		}
+		slog.Warn("Can't fetch MET Norway weather forecast, using the last known forecast", "error", err, "query", key)
+		return cached, true, nil
+	}
+
+	entry := &metNoCacheEntry{expires: resp.expires, lastModified: resp.lastModified, fetchedAt: now.UTC()}
+	if resp.notModified {
+		entry.timeseries = cached.timeseries
+		if entry.lastModified == "" {
+			entry.lastModified = cached.lastModified
+		}
+	} else {
+		var response metNoResponse
+		if err := json.Unmarshal(resp.body, &response); err != nil {
+			return nil, false, &repository.RequestError{Err: fmt.Errorf("error unmarshaling response body: %w", err)}
+		}
+		entry.timeseries = response.Properties.Timeseries
+	}
+
+	p.mu.Lock()
+	p.cache[key] = entry
+	p.mu.Unlock()
+
+	return entry, false, nil
+}
+
+// fromMETNorway converts the hourly timesteps, MET Norway returns the precipitation of the next hour,
+// so the precipitation of an hour is taken from the preceding timestep.
+func fromMETNorway(timeseries []metNoTimestep) []HourlyForecast {
+	var hourly []HourlyForecast
+	for i := 1; i < len(timeseries); i++ {
+		prev, step := timeseries[i-1], timeseries[i]
+		details := step.Data.Instant.Details
+		if prev.Data.Next1Hours == nil || !step.Time.Equal(prev.Time.Add(time.Hour)) ||
+			details.AirTemperature == nil || details.WindSpeed == nil || details.WindFromDirection == nil {
+			continue
+		}
+
+		hourly = append(hourly, HourlyForecast{
+			Time:          step.Time.UTC(),
+			Temperature:   *details.AirTemperature,
+			Precipitation: prev.Data.Next1Hours.Details.PrecipitationAmount,
+			WindSpeed:     *details.WindSpeed * metersPerSecondToKmh,
+			WindDirection: *details.WindFromDirection,
+		})
+	}
+
+	return hourly
+}
@@ -0,0 +1,164 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestMETNorwayProvider(t *testing.T) {
+	t.Run("convert the recorded forecast", func(t *testing.T) {
+		fixture := serveFixture(t, "metno_compact.json")
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/locationforecast/2.0/compact", r.URL.Path)
+			assert.Equal(t, "52.5200", r.URL.Query().Get("lat"))
+			assert.Equal(t, "13.4100", r.URL.Query().Get("lon"))
+			assert.Equal(t, DefaultMETNorwayConfig.UserAgent, r.UserAgent())
+			fixture(w, r)
+		}))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url})
+		provider.(*metNoProvider).now = func() time.Time { return fixtureTime }
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Equal(t, fixtureTime, forecast.FetchedAt)
+		// the 6-hourly time steps are not part of the hourly forecast
+		assert.Len(t, forecast.Hourly, 3)
+		assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), forecast.Hourly[0].Time)
+		assert.Equal(t, 6.2, forecast.Hourly[0].Temperature)
+		assert.Equal(t, 0.3, forecast.Hourly[0].Precipitation, "precipitation of the preceding hour")
+		assert.InDelta(t, 18.0, forecast.Hourly[0].WindSpeed, 0.001, "wind speed in km/h")
+		assert.Equal(t, 235.0, forecast.Hourly[0].WindDirection)
+		assert.Equal(t, 1.1, forecast.Hourly[2].Precipitation)
+	})
+
+	t.Run("convert the forecast into the requested units", func(t *testing.T) {
+		url := setupFakeServer(t, serveFixture(t, "metno_compact.json"))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url})
+		provider.(*metNoProvider).now = func() time.Time { return fixtureTime }
+
+		units := repository.Units{Temperature: repository.Fahrenheit, WindSpeed: repository.MetersPerSecond, Precipitation: repository.Inches}
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1, Units: units})
+		assert.InDelta(t, 43.16, forecast.Hourly[0].Temperature, 0.001)
+		assert.InDelta(t, 5.0, forecast.Hourly[0].WindSpeed, 0.001, "wind speed in m/s")
+		assert.InDelta(t, 0.3/25.4, forecast.Hourly[0].Precipitation, 0.0001)
+	})
+
+	t.Run("use the resolved timezone of the location", func(t *testing.T) {
+		openMeteoURL := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"timezone":"Europe/Berlin","timezone_abbreviation":"CET","utc_offset_seconds":3600,"daily":{"time":["2024-01-01"]}}`)
+		}))
+		timeZones := NewTimeZones(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: openMeteoURL}))
+		url := setupFakeServer(t, serveFixture(t, "metno_compact.json"))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url, TimeZones: timeZones})
+		provider.(*metNoProvider).now = func() time.Time { return fixtureTime }
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Equal(t, "Europe/Berlin", forecast.Location.String())
+	})
+
+	t.Run("limit the forecast to the requested days", func(t *testing.T) {
+		url := setupFakeServer(t, serveFixture(t, "metno_compact.json"))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url})
+		provider.(*metNoProvider).now = func() time.Time { return fixtureTime.AddDate(0, 0, 1) }
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Empty(t, forecast.Hourly)
+	})
+
+	t.Run("reuse the forecast until it expires and revalidate it", func(t *testing.T) {
+		fixture := serveFixture(t, "metno_compact.json")
+		lastModified := fixtureTime.Add(-time.Hour).Format(http.TimeFormat)
+		var requests, revalidations atomic.Int32
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Expires", fixtureTime.Add(30*time.Minute).Format(http.TimeFormat))
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				revalidations.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fixture(w, r)
+		}))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url})
+		now := fixtureTime
+		provider.(*metNoProvider).now = func() time.Time { return now }
+
+		getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Equal(t, int32(1), requests.Load(), "Expected the forecast to be reused until it expires")
+
+		now = fixtureTime.Add(time.Hour)
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Equal(t, int32(2), requests.Load())
+		assert.Equal(t, int32(1), revalidations.Load())
+		assert.Len(t, forecast.Hourly, 3, "Expected the cached forecast after 304 Not Modified")
+		assert.Equal(t, now, forecast.FetchedAt)
+	})
+
+	t.Run("return the expired forecast as stale if the api fails", func(t *testing.T) {
+		fixture := serveFixture(t, "metno_compact.json")
+		var fail atomic.Bool
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if fail.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Expires", fixtureTime.Add(30*time.Minute).Format(http.TimeFormat))
+			fixture(w, r)
+		}))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url})
+		now := fixtureTime
+		provider.(*metNoProvider).now = func() time.Time { return now }
+
+		getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		fail.Store(true)
+		now = fixtureTime.Add(time.Hour)
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.True(t, forecast.Stale)
+		assert.Equal(t, fixtureTime, forecast.FetchedAt)
+	})
+
+	t.Run("request the locations concurrently", func(t *testing.T) {
+		fixture := serveFixture(t, "metno_compact.json")
+		var running, maxRunning atomic.Int32
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			fixture(w, r)
+		}))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url, Concurrency: 2})
+		provider.(*metNoProvider).now = func() time.Time { return fixtureTime }
+
+		coordinates := []repository.Coordinates{{Latitude: 52.52, Longitude: 13.41}, {Latitude: 53.55, Longitude: 9.99}, {Latitude: 48.14, Longitude: 11.58}, {Latitude: 50.94, Longitude: 6.96}}
+		forecasts, err := provider.GetForecasts(context.Background(), coordinates, repository.ForecastOptions{ForecastDays: 1})
+		assert.NoError(t, err)
+		assert.Len(t, forecasts, 4)
+		assert.Equal(t, int32(2), maxRunning.Load())
+	})
+
+	t.Run("fail due to non-OK response", func(t *testing.T) {
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		provider := NewMETNorwayProvider(METNorwayConfig{BaseURL: url})
+
+		_, err := provider.GetForecasts(context.Background(), berlin, repository.ForecastOptions{ForecastDays: 1})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,123 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+type openMeteoProvider struct {
+	repo  repository.OpenMeteoRepository
+	model string
+}
+
+// NewOpenMeteoProvider creates a WeatherProvider for the Open-Meteo forecast API, the forecasts
+// are requested with the given repository, e.g. the cached repository.
+func NewOpenMeteoProvider(repo repository.OpenMeteoRepository) WeatherProvider {
+	return &openMeteoProvider{repo: repo}
+}
+
+// NewOpenMeteoModelProvider creates a WeatherProvider for a single weather model of Open-Meteo,
+// e.g. "icon_seamless". It is named after the model, e.g. "openmeteo:icon_seamless".
+func NewOpenMeteoModelProvider(repo repository.OpenMeteoRepository, model string) WeatherProvider {
+	return &openMeteoProvider{repo: repo, model: model}
+}
+
+// Name returns the name of the provider.
+func (p *openMeteoProvider) Name() string {
+	if p.model != "" {
+		return OpenMeteo + ":" + p.model
+	}
+	return OpenMeteo
+}
+
+// Coverage returns the range of the forecast API, the forecast of a weather model can end before, at the horizon of the model.
+func (p *openMeteoProvider) Coverage() Coverage {
+	return Coverage{ForecastHours: repository.MaxForecastDays * 24, PastDays: repository.MaxPastDays}
+}
+
+// GetForecasts retrieves the forecasts of multiple locations with a single request.
+func (p *openMeteoProvider) GetForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*Forecast, error) {
+	opts.Model = p.model
+	responses, err := p.repo.GetWeatherForecasts(ctx, coordinates, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts := make([]*Forecast, 0, len(responses))
+	for _, response := range responses {
+		forecast, err := FromOpenMeteo(response, p.Name())
+		if err != nil {
+			return nil, err
+		}
+		forecasts = append(forecasts, forecast)
+	}
+
+	return forecasts, nil
+}
+
+// FromOpenMeteo converts the hourly Open-Meteo data in the local time of the location into a forecast with UTC times.
+func FromOpenMeteo(response *repository.WeatherForecastResponse, source string) (*Forecast, error) {
+	hourly := response.Hourly
+	n := len(hourly.Time)
+	if len(hourly.Temperature2m) != n || len(hourly.Precipitation) != n || len(hourly.WindSpeed10m) != n || len(hourly.WindDirection10m) != n {
+		return nil, &repository.RequestError{Err: fmt.Errorf("incomplete hourly data for %d hours from Open-Meteo", n)}
+	}
+
+	// the optional variables that are in the response, the others were not requested
+	variables := map[repository.Variable][]*float64{}
+	for _, v := range repository.AllVariables.List() {
+		if values := hourly.Values(v); values != nil {
+			if len(values) != n {
+				return nil, &repository.RequestError{Err: fmt.Errorf("incomplete hourly %s data for %d hours from Open-Meteo", v, n)}
+			}
+			variables[v] = values
+		}
+	}
+
+	times, err := response.Times()
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &Forecast{
+		Location:  response.Location(),
+		Hourly:    make([]HourlyForecast, 0, n),
+		FetchedAt: response.FetchedAt,
+		Stale:     response.Stale,
+	}
+
+	for i, localTime := range times {
+		// hours past the horizon of a pinned weather model are null, they are left out instead of being taken as zeros
+		if hourly.Temperature2m[i] == nil || hourly.Precipitation[i] == nil || hourly.WindSpeed10m[i] == nil || hourly.WindDirection10m[i] == nil {
+			continue
+		}
+		forecast.Hourly = append(forecast.Hourly, HourlyForecast{
+			Time:          localTime.UTC(),
+			Temperature:   *hourly.Temperature2m[i],
+			Precipitation: *hourly.Precipitation[i],
+			WindSpeed:     *hourly.WindSpeed10m[i],
+			WindDirection: *hourly.WindDirection10m[i],
+			Source:        source,
+			Values:        hourValues(variables, i),
+		})
+	}
+
+	return forecast, nil
+}
+
+// hourValues returns the values of the optional variables of an hour, nil if there are none.
+func hourValues(variables map[repository.Variable][]*float64, hour int) map[repository.Variable]float64 {
+	var values map[repository.Variable]float64
+	for v, hourly := range variables {
+		if hourly[hour] == nil {
+			continue
+		}
+		if values == nil {
+			values = make(map[repository.Variable]float64, len(variables))
+		}
+		values[v] = *hourly[hour]
+	}
+	return values
+}
@@ -0,0 +1,81 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestOpenMeteoProvider(t *testing.T) {
+	t.Run("convert the recorded forecast to UTC", func(t *testing.T) {
+		url := setupFakeServer(t, serveFixture(t, "openmeteo_forecast.json"))
+		provider := NewOpenMeteoProvider(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: url}))
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Equal(t, "Europe/Berlin", forecast.Location.String())
+		assert.False(t, forecast.FetchedAt.IsZero())
+		assert.Len(t, forecast.Hourly, 4)
+		assert.Equal(t, HourlyForecast{
+			Time:          time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC),
+			Temperature:   6.1,
+			Precipitation: 0,
+			WindSpeed:     14.8,
+			WindDirection: 227,
+			Source:        OpenMeteo,
+		}, forecast.Hourly[0])
+		assert.Equal(t, 0.4, forecast.Hourly[2].Precipitation)
+	})
+
+	t.Run("request the forecast of a weather model", func(t *testing.T) {
+		var models string
+		fixture := serveFixture(t, "openmeteo_forecast.json")
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			models = r.URL.Query().Get("models")
+			fixture(w, r)
+		}))
+		provider := NewOpenMeteoModelProvider(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: url}), "icon_seamless")
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Equal(t, "openmeteo:icon_seamless", provider.Name())
+		assert.Equal(t, "icon_seamless", models)
+		assert.Equal(t, "openmeteo:icon_seamless", forecast.Hourly[0].Source)
+	})
+
+	t.Run("leave out the hours past the horizon of a weather model", func(t *testing.T) {
+		url := setupFakeServer(t, serveFixture(t, "openmeteo_forecast_model_horizon.json"))
+		provider := NewOpenMeteoModelProvider(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: url}), "icon_d2")
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1})
+		assert.Len(t, forecast.Hourly, 2, "Expected the null hours not to be reported as zeros")
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), forecast.Hourly[1].Time)
+		assert.Equal(t, 6.0, forecast.Hourly[1].Temperature)
+	})
+
+	t.Run("convert the optional variables", func(t *testing.T) {
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"timezone":"Europe/Berlin","hourly":{"time":["2024-01-01T00:00","2024-01-01T01:00"],` +
+				`"temperature_2m":[1,2],"precipitation":[0,0],"wind_speed_10m":[5,6],"wind_direction_10m":[90,95],"snow_depth":[0.12,null]}}`))
+		}))
+		provider := NewOpenMeteoProvider(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: url}))
+
+		forecast := getBerlinForecast(t, provider, repository.ForecastOptions{ForecastDays: 1, Variables: repository.NewVariables(repository.SnowDepth)})
+		assert.Equal(t, 0.12, *forecast.Hourly[0].Value(repository.SnowDepth))
+		assert.Nil(t, forecast.Hourly[1].Value(repository.SnowDepth))
+		assert.Nil(t, forecast.Hourly[0].Value(repository.Snowfall))
+	})
+
+	t.Run("fail due to incomplete hourly data", func(t *testing.T) {
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"timezone":"Europe/Berlin","hourly":{"time":["2024-01-01T00:00"],"precipitation":[0.5]}}`))
+		}))
+		provider := NewOpenMeteoProvider(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: url}))
+
+		_, err := provider.GetForecasts(context.Background(), berlin, repository.ForecastOptions{ForecastDays: 1})
+		assert.Error(t, err)
+	})
+}
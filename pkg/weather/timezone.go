@@ -0,0 +1,85 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// TimeZones resolves the timezones of locations for the providers that only return UTC times, so the
+// local calendar days of their forecasts are the days of the location.
+type TimeZones struct {
+	repo repository.OpenMeteoRepository
+
+	mu         sync.Mutex
+	byLocation map[repository.Coordinates]*time.Location
+}
+
+// NewTimeZones creates a resolver of the IANA timezones of locations. The timezone of a location is determined
+// by Open-Meteo with a daily weather request of the repository, e.g. the cached repository, and kept for the
+// lifetime of the process. A nil repository approximates the timezones by the longitude of the locations.
+func NewTimeZones(repo repository.OpenMeteoRepository) *TimeZones {
+	return &TimeZones{repo: repo, byLocation: map[repository.Coordinates]*time.Location{}}
+}
+
+// Locations returns the timezones of the locations in the order of the coordinates. If Open-Meteo is not
+// available, the timezones are approximated by the longitude and resolved again with the next call.
+func (z *TimeZones) Locations(ctx context.Context, coordinates []repository.Coordinates) []*time.Location {
+	locations := make([]*time.Location, len(coordinates))
+	var missing []repository.Coordinates
+	var missingIndexes []int
+
+	z.mu.Lock()
+	for i, c := range coordinates {
+		if loc, ok := z.byLocation[c]; ok {
+			locations[i] = loc
+			continue
+		}
+		missing = append(missing, c)
+		missingIndexes = append(missingIndexes, i)
+	}
+	z.mu.Unlock()
+
+	if len(missing) == 0 {
+		return locations
+	}
+
+	resolved := z.resolve(ctx, missing)
+	for j, i := range missingIndexes {
+		if resolved != nil {
+			locations[i] = resolved[j]
+		} else {
+			locations[i] = solarTimeZone(missing[j].Longitude)
+		}
+	}
+	return locations
+}
+
+// resolve requests the timezones of the locations from Open-Meteo and keeps them, it returns nil if that fails.
+func (z *TimeZones) resolve(ctx context.Context, coordinates []repository.Coordinates) []*time.Location {
+	if z.repo == nil {
+		return nil
+	}
+
+	responses, err := z.repo.GetDailyWeather(ctx, coordinates, 1)
+	if err == nil && len(responses) != len(coordinates) {
+		err = fmt.Errorf("got %d timezones for %d locations", len(responses), len(coordinates))
+	}
+	if err != nil {
+		slog.Warn("Can't resolve the timezones, approximating them by the longitude", "error", err, "locations", len(coordinates))
+		return nil
+	}
+
+	locations := make([]*time.Location, len(coordinates))
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	for i, response := range responses {
+		locations[i] = response.Location()
+		z.byLocation[coordinates[i]] = locations[i]
+	}
+	return locations
+}
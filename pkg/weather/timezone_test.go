@@ -0,0 +1,55 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestTimeZones(t *testing.T) {
+	// Madrid uses the central European time, its longitude is closer to UTC
+	madrid := []repository.Coordinates{{Latitude: 40.4168, Longitude: -3.7038}}
+
+	t.Run("resolve the timezone once", func(t *testing.T) {
+		var requests atomic.Int32
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			assert.Equal(t, "auto", r.URL.Query().Get("timezone"))
+			fmt.Fprint(w, `{"timezone":"Europe/Madrid","timezone_abbreviation":"CET","utc_offset_seconds":3600,"daily":{"time":["2024-01-01"]}}`)
+		}))
+		timeZones := NewTimeZones(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: url}))
+
+		for i := 0; i < 2; i++ {
+			locations := timeZones.Locations(context.Background(), madrid)
+			assert.Len(t, locations, 1)
+			assert.Equal(t, "Europe/Madrid", locations[0].String())
+		}
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("approximate the timezone if Open-Meteo is not available", func(t *testing.T) {
+		var requests atomic.Int32
+		url := setupFakeServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		timeZones := NewTimeZones(repository.NewOpenMeteoRepository(repository.OpenMeteoConfig{BaseURL: url}))
+
+		for i := 0; i < 2; i++ {
+			locations := timeZones.Locations(context.Background(), madrid)
+			assert.Equal(t, "UTC+0", locations[0].String())
+		}
+		assert.Equal(t, int32(2), requests.Load(), "resolved again with the next call")
+	})
+
+	t.Run("approximate the timezone without a repository", func(t *testing.T) {
+		locations := NewTimeZones(nil).Locations(context.Background(), madrid)
+		assert.Equal(t, "UTC+0", locations[0].String())
+	})
+}
@@ -0,0 +1,190 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+// Names of the weather providers.
+const (
+	// OpenMeteo is the Open-Meteo forecast API.
+	OpenMeteo = "openmeteo"
+	// METNorway is the locationforecast API of the Norwegian Meteorological Institute.
+	METNorway = "metno"
+	// DWD is the MOSMIX forecast of the Deutscher Wetterdienst.
+	DWD = "dwd"
+	// Failover tries the configured providers in order.
+	Failover = "failover"
+	// Ensemble blends the forecasts of the configured providers.
+	Ensemble = "ensemble"
+)
+
+// metersPerSecondToKmh converts wind speeds from m/s to km/h.
+const metersPerSecondToKmh = 3.6
+
+// ErrUnknownProvider is returned for a provider name that is not configured.
+var ErrUnknownProvider = errors.New("unknown weather provider")
+
+// Forecast is the hourly weather forecast of a location, independent of the provider.
+type Forecast struct {
+	// Location is the timezone of the location, the local calendar days of the forecast are based on it.
+	Location *time.Location
+	// Hourly are the forecasts of the hours in ascending order of time.
+	Hourly []HourlyForecast
+	// FetchedAt is the time the forecast was fetched from the provider.
+	FetchedAt time.Time
+	// Stale is set if the forecast is outdated, because the provider was not available to fetch a new one.
+	Stale bool
+}
+
+// HourlyForecast is the forecast of a single hour.
+type HourlyForecast struct {
+	// Time of the forecast in UTC.
+	Time time.Time
+	// Temperature (2 m) in the temperature unit of the forecast options, celsius by default.
+	Temperature float64
+	// Precipitation sum of the preceding hour in the precipitation unit of the forecast options, millimeter by default.
+	Precipitation float64
+	// WindSpeed (10 m) in the wind speed unit of the forecast options, km/h by default.
+	WindSpeed float64
+	// WindDirection (10 m) in degrees.
+	WindDirection float64
+	// Source is the provider or model that produced the forecast, e.g. "openmeteo:icon_seamless".
+	Source string
+	// Values of the optional variables, only the requested variables that the provider has for the hour are set.
+	Values map[repository.Variable]float64
+}
+
+// Value returns the value of the optional variable, nil if it is not set.
+func (h HourlyForecast) Value(v repository.Variable) *float64 {
+	value, ok := h.Values[v]
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// Coverage is the range of the hourly forecasts of a provider, forecast options beyond it return fewer hours.
+type Coverage struct {
+	// ForecastHours is how many hours after now the hourly forecasts reach at most.
+	ForecastHours int
+	// PastDays is the maximum number of past days, 0 if the provider has no forecasts of past days.
+	PastDays int
+}
+
+// WeatherProvider is a source of hourly weather forecasts.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=WeatherProvider --filename=weather_provider.go --output=../../mocks/
+type WeatherProvider interface {
+	// Name of the provider, e.g. "openmeteo".
+	Name() string
+	// GetForecasts retrieves the forecasts of multiple locations, in the order of the given coordinates.
+	GetForecasts(ctx context.Context, coordinates []repository.Coordinates, opts repository.ForecastOptions) ([]*Forecast, error)
+	// Coverage returns the range of the hourly forecasts of the provider.
+	Coverage() Coverage
+}
+
+// Providers are the weather providers of the deployment, one of them is used by default.
+type Providers struct {
+	byName      map[string]WeatherProvider
+	defaultName string
+}
+
+// NewProviders creates the providers of the deployment, defaultName must be one of the providers.
+func NewProviders(defaultName string, providers ...WeatherProvider) (*Providers, error) {
+	byName := make(map[string]WeatherProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	if _, ok := byName[defaultName]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, defaultName)
+	}
+
+	return &Providers{byName: byName, defaultName: defaultName}, nil
+}
+
+// Get returns the provider with the given name, or the default provider if name is empty.
+func (p *Providers) Get(name string) (WeatherProvider, error) {
+	if name == "" {
+		name = p.defaultName
+	}
+
+	provider, ok := p.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+// FindProviders returns the providers with the given names in the order of the names.
+func FindProviders(providers []WeatherProvider, names ...string) ([]WeatherProvider, error) {
+	found := make([]WeatherProvider, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(providers, func(p WeatherProvider) bool { return p.Name() == name })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+		}
+		found = append(found, providers[i])
+	}
+	return found, nil
+}
+
+// Default returns the name of the default provider.
+func (p *Providers) Default() string {
+	return p.defaultName
+}
+
+// Names returns the sorted names of the providers.
+func (p *Providers) Names() []string {
+	names := make([]string, 0, len(p.byName))
+	for name := range p.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// solarTimeZone approximates the timezone of a location by its longitude, if the timezone can't be
+// resolved with Open-Meteo. It can differ from the legal timezone by an hour or more.
+func solarTimeZone(longitude float64) *time.Location {
+	offset := int(math.Round(longitude/15)) * 60 * 60
+	return time.FixedZone(fmt.Sprintf("UTC%+d", offset/3600), offset)
+}
+
+// forecastRange returns the time range covered by the forecast options in the given timezone,
+// from the start of the first past day until the end of the last forecast day.
+func forecastRange(loc *time.Location, now time.Time, opts repository.ForecastOptions) (time.Time, time.Time) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return today.AddDate(0, 0, -opts.PastDays), today.AddDate(0, 0, opts.ForecastDays)
+}
+
+// inRange returns the hourly forecasts in the range [from, to), attributed to the source.
+func inRange(hourly []HourlyForecast, from, to time.Time, source string) []HourlyForecast {
+	var selected []HourlyForecast
+	for _, h := range hourly {
+		if !h.Time.Before(from) && h.Time.Before(to) {
+			h.Source = source
+			selected = append(selected, h)
+		}
+	}
+	return selected
+}
+
+// convertUnits converts metric hourly forecasts into the units, for providers that only return metric values.
+func convertUnits(hourly []HourlyForecast, units repository.Units) []HourlyForecast {
+	for i := range hourly {
+		hourly[i].Temperature = units.Temperature.FromCelsius(hourly[i].Temperature)
+		hourly[i].Precipitation = units.Precipitation.FromMillimeters(hourly[i].Precipitation)
+		hourly[i].WindSpeed = units.WindSpeed.FromKmh(hourly[i].WindSpeed)
+	}
+	return hourly
+}
@@ -0,0 +1,163 @@
+package weather
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/glower/kaze/pkg/repository"
+)
+
+func TestProviders(t *testing.T) {
+	openMeteo := NewOpenMeteoProvider(nil)
+	metNo := NewMETNorwayProvider(METNorwayConfig{})
+
+	t.Run("fail for an unknown default provider", func(t *testing.T) {
+		_, err := NewProviders("unknown", openMeteo, metNo)
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+
+	t.Run("get the provider by name or the default provider", func(t *testing.T) {
+		providers, err := NewProviders(METNorway, openMeteo, metNo)
+		assert.NoError(t, err)
+
+		provider, err := providers.Get("")
+		assert.NoError(t, err)
+		assert.Same(t, metNo, provider)
+
+		provider, err = providers.Get(OpenMeteo)
+		assert.NoError(t, err)
+		assert.Same(t, openMeteo, provider)
+
+		_, err = providers.Get(DWD)
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+		assert.Equal(t, []string{METNorway, OpenMeteo}, providers.Names())
+	})
+
+	t.Run("find providers by name in the given order", func(t *testing.T) {
+		found, err := FindProviders([]WeatherProvider{openMeteo, metNo}, METNorway, OpenMeteo)
+		assert.NoError(t, err)
+		assert.Equal(t, []WeatherProvider{metNo, openMeteo}, found)
+
+		_, err = FindProviders([]WeatherProvider{openMeteo, metNo}, DWD)
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+}
+
+func TestCoverage(t *testing.T) {
+	metNo := NewMETNorwayProvider(METNorwayConfig{})
+	dwd := NewDWDProvider(DWDConfig{})
+	openMeteo := NewOpenMeteoProvider(nil)
+
+	assert.Equal(t, Coverage{ForecastHours: 60}, metNo.Coverage())
+	assert.Equal(t, Coverage{ForecastHours: 240}, dwd.Coverage())
+	assert.Equal(t, Coverage{ForecastHours: 384, PastDays: 92}, openMeteo.Coverage())
+	// the failover provider is limited by its first provider, the ensemble by its widest member
+	assert.Equal(t, metNo.Coverage(), NewFailoverProvider(metNo, openMeteo).Coverage())
+	assert.Equal(t, Coverage{ForecastHours: 240}, NewEnsembleProvider(EnsembleMember{metNo, 1}, EnsembleMember{dwd, 1}).Coverage())
+}
+
+func TestForecastRange(t *testing.T) {
+	now := time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	from, to := forecastRange(solarTimeZone(13.41), now, repository.ForecastOptions{ForecastDays: 2, PastDays: 1})
+
+	// it is already 2024-01-02 00:30 in Berlin
+	assert.Equal(t, time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC), from.UTC())
+	assert.Equal(t, time.Date(2024, 1, 3, 23, 0, 0, 0, time.UTC), to.UTC())
+}
+
+// berlin is the location of the recorded fixtures.
+var berlin = []repository.Coordinates{{Latitude: 52.52, Longitude: 13.41}}
+
+// fixtureTime is the time the fixtures were recorded.
+var fixtureTime = time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)
+
+// serveFixture returns a handler responding with the content of the fixture.
+func serveFixture(t *testing.T, name string) http.HandlerFunc {
+	content := readFixture(t, name)
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	assert.NoError(t, err)
+	return content
+}
+
+// kmz zips the KML document like the MOSMIX files.
+func kmz(t *testing.T, name string, document []byte) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	f, err := archive.Create(name)
+	assert.NoError(t, err)
+	_, err = f.Write(document)
+	assert.NoError(t, err)
+	assert.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func setupFakeServer(t *testing.T, handler http.Handler) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// getBerlinForecast requests the forecast of the fixture location.
+func getBerlinForecast(t *testing.T, provider WeatherProvider, opts repository.ForecastOptions) *Forecast {
+	forecasts, err := provider.GetForecasts(context.Background(), berlin, opts)
+	assert.NoError(t, err)
+	assert.Len(t, forecasts, 1)
+	return forecasts[0]
+}
+
+// fakeProvider returns the same forecast for every location, or the error.
+type fakeProvider struct {
+	name     string
+	forecast *Forecast
+	err      error
+	calls    int
+	coverage Coverage
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Coverage() Coverage {
+	return p.coverage
+}
+
+func (p *fakeProvider) GetForecasts(_ context.Context, coordinates []repository.Coordinates, _ repository.ForecastOptions) ([]*Forecast, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	forecasts := make([]*Forecast, len(coordinates))
+	for i := range forecasts {
+		forecasts[i] = p.forecast
+	}
+	return forecasts, nil
+}
+
+// hourlyForecast returns a forecast of the hours starting at fixtureTime with the given temperatures.
+func hourlyForecast(source string, temperatures ...float64) *Forecast {
+	forecast := &Forecast{Location: time.UTC, FetchedAt: fixtureTime}
+	for i, temperature := range temperatures {
+		forecast.Hourly = append(forecast.Hourly, HourlyForecast{
+			Time:        fixtureTime.Add(time.Duration(i) * time.Hour),
+			Temperature: temperature,
+			Source:      source,
+		})
+	}
+	return forecast
+}